@@ -3,6 +3,9 @@ package v1beta2
 const (
 	CertificatesRefreshAnnotation       = "v1beta2.k8sd.io/refresh-certificates"
 	CertificatesRefreshStatusAnnotation = "v1beta2.k8sd.io/refresh-certificates-status"
+	// CertificatesRefreshMaxParallelAnnotation limits how many machines owned by a CK8sControlPlane or
+	// MachineDeployment refresh their certificates at the same time. Defaults to 1.
+	CertificatesRefreshMaxParallelAnnotation = "v1beta2.k8sd.io/refresh-certificates-max-parallel"
 )
 
 const (
@@ -15,4 +18,7 @@ const (
 	CertificatesRefreshInProgressEvent = "CertificatesRefreshInProgress"
 	CertificatesRefreshDoneEvent       = "CertificatesRefreshDone"
 	CertificatesRefreshFailedEvent     = "CertificatesRefreshFailed"
+	// CertificatesRenewalInProgressEvent is emitted when a machine is marked to renew its certificates
+	// because they are about to expire.
+	CertificatesRenewalInProgressEvent = "CertificatesRenewalInProgress"
 )
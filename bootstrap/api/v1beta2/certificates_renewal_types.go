@@ -0,0 +1,47 @@
+package v1beta2
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	utiltime "github.com/canonical/cluster-api-k8s/pkg/time"
+)
+
+// CertificatesRenewalPolicy configures the automatic renewal of the certificates of machines before they expire.
+type CertificatesRenewalPolicy struct {
+	// RenewBefore is how long before the recorded expiry of its certificates a machine gets them renewed.
+	RenewBefore metav1.Duration `json:"renewBefore"`
+
+	// TTL is the validity of the renewed certificates, in the format of the refresh-certificates
+	// annotation (e.g. "1y", "6mo", "90d" or "24h"). It must be longer than RenewBefore.
+	TTL string `json:"ttl"`
+
+	// MaxParallel is how many machines renew their certificates at the same time. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxParallel *int32 `json:"maxParallel,omitempty"`
+}
+
+// Validate checks that the renewal policy can renew certificates before they expire.
+func (p *CertificatesRenewalPolicy) Validate(path *field.Path) field.ErrorList {
+	if p == nil {
+		return nil
+	}
+
+	if p.RenewBefore.Duration <= 0 {
+		return field.ErrorList{field.Invalid(path.Child("renewBefore"), p.RenewBefore.Duration.String(), "must be positive")}
+	}
+
+	seconds, err := utiltime.TTLToSeconds(p.TTL)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path.Child("ttl"), p.TTL, err.Error())}
+	}
+	// NOTE: Renewed certificates that are already due for renewal would be renewed over and over.
+	if time.Duration(seconds)*time.Second <= p.RenewBefore.Duration {
+		return field.ErrorList{field.Invalid(path.Child("ttl"), p.TTL, fmt.Sprintf("must be longer than renewBefore %s", p.RenewBefore.Duration))}
+	}
+	return nil
+}
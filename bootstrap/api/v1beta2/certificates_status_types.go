@@ -0,0 +1,40 @@
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CertificatesStatus is the observed state of the certificates of a machine.
+type CertificatesStatus struct {
+	// Certificates are the certificates of the machine, e.g. the kubelet serving certificate
+	// or the kube-apiserver certificate.
+	// +optional
+	Certificates []CertificateStatus `json:"certificates,omitempty"`
+
+	// CertificateAuthorities are the certificate authorities known to the machine.
+	// +optional
+	CertificateAuthorities []CertificateStatus `json:"certificateAuthorities,omitempty"`
+
+	// LastSyncTime is when the certificates were last queried from the machine.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// CertificateStatus is the observed state of a single certificate of a machine.
+type CertificateStatus struct {
+	// Name identifies the certificate.
+	Name string `json:"name"`
+
+	// ExpiresAt is when the certificate expires.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// CertificateAuthority is the common name of the certificate authority that issued the certificate.
+	// It is empty for self-signed certificate authorities.
+	// +optional
+	CertificateAuthority string `json:"certificateAuthority,omitempty"`
+
+	// ExternallyManaged is true if the certificate is not managed by k8sd.
+	// +optional
+	ExternallyManaged bool `json:"externallyManaged,omitempty"`
+}
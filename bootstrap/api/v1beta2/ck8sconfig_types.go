@@ -325,6 +325,15 @@ type CK8sConfigStatus struct {
 	// Conditions defines current service state of the CK8sConfig.
 	// +optional
 	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+
+	// InPlaceUpgrade is the observed state of the in-place upgrade of the machine.
+	// It is informational only, it reports the in-place upgrade annotations of the Machine.
+	// +optional
+	InPlaceUpgrade *InPlaceUpgradeStatus `json:"inPlaceUpgrade,omitempty"`
+
+	// Certificates is the observed state of the certificates of the machine.
+	// +optional
+	Certificates *CertificatesStatus `json:"certificates,omitempty"`
 }
 
 // +kubebuilder:object:root=true
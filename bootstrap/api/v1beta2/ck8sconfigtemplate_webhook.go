@@ -18,8 +18,11 @@ package v1beta2
 
 import (
 	"context"
+	"fmt"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
@@ -40,13 +43,13 @@ var _ admission.CustomDefaulter = &CK8sConfigTemplate{}
 var _ admission.CustomValidator = &CK8sConfigTemplate{}
 
 // ValidateCreate will do any extra validation when creating a CK8sControlPlane.
-func (c *CK8sConfigTemplate) ValidateCreate(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
-	return []string{}, nil
+func (c *CK8sConfigTemplate) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return []string{}, validateCK8sConfigTemplate(obj)
 }
 
 // ValidateUpdate will do any extra validation when updating a CK8sControlPlane.
-func (c *CK8sConfigTemplate) ValidateUpdate(_ context.Context, _, _ runtime.Object) (admission.Warnings, error) {
-	return []string{}, nil
+func (c *CK8sConfigTemplate) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return []string{}, validateCK8sConfigTemplate(newObj)
 }
 
 // ValidateDelete allows you to add any extra validation when deleting.
@@ -58,3 +61,16 @@ func (c *CK8sConfigTemplate) ValidateDelete(_ context.Context, _ runtime.Object)
 func (c *CK8sConfigTemplate) Default(_ context.Context, _ runtime.Object) error {
 	return nil
 }
+
+func validateCK8sConfigTemplate(obj runtime.Object) error {
+	c, ok := obj.(*CK8sConfigTemplate)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a CK8sConfigTemplate but got a %T", obj))
+	}
+
+	allErrs := c.Spec.CertificatesRenewal.Validate(field.NewPath("spec", "certificatesRenewal"))
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("CK8sConfigTemplate").GroupKind(), c.Name, allErrs)
+}
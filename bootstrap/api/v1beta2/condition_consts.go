@@ -75,3 +75,59 @@ const (
 
 	SnapInstallValidationFailedReason = "SnapInstallValidationFailed"
 )
+
+const (
+	// CertificatesRefreshedCondition documents the progress of a certificates refresh orchestrated over
+	// the machines owned by a CK8sControlPlane or a MachineDeployment.
+	CertificatesRefreshedCondition clusterv1.ConditionType = "CertificatesRefreshed"
+
+	// CertificatesRefreshInProgressReason (Severity=Info) documents a certificates refresh that is still
+	// rolling through the owned machines.
+	CertificatesRefreshInProgressReason = "CertificatesRefreshInProgress"
+
+	// CertificatesRefreshFailedReason (Severity=Error) documents a certificates refresh that was halted
+	// because it failed on at least one of the owned machines.
+	CertificatesRefreshFailedReason = "CertificatesRefreshFailed"
+)
+
+const (
+	// CertificatesRenewalPolicyValidCondition documents whether the certificates renewal policy of a CK8sControlPlane,
+	// or of the CK8sConfigTemplate of a MachineDeployment, can renew the certificates of the machines before they expire.
+	CertificatesRenewalPolicyValidCondition clusterv1.ConditionType = "CertificatesRenewalPolicyValid"
+
+	// CertificatesRenewalPolicyInvalidReason (Severity=Error) documents a certificates renewal policy that is
+	// not applied until it is fixed.
+	CertificatesRenewalPolicyInvalidReason = "CertificatesRenewalPolicyInvalid"
+)
+
+const (
+	// InPlaceUpgradeHealthGatesPassedCondition documents whether the health gates configured for an orchestrated
+	// in-place upgrade of a CK8sControlPlane or a MachineDeployment pass.
+	InPlaceUpgradeHealthGatesPassedCondition clusterv1.ConditionType = "InPlaceUpgradeHealthGatesPassed"
+
+	// InPlaceUpgradeHealthGateFailedReason (Severity=Warning) documents an in-place upgrade held back
+	// because a health gate failed.
+	InPlaceUpgradeHealthGateFailedReason = "InPlaceUpgradeHealthGateFailed"
+)
+
+const (
+	// MaintenanceWindowOpenCondition documents whether the maintenance window of a CK8sControlPlane,
+	// MachineDeployment or Cluster is open, i.e. whether new machine operations may start.
+	MaintenanceWindowOpenCondition clusterv1.ConditionType = "MaintenanceWindowOpen"
+
+	// OutsideMaintenanceWindowReason (Severity=Info) documents machine operations deferred until
+	// the next maintenance window.
+	OutsideMaintenanceWindowReason = "OutsideMaintenanceWindow"
+)
+
+const (
+	// MachinesInPlaceUpgradedCondition documents the progress of an orchestrated in-place upgrade over
+	// the machines owned by a CK8sControlPlane or a MachineDeployment.
+	MachinesInPlaceUpgradedCondition clusterv1.ConditionType = "MachinesInPlaceUpgraded"
+	// InPlaceUpgradeInProgressReason (Severity=Info) documents an in-place upgrade that is still
+	// rolling through the owned machines.
+	InPlaceUpgradeInProgressReason = "InPlaceUpgradeInProgress"
+	// InPlaceUpgradePausedReason (Severity=Info) documents an in-place upgrade that is paused,
+	// with machines left to upgrade once it is resumed.
+	InPlaceUpgradePausedReason = "InPlaceUpgradePaused"
+)
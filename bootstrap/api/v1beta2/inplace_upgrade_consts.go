@@ -6,6 +6,44 @@ const (
 	InPlaceUpgradeReleaseAnnotation             = "v1beta2.k8sd.io/in-place-upgrade-release"
 	InPlaceUpgradeChangeIDAnnotation            = "v1beta2.k8sd.io/in-place-upgrade-change-id"
 	InPlaceUpgradeLastFailedAttemptAtAnnotation = "v1beta2.k8sd.io/in-place-upgrade-last-failed-attempt-at"
+	// InPlaceUpgradeMaxUnavailableAnnotation limits how many machines owned by a MachineDeployment
+	// upgrade at the same time. It is either an absolute number (e.g. "5") or a percentage of
+	// the machines (e.g. "10%"). Defaults to 1.
+	InPlaceUpgradeMaxUnavailableAnnotation = "v1beta2.k8sd.io/in-place-upgrade-max-unavailable"
+	// InPlaceUpgradeDrainAnnotation opts in to cordoning and draining the node before the in-place upgrade
+	// and uncordoning it once the node is Ready again. Set to "true" on a Machine, or on its
+	// MachineDeployment or CK8sControlPlane to apply to all of their machines.
+	InPlaceUpgradeDrainAnnotation = "v1beta2.k8sd.io/in-place-upgrade-drain"
+	// InPlaceUpgradeDrainTimeoutAnnotation is how long to wait for the node to drain before failing the upgrade
+	// (e.g. "15m"). It is read from the same object as InPlaceUpgradeDrainAnnotation.
+	InPlaceUpgradeDrainTimeoutAnnotation = "v1beta2.k8sd.io/in-place-upgrade-drain-timeout"
+	// InPlaceUpgradeNodeReadyTimeoutAnnotation is how long to wait for the node to become Ready after the refresh
+	// before rolling back the upgrade (e.g. "15m"). It is read from the Machine, or from its MachineDeployment
+	// or CK8sControlPlane.
+	InPlaceUpgradeNodeReadyTimeoutAnnotation = "v1beta2.k8sd.io/in-place-upgrade-node-ready-timeout"
+	// InPlaceUpgradeMaxAttemptsAnnotation is how many times an in-place upgrade is attempted before giving up
+	// (e.g. "5"). Set on a Machine, or on its MachineDeployment or CK8sControlPlane. Defaults to retrying forever.
+	InPlaceUpgradeMaxAttemptsAnnotation = "v1beta2.k8sd.io/in-place-upgrade-max-attempts"
+	// InPlaceUpgradeRetryBackoffAnnotation is how long to wait after the first failed attempt before retrying
+	// (e.g. "30s"). The wait doubles after every failed attempt, up to InPlaceUpgradeMaxRetryBackoffAnnotation.
+	// It is read from the same object as InPlaceUpgradeMaxAttemptsAnnotation.
+	InPlaceUpgradeRetryBackoffAnnotation = "v1beta2.k8sd.io/in-place-upgrade-retry-backoff"
+	// InPlaceUpgradeMaxRetryBackoffAnnotation caps the wait between attempts (e.g. "10m").
+	InPlaceUpgradeMaxRetryBackoffAnnotation = "v1beta2.k8sd.io/in-place-upgrade-max-retry-backoff"
+	// InPlaceUpgradeAcknowledgeFailureAnnotation is set by the user on a Machine whose in-place upgrade
+	// ran out of attempts, to acknowledge the failure and attempt the upgrade again.
+	InPlaceUpgradeAcknowledgeFailureAnnotation = "v1beta2.k8sd.io/in-place-upgrade-acknowledge-failure"
+	// InPlaceUpgradeHealthGatesAnnotation is a comma-separated list of health gates that must pass before
+	// the next machine of a CK8sControlPlane or MachineDeployment upgrades, and before the upgrade is done.
+	// Supported gates are "control-plane", "datastore" and "nodes".
+	InPlaceUpgradeHealthGatesAnnotation = "v1beta2.k8sd.io/in-place-upgrade-health-gates"
+	// InPlaceUpgradeHealthCheckEndpointAnnotation is a path on the workload cluster API server that must respond
+	// successfully for the health gates to pass, e.g. a service proxy path to a user-supplied health check.
+	InPlaceUpgradeHealthCheckEndpointAnnotation = "v1beta2.k8sd.io/in-place-upgrade-health-check-endpoint"
+	// InPlaceUpgradePausedAnnotation pauses in-place upgrades when set to "true" on a Cluster, CK8sControlPlane,
+	// MachineDeployment or Machine. No new machine upgrade starts while paused, the ones already upgrading
+	// complete. Removing the annotation resumes the upgrade where it stopped.
+	InPlaceUpgradePausedAnnotation = "v1beta2.k8sd.io/in-place-upgrade-paused"
 )
 
 const (
@@ -15,8 +53,23 @@ const (
 )
 
 const (
-	InPlaceUpgradeInProgressEvent = "InPlaceUpgradeInProgress"
-	InPlaceUpgradeDoneEvent       = "InPlaceUpgradeDone"
-	InPlaceUpgradeFailedEvent     = "InPlaceUpgradeFailed"
-	InPlaceUpgradeCancelledEvent  = "InPlaceUpgradeCancelled"
+	InPlaceUpgradeInProgressEvent  = "InPlaceUpgradeInProgress"
+	InPlaceUpgradeDoneEvent        = "InPlaceUpgradeDone"
+	InPlaceUpgradeFailedEvent      = "InPlaceUpgradeFailed"
+	InPlaceUpgradeCancelledEvent   = "InPlaceUpgradeCancelled"
+	InPlaceUpgradeDrainingEvent    = "InPlaceUpgradeDraining"
+	InPlaceUpgradeDrainedEvent     = "InPlaceUpgradeDrained"
+	InPlaceUpgradeUncordonedEvent  = "InPlaceUpgradeUncordoned"
+	InPlaceUpgradeRollingBackEvent = "InPlaceUpgradeRollingBack"
+	InPlaceUpgradeRolledBackEvent  = "InPlaceUpgradeRolledBack"
+	// InPlaceUpgradeRetriesExhaustedEvent is emitted when the upgrade will not be retried anymore.
+	InPlaceUpgradeRetriesExhaustedEvent = "InPlaceUpgradeRetriesExhausted"
+	// InPlaceUpgradeFailureAcknowledgedEvent is emitted when the user acknowledged the failure of the upgrade.
+	InPlaceUpgradeFailureAcknowledgedEvent = "InPlaceUpgradeFailureAcknowledged"
+	// InPlaceUpgradeHealthGateFailedEvent is emitted when a health gate holds back the upgrade.
+	InPlaceUpgradeHealthGateFailedEvent = "InPlaceUpgradeHealthGateFailed"
+	// InPlaceUpgradePausedEvent is emitted when an orchestrated in-place upgrade is paused.
+	InPlaceUpgradePausedEvent = "InPlaceUpgradePaused"
+	// InPlaceUpgradeResumedEvent is emitted when a paused orchestrated in-place upgrade is resumed.
+	InPlaceUpgradeResumedEvent = "InPlaceUpgradeResumed"
 )
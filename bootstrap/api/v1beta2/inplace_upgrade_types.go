@@ -0,0 +1,89 @@
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// InPlaceUpgradePhase is the phase of the in-place upgrade of a machine.
+type InPlaceUpgradePhase string
+
+const (
+	// InPlaceUpgradePhasePending means the machine is instructed to upgrade, but the upgrade has not started yet.
+	InPlaceUpgradePhasePending InPlaceUpgradePhase = "Pending"
+	// InPlaceUpgradePhasePaused means the machine is instructed to upgrade, but in-place upgrades are paused on it.
+	InPlaceUpgradePhasePaused InPlaceUpgradePhase = "Paused"
+	// InPlaceUpgradePhaseDraining means the node is cordoned and drained before the refresh.
+	InPlaceUpgradePhaseDraining InPlaceUpgradePhase = "Draining"
+	// InPlaceUpgradePhaseUpgrading means the snap refresh is in progress on the machine.
+	InPlaceUpgradePhaseUpgrading InPlaceUpgradePhase = "Upgrading"
+	// InPlaceUpgradePhaseWaitingForNode means the refresh completed and the node is expected to become Ready.
+	InPlaceUpgradePhaseWaitingForNode InPlaceUpgradePhase = "WaitingForNode"
+	// InPlaceUpgradePhaseRollingBack means the machine is refreshed back to its previous release.
+	InPlaceUpgradePhaseRollingBack InPlaceUpgradePhase = "RollingBack"
+	// InPlaceUpgradePhaseSucceeded means the machine runs the requested release.
+	InPlaceUpgradePhaseSucceeded InPlaceUpgradePhase = "Succeeded"
+	// InPlaceUpgradePhaseFailed means the last upgrade attempt failed. The upgrade is retried.
+	InPlaceUpgradePhaseFailed InPlaceUpgradePhase = "Failed"
+	// InPlaceUpgradePhaseRetriesExhausted means every allowed upgrade attempt failed.
+	// The upgrade is not retried until the user acknowledges the failure.
+	InPlaceUpgradePhaseRetriesExhausted InPlaceUpgradePhase = "RetriesExhausted"
+)
+
+// InPlaceUpgradeStatus is the state of the in-place upgrade of a machine.
+// The in-place upgrade controller drives the upgrade from it. The v1beta2.k8sd.io/in-place-upgrade-status,
+// v1beta2.k8sd.io/in-place-upgrade-release and v1beta2.k8sd.io/in-place-upgrade-last-failed-attempt-at
+// annotations of the Machine are kept up to date for the orchestrated upgrades.
+type InPlaceUpgradeStatus struct {
+	// Phase is the phase of the in-place upgrade.
+	// +optional
+	Phase InPlaceUpgradePhase `json:"phase,omitempty"`
+
+	// Release is the release the machine is upgrading to, or upgraded to once the upgrade succeeded.
+	// +optional
+	Release string `json:"release,omitempty"`
+
+	// PreviousRelease is the release the machine ran before the upgrade.
+	// +optional
+	PreviousRelease string `json:"previousRelease,omitempty"`
+
+	// ChangeID is the ID of the k8sd change refreshing the machine.
+	// +optional
+	ChangeID string `json:"changeID,omitempty"`
+
+	// Attempts is the number of times the upgrade to Release was attempted.
+	// +optional
+	Attempts int32 `json:"attempts,omitempty"`
+
+	// Message is a human readable message about the last failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// StartedAt is when the first attempt of the upgrade to Release started.
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// CompletedAt is when the upgrade to Release succeeded.
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	// DrainStartedAt is when the node started draining for the current attempt.
+	// It is cleared once the node is uncordoned.
+	// +optional
+	DrainStartedAt *metav1.Time `json:"drainStartedAt,omitempty"`
+
+	// RefreshedAt is when the refresh of the current attempt, or of its rollback, completed.
+	// +optional
+	RefreshedAt *metav1.Time `json:"refreshedAt,omitempty"`
+
+	// RollbackPhase is the outcome of the rollback of the last failed attempt, either Succeeded or Failed.
+	// +optional
+	RollbackPhase InPlaceUpgradePhase `json:"rollbackPhase,omitempty"`
+
+	// LastFailedAttemptAt is when the last attempt of the upgrade failed.
+	// +optional
+	LastFailedAttemptAt *metav1.Time `json:"lastFailedAttemptAt,omitempty"`
+
+	// LastTransitionTime is when the phase last changed.
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+}
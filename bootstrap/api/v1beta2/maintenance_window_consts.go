@@ -0,0 +1,13 @@
+package v1beta2
+
+const (
+	// MaintenanceWindowScheduleAnnotation is a cron expression (minute hour day-of-month month day-of-week)
+	// for the start of the maintenance windows, e.g. "0 22 * * FRI". Set on a CK8sControlPlane, MachineDeployment
+	// or Cluster to only start new machine upgrades and certificates refreshes within the windows.
+	// CK8sControlPlanes and MachineDeployments without a window use the window of their Cluster.
+	MaintenanceWindowScheduleAnnotation = "v1beta2.k8sd.io/maintenance-window-schedule"
+	// MaintenanceWindowDurationAnnotation is how long each maintenance window lasts, e.g. "48h".
+	MaintenanceWindowDurationAnnotation = "v1beta2.k8sd.io/maintenance-window-duration"
+	// MaintenanceWindowTimeZoneAnnotation is the IANA time zone of the schedule, e.g. "Europe/Berlin". Defaults to UTC.
+	MaintenanceWindowTimeZoneAnnotation = "v1beta2.k8sd.io/maintenance-window-timezone"
+)
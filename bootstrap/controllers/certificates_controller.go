@@ -8,6 +8,7 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -20,6 +21,8 @@ import (
 
 	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
 	"github.com/canonical/cluster-api-k8s/pkg/ck8s"
+	"github.com/canonical/cluster-api-k8s/pkg/maintenance"
+	"github.com/canonical/cluster-api-k8s/pkg/metrics"
 	utiltime "github.com/canonical/cluster-api-k8s/pkg/time"
 	"github.com/canonical/cluster-api-k8s/pkg/token"
 )
@@ -32,6 +35,10 @@ type CertificatesReconciler struct {
 	recorder          record.EventRecorder
 	K8sdDialTimeout   time.Duration
 	managementCluster ck8s.ManagementCluster
+
+	// ExpirySyncInterval is how often the certificates expiry of machines is queried again.
+	// If zero, it is only queried once, when the machine has no expiry recorded.
+	ExpirySyncInterval time.Duration
 }
 
 type CertificatesScope struct {
@@ -65,6 +72,8 @@ func (r *CertificatesReconciler) SetupWithManager(mgr ctrl.Manager) error {
 // +kubebuilder:rbac:groups=bootstrap.cluster.x-k8s.io,resources=ck8sconfigs/status,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status;machines;machines/status,verbs=get;list;watch
 // +kubebuilder:rbac:groups=exp.cluster.x-k8s.io,resources=machinepools;machinepools/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=ck8scontrolplanes,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=secrets;events;configmaps,verbs=get;list;watch;create;update;patch;delete
 
 func (r *CertificatesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -73,11 +82,15 @@ func (r *CertificatesReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	m := &clusterv1.Machine{}
 	if err := r.Get(ctx, req.NamespacedName, m); err != nil {
 		if apierrors.IsNotFound(err) {
+			metrics.ForgetMachine(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
 	}
 
+	// Report the expiry recorded on the machine, including any update made below.
+	defer metrics.RecordCertificatesExpiry(m)
+
 	if m.Status.NodeRef == nil {
 		// If the machine does not have a node ref, we requeue the request to retry.
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
@@ -115,23 +128,46 @@ func (r *CertificatesReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, nil
 	}
 
-	if !refreshCertificates && hasExpiryDateAnnotation {
+	config := &bootstrapv1.CK8sConfig{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: m.Namespace, Name: m.Spec.Bootstrap.ConfigRef.Name}, config); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get CK8sConfig: %w", err)
+	}
+
+	syncExpiry, syncAfter := r.expirySyncDue(hasExpiryDateAnnotation, config, time.Now())
+	if !refreshCertificates && !syncExpiry {
 		// No need to refresh certificates or update expiry date, return early.
-		return ctrl.Result{}, nil
+		return ctrl.Result{RequeueAfter: syncAfter}, nil
 	}
 
-	scope, err := r.createScope(ctx, m, log)
+	scope, err := r.createScope(ctx, m, config, log)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	if !hasExpiryDateAnnotation {
-		if err := r.updateExpiryDateAnnotation(ctx, scope); err != nil {
+	// NOTE: A refresh syncs the expiry of the refreshed certificates anyway.
+	if syncExpiry && !refreshCertificates {
+		if err := r.syncCertificatesExpiry(ctx, scope); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
 	if refreshCertificates {
+		window, err := r.getMaintenanceWindow(ctx, scope)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if now := time.Now(); window != nil && !window.IsOpen(now) {
+			log.Info("Outside of the maintenance window, deferring certificates refresh",
+				"nextWindow", window.NextOpen(now).Format(time.RFC3339),
+			)
+			if syncExpiry {
+				if err := r.syncCertificatesExpiry(ctx, scope); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+			return ctrl.Result{RequeueAfter: window.RequeueAfter(now)}, nil
+		}
+
 		if err := r.refreshCertificates(ctx, scope); err != nil {
 			// On error, we requeue the request to retry.
 			mAnnotations[bootstrapv1.CertificatesRefreshStatusAnnotation] = bootstrapv1.CertificatesRefreshFailedStatus
@@ -141,17 +177,37 @@ func (r *CertificatesReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			}
 			return ctrl.Result{}, err
 		}
+
+		if err := r.syncCertificatesExpiry(ctx, scope); err != nil {
+			return ctrl.Result{}, err
+		}
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: r.ExpirySyncInterval}, nil
 }
 
-func (r *CertificatesReconciler) createScope(ctx context.Context, m *clusterv1.Machine, log logr.Logger) (*CertificatesScope, error) {
-	config := &bootstrapv1.CK8sConfig{}
-	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: m.Namespace, Name: m.Spec.Bootstrap.ConfigRef.Name}, config); err != nil {
-		return nil, fmt.Errorf("failed to get CK8sConfig: %w", err)
+// expirySyncDue checks if the certificates expiry of the machine should be queried, otherwise returns
+// how long until it should be. Machines that have no expiry recorded are always due.
+func (r *CertificatesReconciler) expirySyncDue(hasExpiryDateAnnotation bool, config *bootstrapv1.CK8sConfig, now time.Time) (bool, time.Duration) {
+	if !hasExpiryDateAnnotation {
+		return true, 0
+	}
+	if r.ExpirySyncInterval <= 0 {
+		return false, 0
+	}
+
+	status := config.Status.Certificates
+	if status == nil || status.LastSyncTime == nil {
+		return true, 0
+	}
+
+	if next := status.LastSyncTime.Add(r.ExpirySyncInterval); next.After(now) {
+		return false, next.Sub(now)
 	}
+	return true, 0
+}
 
+func (r *CertificatesReconciler) createScope(ctx context.Context, m *clusterv1.Machine, config *bootstrapv1.CK8sConfig, log logr.Logger) (*CertificatesScope, error) {
 	configOwner, err := bsutil.GetConfigOwner(ctx, r.Client, config)
 	if err != nil || configOwner == nil {
 		return nil, fmt.Errorf("failed to get config owner: %w", err)
@@ -186,6 +242,25 @@ func (r *CertificatesReconciler) createScope(ctx context.Context, m *clusterv1.M
 	}, nil
 }
 
+// getMaintenanceWindow returns the maintenance window set on the MachineDeployment or control plane
+// of the machine, falling back to the one set on the Cluster.
+func (r *CertificatesReconciler) getMaintenanceWindow(ctx context.Context, scope *CertificatesScope) (*maintenance.Window, error) {
+	var objs []client.Object
+	owner, err := getMachineOwner(ctx, r.Client, scope.Machine)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owner of machine: %w", err)
+	}
+	if owner != nil {
+		objs = append(objs, owner)
+	}
+
+	window, err := maintenance.ResolveWindow(append(objs, scope.Cluster)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance window: %w", err)
+	}
+	return window, nil
+}
+
 func (r *CertificatesReconciler) refreshCertificates(ctx context.Context, scope *CertificatesScope) error {
 	nodeToken, err := token.LookupNodeToken(ctx, r.Client, util.ObjectKey(scope.Cluster), scope.Machine.Name)
 	if err != nil {
@@ -274,7 +349,9 @@ func (r *CertificatesReconciler) refreshCertificates(ctx context.Context, scope
 	return nil
 }
 
-func (r *CertificatesReconciler) updateExpiryDateAnnotation(ctx context.Context, scope *CertificatesScope) error {
+// syncCertificatesExpiry records the expiry date of the certificates on the machine annotation,
+// and the expiry of each certificate in the status of the CK8sConfig.
+func (r *CertificatesReconciler) syncCertificatesExpiry(ctx context.Context, scope *CertificatesScope) error {
 	nodeToken, err := token.LookupNodeToken(ctx, r.Client, util.ObjectKey(scope.Cluster), scope.Machine.Name)
 	if err != nil {
 		return fmt.Errorf("failed to lookup node token: %w", err)
@@ -292,5 +369,26 @@ func (r *CertificatesReconciler) updateExpiryDateAnnotation(ctx context.Context,
 
 	mAnnotations[bootstrapv1.MachineCertificatesExpiryDateAnnotation] = expiryDateString
 	scope.Machine.SetAnnotations(mAnnotations)
-	return scope.Patcher.Patch(ctx, scope.Machine)
+	if err := scope.Patcher.Patch(ctx, scope.Machine); err != nil {
+		return fmt.Errorf("failed to patch machine annotations: %w", err)
+	}
+
+	status, err := scope.Workload.GetCertificatesStatus(ctx, scope.Machine, *nodeToken)
+	if err != nil {
+		return fmt.Errorf("failed to get certificates status: %w", err)
+	}
+
+	configPatcher, err := patch.NewHelper(scope.Config, r.Client)
+	if err != nil {
+		return fmt.Errorf("failed to create patch helper: %w", err)
+	}
+
+	now := metav1.Now()
+	status.LastSyncTime = &now
+	scope.Config.Status.Certificates = status
+	if err := configPatcher.Patch(ctx, scope.Config); err != nil {
+		return fmt.Errorf("failed to patch CK8sConfig status: %w", err)
+	}
+
+	return nil
 }
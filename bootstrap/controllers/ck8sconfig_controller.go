@@ -691,7 +691,11 @@ func (r *CK8sConfigReconciler) handleClusterNotInitialized(ctx context.Context,
 	}
 
 	microclusterPort := scope.Config.Spec.ControlPlaneConfig.GetMicroclusterPort()
-	ds, err := ck8s.RenderK8sdProxyDaemonSetManifest(ck8s.K8sdProxyDaemonSetInput{K8sdPort: microclusterPort})
+	k8sdProxySpec, err := ck8s.GetK8sdProxySpec(ctx, r.Client, util.ObjectKey(scope.Cluster))
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get k8sd-proxy configuration: %w", err)
+	}
+	ds, err := ck8s.RenderK8sdProxyDaemonSetManifest(ck8s.NewK8sdProxyDaemonSetInput(microclusterPort, k8sdProxySpec))
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to render k8sd-proxy daemonset: %w", err)
 	}
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/certificates"
+	"github.com/canonical/cluster-api-k8s/pkg/ck8s"
+	"github.com/canonical/cluster-api-k8s/pkg/maintenance"
+	"github.com/canonical/cluster-api-k8s/pkg/trace"
+	"github.com/canonical/cluster-api-k8s/pkg/upgrade/inplace"
+)
+
+// MachineDeployCertificatesReconciler reconciles a MachineDeployment object and orchestrates
+// the certificates refresh of the machines it owns.
+type MachineDeployCertificatesReconciler struct {
+	scheme        *runtime.Scheme
+	recorder      record.EventRecorder
+	machineGetter inplace.MachineGetter
+	orchestrator  *certificates.Orchestrator
+
+	client.Client
+	Log logr.Logger
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MachineDeployCertificatesReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.scheme = mgr.GetScheme()
+	r.recorder = mgr.GetEventRecorderFor("ck8s-md-certificates-controller")
+	r.orchestrator = &certificates.Orchestrator{
+		Client:   r.Client,
+		Recorder: r.recorder,
+	}
+
+	if r.machineGetter == nil {
+		r.machineGetter = &ck8s.Management{
+			Client: r.Client,
+		}
+	}
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&clusterv1.MachineDeployment{}).
+		// NOTE: The machines are owned by the MachineSets of the MachineDeployment, not by the MachineDeployment itself.
+		Watches(&clusterv1.Machine{}, handler.EnqueueRequestsFromMapFunc(r.machineToMachineDeployment)).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to get new controller builder: %w", err)
+	}
+
+	return nil
+}
+
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinesets;machinesets/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments;machinedeployments/status,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=bootstrap.cluster.x-k8s.io,resources=ck8sconfigtemplates,verbs=get;list;watch
+
+// Reconcile handles the reconciliation of a MachineDeployment object.
+func (r *MachineDeployCertificatesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	traceID := trace.NewID()
+	log := r.Log.WithValues("machinedeployment_certificates", req.NamespacedName, "trace_id", traceID)
+	log.V(1).Info("Reconciliation started...")
+
+	machineDeployment := &clusterv1.MachineDeployment{}
+	if err := r.Get(ctx, req.NamespacedName, machineDeployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.V(1).Info("MachineDeployment resource not found. Ignoring since object must be deleted.")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to get MachineDeployment: %w", err)
+	}
+
+	template, err := getMachineDeploymentConfigTemplate(ctx, r.Client, machineDeployment)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get CK8sConfigTemplate: %w", err)
+	}
+
+	var renewal *bootstrapv1.CertificatesRenewalPolicy
+	if template != nil {
+		renewal = template.Spec.CertificatesRenewal
+	}
+
+	if certificates.GetRefreshTTL(machineDeployment) == "" && renewal == nil {
+		log.V(1).Info("MachineDeployment has no certificates refresh request nor renewal policy, skipping reconciliation")
+		return ctrl.Result{}, nil
+	}
+
+	if isDeleted(machineDeployment) {
+		log.V(1).Info("MachineDeployment is being deleted, skipping reconciliation")
+		return ctrl.Result{}, nil
+	}
+
+	scope, err := r.createScope(ctx, machineDeployment)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to create scope: %w", err)
+	}
+
+	// NOTE: A requested refresh takes precedence over the renewal policy, it refreshes every machine anyway.
+	if certificates.GetRefreshTTL(machineDeployment) == "" {
+		return r.orchestrator.ReconcileRenewal(ctx, log, scope, renewal)
+	}
+
+	return r.orchestrator.ReconcileRefresh(ctx, log, scope)
+}
+
+// createScope creates the certificates refresh scope of the MachineDeployment.
+func (r *MachineDeployCertificatesReconciler) createScope(ctx context.Context, md *clusterv1.MachineDeployment) (*certificates.Scope, error) {
+	patchHelper, err := patch.NewHelper(md, r.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new patch helper: %w", err)
+	}
+
+	cluster, err := getMachineDeploymentCluster(ctx, r.Client, md)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+
+	ownedMachines, err := getMachineDeploymentMachines(ctx, r.Client, r.machineGetter, md)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owned machines: %w", err)
+	}
+
+	window, err := maintenance.ResolveWindow(md, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance window: %w", err)
+	}
+
+	return &certificates.Scope{
+		Owner:    md,
+		Patcher:  patchHelper,
+		Machines: ownedMachines,
+		Window:   window,
+	}, nil
+}
+
+// machineToMachineDeployment maps a Machine to the MachineDeployment it belongs to.
+func (r *MachineDeployCertificatesReconciler) machineToMachineDeployment(_ context.Context, o client.Object) []ctrl.Request {
+	m, ok := o.(*clusterv1.Machine)
+	if !ok {
+		panic(fmt.Sprintf("Expected a Machine but got a %T", o))
+	}
+
+	mdName, ok := m.Labels[clusterv1.MachineDeploymentNameLabel]
+	if !ok || mdName == "" {
+		return nil
+	}
+
+	return []ctrl.Request{{NamespacedName: client.ObjectKey{Namespace: m.Namespace, Name: mdName}}}
+}
@@ -3,23 +3,25 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/util"
-	"sigs.k8s.io/cluster-api/util/collections"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
 	"github.com/canonical/cluster-api-k8s/pkg/ck8s"
+	"github.com/canonical/cluster-api-k8s/pkg/maintenance"
 	"github.com/canonical/cluster-api-k8s/pkg/trace"
 	"github.com/canonical/cluster-api-k8s/pkg/upgrade/inplace"
 )
@@ -32,14 +34,23 @@ type OrchestratedInPlaceUpgradeController struct {
 
 	client.Client
 	Log logr.Logger
+
+	K8sdDialTimeout time.Duration
+
+	managementCluster ck8s.ManagementCluster
 }
 
 // orchestratedInPlaceUpgradeScope is a struct that holds the context of the upgrade process.
 type orchestratedInPlaceUpgradeScope struct {
+	cluster           *clusterv1.Cluster
 	machineDeployment *clusterv1.MachineDeployment
 	mdPatcher         inplace.Patcher
 	upgradeTo         string
+	maxUnavailable    int
 	ownedMachines     []*clusterv1.Machine
+	healthChecks      *inplace.HealthChecks
+	window            *maintenance.Window
+	paused            bool
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -52,6 +63,12 @@ func (r *OrchestratedInPlaceUpgradeController) SetupWithManager(mgr ctrl.Manager
 			Client: r.Client,
 		}
 	}
+	if r.managementCluster == nil {
+		r.managementCluster = &ck8s.Management{
+			Client:          r.Client,
+			K8sdDialTimeout: r.K8sdDialTimeout,
+		}
+	}
 
 	// NOTE(Hue): Initially, I tried to go with comprehensive predicates but there was two problems with that:
 	// 1. It was not really understandable and mantainable.
@@ -73,7 +90,7 @@ func (r *OrchestratedInPlaceUpgradeController) SetupWithManager(mgr ctrl.Manager
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments;machinedeployments/status,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile handles the reconciliation of a MachineDeployment object.
-func (r *OrchestratedInPlaceUpgradeController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *OrchestratedInPlaceUpgradeController) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
 	traceID := trace.NewID()
 	log := r.Log.WithValues("orchestrated_inplace_upgrade", req.NamespacedName, "trace_id", traceID)
 	log.V(1).Info("Reconciliation started...")
@@ -102,8 +119,24 @@ func (r *OrchestratedInPlaceUpgradeController) Reconcile(ctx context.Context, re
 		return ctrl.Result{}, fmt.Errorf("failed to create scope: %w", err)
 	}
 
+	// Always report the progress of the upgrade, whatever the outcome of the reconciliation.
+	defer func() {
+		if err := r.updateProgress(ctx, scope); err != nil {
+			log.Error(err, "Failed to update in-place upgrade progress", "rerr", rerr)
+			if rerr == nil {
+				rerr = err
+			}
+		}
+	}()
+
 	// Starting the upgrade process
-	var upgradedMachines int
+	var (
+		upgradedMachines  int
+		upgradingMachines int
+		failedMachine     *clusterv1.Machine
+		machinesToUpgrade []*clusterv1.Machine
+		upgradingNodes    = map[string]struct{}{}
+	)
 	for _, m := range scope.ownedMachines {
 		if inplace.IsUpgraded(m, scope.upgradeTo) {
 			log.V(1).Info("Machine is already upgraded", "machine", m.Name)
@@ -117,20 +150,69 @@ func (r *OrchestratedInPlaceUpgradeController) Reconcile(ctx context.Context, re
 		}
 
 		if inplace.IsMachineUpgradeFailed(m) {
-			log.Info("Machine upgrade failed for machine, requeuing...", "machine", m.Name)
-			if err := r.markUpgradeFailed(ctx, scope, m); err != nil {
-				return ctrl.Result{}, fmt.Errorf("failed to mark upgrade as failed: %w", err)
+			if failedMachine == nil {
+				failedMachine = m
 			}
-
-			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+			continue
 		}
 
 		if inplace.IsMachineUpgrading(m) {
-			log.V(1).Info("Machine is upgrading, requeuing...", "machine", m.Name)
+			log.V(1).Info("Machine is upgrading", "machine", m.Name)
+			upgradingMachines++
+			if m.Status.NodeRef != nil {
+				upgradingNodes[m.Status.NodeRef.Name] = struct{}{}
+			}
+			continue
+		}
+
+		machinesToUpgrade = append(machinesToUpgrade, m)
+	}
+
+	// NOTE: A single failure halts the whole rollout, no more machines are marked for upgrade
+	// until the failed machine gets upgraded on retry.
+	if failedMachine != nil {
+		log.Info("Machine upgrade failed for machine, requeuing...", "machine", failedMachine.Name)
+		if err := r.markUpgradeFailed(ctx, scope, failedMachine); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to mark upgrade as failed: %w", err)
+		}
+
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	// NOTE: Pausing only holds back the start of machine upgrades, like the maintenance window.
+	if scope.paused && len(machinesToUpgrade) > 0 {
+		log.Info("In-place upgrade is paused, holding back the upgrade of the next machines", "machines", len(machinesToUpgrade))
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	// Mark as many machines for upgrade as the unavailability budget allows.
+	budget := scope.maxUnavailable - upgradingMachines
+	if budget > 0 && len(machinesToUpgrade) > 0 {
+		// NOTE: Only the start of machine upgrades waits for the maintenance window,
+		// the machines that are already upgrading are left to complete.
+		if open, err := r.checkMaintenanceWindow(ctx, scope); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to check maintenance window: %w", err)
+		} else if !open {
+			log.Info("Outside of the maintenance window, deferring the upgrade of the next machines")
+			return ctrl.Result{RequeueAfter: scope.window.RequeueAfter(time.Now())}, nil
+		}
+
+		// NOTE: This also acts as the post-upgrade gate of the previously upgraded machines,
+		// so a bad release stops after the first machines. The nodes of the machines still
+		// upgrading are expected to be unavailable and are not checked.
+		if passed, err := r.checkHealthGates(ctx, scope, upgradingNodes); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to check health gates: %w", err)
+		} else if !passed {
+			log.Info("Health gates failed, holding back the upgrade of the next machines")
 			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 		}
+	}
+	for _, m := range machinesToUpgrade {
+		if budget <= 0 {
+			log.V(1).Info("Max unavailable machines reached, requeuing...", "maxUnavailable", scope.maxUnavailable)
+			break
+		}
 
-		// Machine is not upgraded, mark it for upgrade
 		if err := r.markMachineToUpgrade(ctx, scope, m); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to mark machine to upgrade: %w", err)
 		}
@@ -141,10 +223,17 @@ func (r *OrchestratedInPlaceUpgradeController) Reconcile(ctx context.Context, re
 			return ctrl.Result{}, fmt.Errorf("failed to mark upgrade as in-progress: %w", err)
 		}
 
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		budget--
 	}
 
 	if upgradedMachines == len(scope.ownedMachines) {
+		if passed, err := r.checkHealthGates(ctx, scope, nil); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to check health gates: %w", err)
+		} else if !passed {
+			log.Info("Health gates failed after upgrading all machines, requeuing...")
+			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+
 		if err := r.markUpgradeDone(ctx, scope); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to mark upgrade as done: %w", err)
 		}
@@ -212,101 +301,128 @@ func (r *OrchestratedInPlaceUpgradeController) createScope(ctx context.Context,
 		return nil, fmt.Errorf("failed to create new patch helper: %w", err)
 	}
 
-	ownedMachines, err := r.getOwnedMachines(ctx, md)
+	cluster, err := getMachineDeploymentCluster(ctx, r.Client, md)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+
+	ownedMachines, err := getMachineDeploymentMachines(ctx, r.Client, r.machineGetter, md)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get owned machines: %w", err)
 	}
 
+	healthChecks, err := inplace.GetHealthChecks(md)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get health checks: %w", err)
+	}
+
+	window, err := maintenance.ResolveWindow(md, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance window: %w", err)
+	}
+
 	return &orchestratedInPlaceUpgradeScope{
+		cluster:           cluster,
 		machineDeployment: md,
 		upgradeTo:         inplace.GetUpgradeInstructions(md),
+		maxUnavailable:    inplace.GetMaxUnavailable(md, len(ownedMachines)),
 		ownedMachines:     ownedMachines,
 		mdPatcher:         patchHelper,
+		healthChecks:      healthChecks,
+		window:            window,
+		paused:            inplace.IsPaused(md, cluster),
 	}, nil
 }
 
-// getCluster gets the Cluster object for the MachineDeployment.
-func (r *OrchestratedInPlaceUpgradeController) getCluster(ctx context.Context, md *clusterv1.MachineDeployment) (*clusterv1.Cluster, error) {
-	cluster := &clusterv1.Cluster{}
-	clusterKey := client.ObjectKey{
-		Namespace: md.Namespace,
-		Name:      md.Spec.ClusterName,
+// updateProgress reports the progress of the upgrade in the MachinesInPlaceUpgraded condition of the
+// MachineDeployment, and emits an event when the upgrade is paused or resumed.
+func (r *OrchestratedInPlaceUpgradeController) updateProgress(ctx context.Context, scope *orchestratedInPlaceUpgradeScope) error {
+	wasPaused := conditions.GetReason(scope.machineDeployment, bootstrapv1.MachinesInPlaceUpgradedCondition) == bootstrapv1.InPlaceUpgradePausedReason
+
+	progress := inplace.GetProgress(scope.ownedMachines, scope.upgradeTo, scope.paused)
+	inplace.MarkProgress(scope.machineDeployment, progress)
+	if err := scope.mdPatcher.Patch(ctx, scope.machineDeployment); err != nil {
+		return fmt.Errorf("failed to patch MachineDeployment: %w", err)
 	}
-	if err := r.Get(ctx, clusterKey, cluster); err != nil {
-		return nil, fmt.Errorf("failed to get object: %w", err)
+
+	isPaused := conditions.GetReason(scope.machineDeployment, bootstrapv1.MachinesInPlaceUpgradedCondition) == bootstrapv1.InPlaceUpgradePausedReason
+	switch {
+	case isPaused && !wasPaused:
+		r.recorder.Eventf(scope.machineDeployment, corev1.EventTypeNormal, bootstrapv1.InPlaceUpgradePausedEvent, "In-place upgrade is paused. %s", progress)
+	case !isPaused && wasPaused:
+		r.recorder.Eventf(scope.machineDeployment, corev1.EventTypeNormal, bootstrapv1.InPlaceUpgradeResumedEvent, "In-place upgrade is resumed. %s", progress)
 	}
 
-	return cluster, nil
+	return nil
 }
 
-// getOwnedMachines gets the machines owned by the MachineDeployment.
-func (r *OrchestratedInPlaceUpgradeController) getOwnedMachines(ctx context.Context, md *clusterv1.MachineDeployment) ([]*clusterv1.Machine, error) {
-	cluster, err := r.getCluster(ctx, md)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get cluster: %w", err)
+// checkMaintenanceWindow reports the maintenance window in the MaintenanceWindowOpen condition of the
+// MachineDeployment. Returns false if no new machine upgrade may start.
+func (r *OrchestratedInPlaceUpgradeController) checkMaintenanceWindow(ctx context.Context, scope *orchestratedInPlaceUpgradeScope) (bool, error) {
+	open := maintenance.Check(scope.machineDeployment, scope.window, time.Now())
+	if err := scope.mdPatcher.Patch(ctx, scope.machineDeployment); err != nil {
+		return false, fmt.Errorf("failed to patch MachineDeployment: %w", err)
 	}
 
-	// NOTE(Hue): The machines are not owned by the MachineDeployment directly, but by the MachineSet.
-	var (
-		msList   clusterv1.MachineSetList
-		selector = map[string]string{
-			clusterv1.ClusterNameLabel:           cluster.Name,
-			clusterv1.MachineDeploymentNameLabel: md.Name,
-		}
-	)
-	if err := r.List(ctx, &msList, client.InNamespace(cluster.Namespace), client.MatchingLabels(selector)); err != nil {
-		return nil, fmt.Errorf("failed to get MachineSetList: %w", err)
+	return open, nil
+}
+
+// checkHealthGates runs the health gates configured on the MachineDeployment and reports the outcome in the
+// InPlaceUpgradeHealthGatesPassed condition. Returns false if the upgrade must be held back.
+func (r *OrchestratedInPlaceUpgradeController) checkHealthGates(ctx context.Context, scope *orchestratedInPlaceUpgradeScope, ignoreNodes map[string]struct{}) (bool, error) {
+	if scope.healthChecks == nil {
+		return true, nil
 	}
 
+	cluster := scope.cluster
+
+	// NOTE: The control plane is read as unstructured, its conditions and microcluster port are all we need.
 	var (
-		ms    clusterv1.MachineSet
-		found bool
+		controlPlane     conditions.Getter
+		microclusterPort = (&bootstrapv1.CK8sControlPlaneConfig{}).GetMicroclusterPort()
 	)
-	// NOTE(Hue): The nosec is due to a false positive: https://stackoverflow.com/questions/62446118/implicit-memory-aliasing-in-for-loop
-	for _, _ms := range msList.Items { // #nosec G601
-		if util.IsOwnedByObject(&_ms, md) {
-			ms = _ms
-			found = true
-			break
+	if cluster.Spec.ControlPlaneRef != nil {
+		u, err := external.Get(ctx, r.Client, cluster.Spec.ControlPlaneRef)
+		if err != nil {
+			return false, fmt.Errorf("failed to get control plane: %w", err)
 		}
-	}
+		controlPlane = conditions.UnstructuredGetter(u)
 
-	if !found {
-		return nil, fmt.Errorf("failed to find MachineSet owned by MachineDeployment %q", md.Name)
+		if port, found, err := unstructured.NestedInt64(u.Object, "spec", "spec", "controlPlane", "microclusterPort"); err == nil && found {
+			microclusterPort = int(port)
+		}
 	}
 
-	ownedMachinesCollection, err := r.machineGetter.GetMachinesForCluster(ctx, client.ObjectKeyFromObject(cluster), collections.OwnedMachines(&ms))
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(cluster), microclusterPort)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get cluster machines: %w", err)
+		return false, fmt.Errorf("failed to get workload cluster: %w", err)
 	}
 
-	ownedMachines := make([]*clusterv1.Machine, len(ownedMachinesCollection))
-	i := 0
-	for _, m := range ownedMachinesCollection {
-		ownedMachines[i] = m
-		i++
+	md := scope.machineDeployment
+	if err := scope.healthChecks.Check(ctx, controlPlane, workloadCluster, ignoreNodes); err != nil {
+		conditions.MarkFalse(md, bootstrapv1.InPlaceUpgradeHealthGatesPassedCondition, bootstrapv1.InPlaceUpgradeHealthGateFailedReason, clusterv1.ConditionSeverityWarning, "%s", err.Error())
+		if err := scope.mdPatcher.Patch(ctx, md); err != nil {
+			return false, fmt.Errorf("failed to patch MachineDeployment: %w", err)
+		}
+
+		r.recorder.Eventf(
+			md,
+			corev1.EventTypeWarning,
+			bootstrapv1.InPlaceUpgradeHealthGateFailedEvent,
+			"In-place upgrade held back by health gates: %s",
+			err.Error(),
+		)
+		return false, nil
 	}
 
-	// NOTE(Hue): Sorting machines by their UID to make sure we have a deterministic order.
-	// This is to (kind of) make sure we upgrade the machines in the same order every time.
-	// Meaning that if in the previous reconciliation we annotated a machine with upgrade-to,
-	// In the next reconciliation we will make sure that upgrade was successful before moving
-	// to the next machine.
-	// This is not the most robust way to do this, but it's good enough.
-	// A better way to do this might be to use some kind of lock (via a secret or something),
-	// similar to control plane init lock.
-	slices.SortStableFunc(ownedMachines, func(m1, m2 *clusterv1.Machine) int {
-		switch {
-		case m1.UID < m2.UID:
-			return -1
-		case m1.UID == m2.UID:
-			return 0
-		default:
-			return 1
+	if !conditions.IsTrue(md, bootstrapv1.InPlaceUpgradeHealthGatesPassedCondition) {
+		conditions.MarkTrue(md, bootstrapv1.InPlaceUpgradeHealthGatesPassedCondition)
+		if err := scope.mdPatcher.Patch(ctx, md); err != nil {
+			return false, fmt.Errorf("failed to patch MachineDeployment: %w", err)
 		}
-	})
+	}
 
-	return ownedMachines, nil
+	return true, nil
 }
 
 // markMachineToUpgrade marks the machine to upgrade.
@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"time"
 
+	apiv1 "github.com/canonical/k8s-snap-api/api/v1"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -20,7 +24,9 @@ import (
 
 	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
 	"github.com/canonical/cluster-api-k8s/pkg/ck8s"
+	"github.com/canonical/cluster-api-k8s/pkg/metrics"
 	"github.com/canonical/cluster-api-k8s/pkg/token"
+	"github.com/canonical/cluster-api-k8s/pkg/upgrade/inplace"
 )
 
 // InPlaceUpgradeReconciler reconciles machines and performs in-place upgrades based on annotations.
@@ -59,13 +65,24 @@ type UpgradeScope struct {
 	Machine         *clusterv1.Machine
 	PatchHelper     *patch.Helper
 	UpgradeOption   string
+	// Config is the CK8sConfig of the machine. Its status records the state of the in-place upgrade.
+	Config *bootstrapv1.CK8sConfig
+	// configBefore is the CK8sConfig as last persisted.
+	configBefore *bootstrapv1.CK8sConfig
+}
+
+// Status returns the status of the in-place upgrade of the machine.
+func (s *UpgradeScope) Status() *bootstrapv1.InPlaceUpgradeStatus {
+	return s.Config.Status.InPlaceUpgrade
 }
 
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=bootstrap.cluster.x-k8s.io,resources=ck8sconfigs,verbs=get;list;watch
-// +kubebuilder:rbac:groups=bootstrap.cluster.x-k8s.io,resources=ck8sconfigs/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=bootstrap.cluster.x-k8s.io,resources=ck8sconfigs/status,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups="",resources=secrets;events;configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=ck8scontrolplanes,verbs=get;list;watch
 
 func (r *InPlaceUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("namespace", req.Namespace, "machine", req.Name)
@@ -75,6 +92,7 @@ func (r *InPlaceUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		if apierrors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			metrics.ForgetMachine(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 
@@ -82,9 +100,52 @@ func (r *InPlaceUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, err
 	}
 
-	mAnnotations := m.GetAnnotations()
+	if m.Spec.Bootstrap.ConfigRef == nil {
+		// Not bootstrapped by a CK8sConfig, ignoring...
+		return ctrl.Result{}, nil
+	}
+
+	// Lookup the ck8s config used by the machine
+	config := &bootstrapv1.CK8sConfig{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: m.Namespace, Name: m.Spec.Bootstrap.ConfigRef.Name}, config); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to get CK8sConfig: %w", err)
+	}
+
+	patchHelper, err := patch.NewHelper(m, r.Client)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to create patch helper for machine: %w", err)
+	}
+
+	scope := &UpgradeScope{
+		Log:          log,
+		Machine:      m,
+		PatchHelper:  patchHelper,
+		Config:       config,
+		configBefore: config.DeepCopy(),
+	}
+
+	previous := config.Status.InPlaceUpgrade.DeepCopy()
+	defer func() {
+		metrics.RecordInPlaceUpgradeStatus(m, previous, scope.configBefore.Status.InPlaceUpgrade)
+	}()
+
+	// NOTE: Earlier releases recorded the state of the in-place upgrade in the annotations of the machine.
+	// It is converted to the status once, and the status is the source of truth from then on.
+	if scope.Status() == nil {
+		if status := inplace.StatusFromAnnotations(m.GetAnnotations()); status != nil {
+			log.Info("Converting in-place upgrade annotations to status", "phase", status.Phase)
+			config.Status.InPlaceUpgrade = status
+			delete(m.Annotations, bootstrapv1.InPlaceUpgradeChangeIDAnnotation)
+			if err := r.patchUpgrade(ctx, scope); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
 
-	upgradeOption, ok := mAnnotations[bootstrapv1.InPlaceUpgradeToAnnotation]
+	upgradeOption, ok := m.GetAnnotations()[bootstrapv1.InPlaceUpgradeToAnnotation]
 	if !ok {
 		// In-place upgrade to annotation not found, ignoring...
 		return ctrl.Result{}, nil
@@ -97,77 +158,74 @@ func (r *InPlaceUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	}
 
 	// Get the workload cluster for the machine
-	workloadCluster, err := r.getWorkloadClusterForMachine(ctx, util.ObjectKey(cluster), m)
+	microclusterPort := config.Spec.ControlPlaneConfig.GetMicroclusterPort()
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(cluster), microclusterPort)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to get workload cluster for machine: %w", err)
 	}
 
-	patchHelper, err := patch.NewHelper(m, r.Client)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to create patch helper for machine: %w", err)
-	}
+	scope.Cluster = cluster
+	scope.WorkloadCluster = workloadCluster
+	scope.UpgradeOption = upgradeOption
 
-	scope := &UpgradeScope{
-		Log:             log,
-		Cluster:         cluster,
-		WorkloadCluster: workloadCluster,
-		Machine:         m,
-		PatchHelper:     patchHelper,
-		UpgradeOption:   upgradeOption,
-	}
-
-	changeID, hasChangeIDAnnotation := mAnnotations[bootstrapv1.InPlaceUpgradeChangeIDAnnotation]
-
-	if hasChangeIDAnnotation {
-		// Still handling an in-progress upgrade since change ID is present
-		upgradeStatus, ok := mAnnotations[bootstrapv1.InPlaceUpgradeStatusAnnotation]
-		if !ok {
-			log.Info("Found in-place upgrade change ID without status, marking as failed")
-			if err := r.markUpgradeFailed(ctx, scope, "missing in-place upgrade status"); err != nil {
-				return ctrl.Result{}, fmt.Errorf("failed to mark in place upgrade status: %w", err)
-			}
-		}
+	// A different release is a new upgrade, the previous one is only kept to know the release the machine runs.
+	if status := scope.Status(); status == nil || status.Release != upgradeOption {
+		config.Status.InPlaceUpgrade = inplace.NewStatus(status, upgradeOption)
+	}
 
-		switch upgradeStatus {
-		case bootstrapv1.InPlaceUpgradeInProgressStatus:
-			return r.handleUpgradeInProgress(ctx, scope, changeID)
-		case bootstrapv1.InPlaceUpgradeDoneStatus:
-			return r.handleUpgradeDone(ctx, scope)
-		case bootstrapv1.InPlaceUpgradeFailedStatus:
-			return r.handleUpgradeFailed(ctx, scope)
-		default:
-			log.Info("Found invalid in-place upgrade status, marking as failed")
-			if err := r.markUpgradeFailed(ctx, scope, "invalid in-place upgrade status"); err != nil {
-				return ctrl.Result{}, fmt.Errorf("failed to mark in place upgrade status: %w", err)
-			}
+	switch scope.Status().Phase {
+	case bootstrapv1.InPlaceUpgradePhasePending, bootstrapv1.InPlaceUpgradePhasePaused:
+		// Starting a new upgrade
+		return r.handleUpgradeRequest(ctx, scope)
+	case bootstrapv1.InPlaceUpgradePhaseDraining:
+		return r.handleDraining(ctx, scope)
+	case bootstrapv1.InPlaceUpgradePhaseUpgrading:
+		return r.handleUpgradeInProgress(ctx, scope)
+	case bootstrapv1.InPlaceUpgradePhaseWaitingForNode:
+		return r.handleWaitingForNode(ctx, scope)
+	case bootstrapv1.InPlaceUpgradePhaseRollingBack:
+		return r.handleRollingBack(ctx, scope)
+	case bootstrapv1.InPlaceUpgradePhaseSucceeded:
+		return r.handleUpgradeDone(ctx, scope)
+	case bootstrapv1.InPlaceUpgradePhaseFailed:
+		return r.handleUpgradeFailed(ctx, scope)
+	case bootstrapv1.InPlaceUpgradePhaseRetriesExhausted:
+		return r.handleRetriesExhausted(ctx, scope)
+	default:
+		log.Info("Found invalid in-place upgrade phase, marking as failed", "phase", scope.Status().Phase)
+		if err := r.markUpgradeFailed(ctx, scope, "invalid in-place upgrade phase"); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to mark in place upgrade status: %w", err)
 		}
-
 		return ctrl.Result{}, nil
 	}
-
-	// Starting a new upgrade or retrying a failed one
-	return r.handleUpgradeRequest(ctx, scope)
 }
 
-func (r *InPlaceUpgradeReconciler) getWorkloadClusterForMachine(ctx context.Context, clusterKey types.NamespacedName, m *clusterv1.Machine) (*ck8s.Workload, error) {
-	// Lookup the ck8s config used by the machine
-	config := &bootstrapv1.CK8sConfig{}
-	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: m.Namespace, Name: m.Spec.Bootstrap.ConfigRef.Name}, config); err != nil {
-		return nil, err
+// patchUpgrade persists the in-place upgrade status on the CK8sConfig, then the annotations of the machine.
+// NOTE: The status is patched with optimistic locking, so that a reconciliation acting on a stale status
+// fails instead of repeating the steps of the upgrade that already happened.
+func (r *InPlaceUpgradeReconciler) patchUpgrade(ctx context.Context, scope *UpgradeScope) error {
+	if !equality.Semantic.DeepEqual(scope.Config.Status.InPlaceUpgrade, scope.configBefore.Status.InPlaceUpgrade) {
+		patch := client.MergeFromWithOptions(scope.configBefore, client.MergeFromWithOptimisticLock{})
+		if err := r.Client.Status().Patch(ctx, scope.Config, patch); err != nil {
+			return fmt.Errorf("failed to patch CK8sConfig status: %w", err)
+		}
+		scope.configBefore = scope.Config.DeepCopy()
 	}
 
-	microclusterPort := config.Spec.ControlPlaneConfig.GetMicroclusterPort()
-	return r.managementCluster.GetWorkloadCluster(ctx, clusterKey, microclusterPort)
+	if err := scope.PatchHelper.Patch(ctx, scope.Machine); err != nil {
+		return fmt.Errorf("failed to patch machine annotations: %w", err)
+	}
+	return nil
 }
 
 func (r *InPlaceUpgradeReconciler) markUpgradeInProgress(ctx context.Context, scope *UpgradeScope, changeID string) error {
-	mAnnotations := scope.Machine.GetAnnotations()
+	status := scope.Status()
+	status.ChangeID = changeID
+	inplace.SetPhase(status, bootstrapv1.InPlaceUpgradePhaseUpgrading)
 
-	mAnnotations[bootstrapv1.InPlaceUpgradeStatusAnnotation] = bootstrapv1.InPlaceUpgradeInProgressStatus
-	mAnnotations[bootstrapv1.InPlaceUpgradeChangeIDAnnotation] = changeID
-	scope.Machine.SetAnnotations(mAnnotations)
-	if err := scope.PatchHelper.Patch(ctx, scope.Machine); err != nil {
-		return fmt.Errorf("failed to patch machine annotations: %w", err)
+	scope.Machine.Annotations[bootstrapv1.InPlaceUpgradeStatusAnnotation] = bootstrapv1.InPlaceUpgradeInProgressStatus
+	if err := r.patchUpgrade(ctx, scope); err != nil {
+		return err
 	}
 
 	r.recorder.Eventf(scope.Machine, corev1.EventTypeNormal, bootstrapv1.InPlaceUpgradeInProgressEvent, "Performing in place upgrade with %s", scope.UpgradeOption)
@@ -175,49 +233,361 @@ func (r *InPlaceUpgradeReconciler) markUpgradeInProgress(ctx context.Context, sc
 }
 
 func (r *InPlaceUpgradeReconciler) markUpgradeDone(ctx context.Context, scope *UpgradeScope) error {
-	mAnnotations := scope.Machine.GetAnnotations()
+	if err := r.setNodeRelease(ctx, scope, scope.UpgradeOption); err != nil {
+		return err
+	}
 
-	mAnnotations[bootstrapv1.InPlaceUpgradeStatusAnnotation] = bootstrapv1.InPlaceUpgradeDoneStatus
-	scope.Machine.SetAnnotations(mAnnotations)
-	if err := scope.PatchHelper.Patch(ctx, scope.Machine); err != nil {
-		return fmt.Errorf("failed to patch machine annotations: %w", err)
+	now := metav1.Now()
+	status := scope.Status()
+	status.ChangeID = ""
+	status.RefreshedAt = nil
+	status.Message = ""
+	status.CompletedAt = &now
+	inplace.SetPhase(status, bootstrapv1.InPlaceUpgradePhaseSucceeded)
+
+	scope.Machine.Annotations[bootstrapv1.InPlaceUpgradeStatusAnnotation] = bootstrapv1.InPlaceUpgradeDoneStatus
+	if err := r.patchUpgrade(ctx, scope); err != nil {
+		return err
 	}
 
 	r.recorder.Eventf(scope.Machine, corev1.EventTypeNormal, bootstrapv1.InPlaceUpgradeDoneEvent, "Successfully performed in place upgrade with %s", scope.UpgradeOption)
 	return nil
 }
 
+// setNodeRelease records the release the node of the machine was refreshed to, so that later upgrades
+// roll back to it.
+func (r *InPlaceUpgradeReconciler) setNodeRelease(ctx context.Context, scope *UpgradeScope, release string) error {
+	nodeName, err := getNodeName(scope.Machine)
+	if err != nil {
+		return err
+	}
+
+	if err := scope.WorkloadCluster.SetNodeSnapRelease(ctx, nodeName, release); err != nil {
+		return fmt.Errorf("failed to record the release of node %q: %w", nodeName, err)
+	}
+	return nil
+}
+
 func (r *InPlaceUpgradeReconciler) markUpgradeFailed(ctx context.Context, scope *UpgradeScope, failure string) error {
-	mAnnotations := scope.Machine.GetAnnotations()
+	if err := r.uncordonDrainedNode(ctx, scope); err != nil {
+		return err
+	}
+
+	now := metav1.Now()
+	status := scope.Status()
+	status.ChangeID = ""
+	status.RefreshedAt = nil
+	status.Message = failure
+	status.LastFailedAttemptAt = &now
+	inplace.SetPhase(status, bootstrapv1.InPlaceUpgradePhaseFailed)
 
-	mAnnotations[bootstrapv1.InPlaceUpgradeStatusAnnotation] = bootstrapv1.InPlaceUpgradeFailedStatus
+	scope.Machine.Annotations[bootstrapv1.InPlaceUpgradeStatusAnnotation] = bootstrapv1.InPlaceUpgradeFailedStatus
 	// NOTE(Hue): Add an annotation here to indicate that the upgrade failed
 	// and we are not going to retry it.
-	mAnnotations[bootstrapv1.InPlaceUpgradeLastFailedAttemptAtAnnotation] = time.Now().Format(time.RFC1123Z)
-	scope.Machine.SetAnnotations(mAnnotations)
-	if err := scope.PatchHelper.Patch(ctx, scope.Machine); err != nil {
-		return fmt.Errorf("failed to patch machine annotations: %w", err)
+	scope.Machine.Annotations[bootstrapv1.InPlaceUpgradeLastFailedAttemptAtAnnotation] = now.Format(time.RFC1123Z)
+	if err := r.patchUpgrade(ctx, scope); err != nil {
+		return err
 	}
 
 	r.recorder.Eventf(scope.Machine, corev1.EventTypeWarning, bootstrapv1.InPlaceUpgradeFailedEvent, "Failed to perform in place upgrade with %s: %s", scope.UpgradeOption, failure)
 	return nil
 }
 
+// markDraining cordons the node of the machine and starts draining it.
+func (r *InPlaceUpgradeReconciler) markDraining(ctx context.Context, scope *UpgradeScope) error {
+	nodeName, err := getNodeName(scope.Machine)
+	if err != nil {
+		return err
+	}
+
+	if err := scope.WorkloadCluster.CordonNode(ctx, nodeName); err != nil {
+		return fmt.Errorf("failed to cordon node: %w", err)
+	}
+
+	now := metav1.Now()
+	status := scope.Status()
+	status.DrainStartedAt = &now
+	inplace.SetPhase(status, bootstrapv1.InPlaceUpgradePhaseDraining)
+
+	scope.Machine.Annotations[bootstrapv1.InPlaceUpgradeStatusAnnotation] = bootstrapv1.InPlaceUpgradeInProgressStatus
+	if err := r.patchUpgrade(ctx, scope); err != nil {
+		return err
+	}
+
+	r.recorder.Eventf(scope.Machine, corev1.EventTypeNormal, bootstrapv1.InPlaceUpgradeDrainingEvent, "Cordoned and draining node %q before in place upgrade with %s", nodeName, scope.UpgradeOption)
+	return nil
+}
+
+func (r *InPlaceUpgradeReconciler) markWaitingForNode(ctx context.Context, scope *UpgradeScope) error {
+	now := metav1.Now()
+	status := scope.Status()
+	status.RefreshedAt = &now
+	inplace.SetPhase(status, bootstrapv1.InPlaceUpgradePhaseWaitingForNode)
+
+	if err := r.patchUpgrade(ctx, scope); err != nil {
+		return err
+	}
+
+	r.recorder.Eventf(scope.Machine, corev1.EventTypeNormal, bootstrapv1.InPlaceUpgradeInProgressEvent, "Refreshed with %s, waiting for node to be ready", scope.UpgradeOption)
+	return nil
+}
+
+func (r *InPlaceUpgradeReconciler) markRollingBack(ctx context.Context, scope *UpgradeScope, changeID string, failure string) error {
+	status := scope.Status()
+	status.ChangeID = changeID
+	status.Message = failure
+	// NOTE: RefreshedAt is set again once the rollback refresh completes.
+	status.RefreshedAt = nil
+	inplace.SetPhase(status, bootstrapv1.InPlaceUpgradePhaseRollingBack)
+
+	if err := r.patchUpgrade(ctx, scope); err != nil {
+		return err
+	}
+
+	r.recorder.Eventf(scope.Machine, corev1.EventTypeWarning, bootstrapv1.InPlaceUpgradeRollingBackEvent, "In place upgrade with %s failed, rolling back to %s: %s", scope.UpgradeOption, status.PreviousRelease, failure)
+	return nil
+}
+
+func (r *InPlaceUpgradeReconciler) markRetriesExhausted(ctx context.Context, scope *UpgradeScope) error {
+	if err := r.uncordonDrainedNode(ctx, scope); err != nil {
+		return err
+	}
+
+	status := scope.Status()
+	inplace.SetPhase(status, bootstrapv1.InPlaceUpgradePhaseRetriesExhausted)
+
+	if err := r.patchUpgrade(ctx, scope); err != nil {
+		return err
+	}
+
+	r.recorder.Eventf(scope.Machine, corev1.EventTypeWarning, bootstrapv1.InPlaceUpgradeRetriesExhaustedEvent, "In place upgrade with %s failed %d times, set the %s annotation to retry", scope.UpgradeOption, status.Attempts, bootstrapv1.InPlaceUpgradeAcknowledgeFailureAnnotation)
+	return nil
+}
+
+func (r *InPlaceUpgradeReconciler) uncordonNode(ctx context.Context, scope *UpgradeScope, nodeName string) error {
+	if err := scope.WorkloadCluster.UncordonNode(ctx, nodeName); err != nil {
+		return fmt.Errorf("failed to uncordon node: %w", err)
+	}
+
+	r.recorder.Eventf(scope.Machine, corev1.EventTypeNormal, bootstrapv1.InPlaceUpgradeUncordonedEvent, "Uncordoned node %q", nodeName)
+	return nil
+}
+
+// uncordonDrainedNode puts the node back in service if it was cordoned and drained for the upgrade.
+// It is called before every terminal transition of the upgrade, so that the node is never left unschedulable.
+func (r *InPlaceUpgradeReconciler) uncordonDrainedNode(ctx context.Context, scope *UpgradeScope) error {
+	if scope.Status().DrainStartedAt == nil {
+		return nil
+	}
+
+	nodeName, err := getNodeName(scope.Machine)
+	if err != nil {
+		return err
+	}
+
+	if err := r.uncordonNode(ctx, scope, nodeName); err != nil {
+		return err
+	}
+
+	scope.Status().DrainStartedAt = nil
+	return nil
+}
+
+// getDrainOptions returns the drain options for the machine, or nil if draining is not enabled.
+// Options set on the machine take precedence over the ones set on its MachineDeployment or control plane.
+func (r *InPlaceUpgradeReconciler) getDrainOptions(ctx context.Context, m *clusterv1.Machine) (*inplace.DrainOptions, error) {
+	if inplace.HasDrainOptions(m) {
+		return inplace.GetDrainOptions(m)
+	}
+
+	owner, err := getMachineOwner(ctx, r.Client, m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owner of machine: %w", err)
+	}
+	if owner == nil {
+		return nil, nil
+	}
+
+	return inplace.GetDrainOptions(owner)
+}
+
+// getRetryPolicy returns the retry policy for the upgrade of the machine.
+// The policy set on the machine takes precedence over the one set on its MachineDeployment or control plane.
+func (r *InPlaceUpgradeReconciler) getRetryPolicy(ctx context.Context, m *clusterv1.Machine) (*inplace.RetryPolicy, error) {
+	if inplace.HasRetryPolicy(m) {
+		return inplace.GetRetryPolicy(m)
+	}
+
+	owner, err := getMachineOwner(ctx, r.Client, m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owner of machine: %w", err)
+	}
+	if owner == nil {
+		return inplace.DefaultRetryPolicy(), nil
+	}
+
+	return inplace.GetRetryPolicy(owner)
+}
+
+// isUpgradePaused checks if in-place upgrades are paused on the machine, on its MachineDeployment or
+// control plane, or on its Cluster.
+func (r *InPlaceUpgradeReconciler) isUpgradePaused(ctx context.Context, scope *UpgradeScope) (bool, error) {
+	objs := []client.Object{scope.Machine, scope.Cluster}
+
+	owner, err := getMachineOwner(ctx, r.Client, scope.Machine)
+	if err != nil {
+		return false, fmt.Errorf("failed to get owner of machine: %w", err)
+	}
+	if owner != nil {
+		objs = append(objs, owner)
+	}
+
+	return inplace.IsPaused(objs...), nil
+}
+
+// getMachineOwner returns the metadata of the object orchestrating the upgrades and certificates refreshes
+// of the machine, either its MachineDeployment or its control plane. Returns nil for machines with neither.
+func getMachineOwner(ctx context.Context, c client.Reader, m *clusterv1.Machine) (*metav1.PartialObjectMetadata, error) {
+	owner := &metav1.PartialObjectMetadata{}
+	key := client.ObjectKey{Namespace: m.Namespace}
+
+	if mdName, ok := m.Labels[clusterv1.MachineDeploymentNameLabel]; ok {
+		owner.SetGroupVersionKind(clusterv1.GroupVersion.WithKind("MachineDeployment"))
+		key.Name = mdName
+	} else if ref := metav1.GetControllerOf(m); ref != nil && ref.Kind == "CK8sControlPlane" {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse owner API version: %w", err)
+		}
+		owner.SetGroupVersionKind(gv.WithKind(ref.Kind))
+		key.Name = ref.Name
+	} else {
+		return nil, nil
+	}
+
+	if err := c.Get(ctx, key, owner); err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", owner.Kind, err)
+	}
+
+	return owner, nil
+}
+
+// getNodeReadyTimeout returns how long to wait for the node to become Ready after the refresh.
+// The timeout set on the machine takes precedence over the one set on its MachineDeployment or control plane.
+func (r *InPlaceUpgradeReconciler) getNodeReadyTimeout(ctx context.Context, m *clusterv1.Machine) (time.Duration, error) {
+	v, ok := m.Annotations[bootstrapv1.InPlaceUpgradeNodeReadyTimeoutAnnotation]
+	if !ok {
+		owner, err := getMachineOwner(ctx, r.Client, m)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get owner of machine: %w", err)
+		}
+		if owner != nil {
+			v, ok = owner.Annotations[bootstrapv1.InPlaceUpgradeNodeReadyTimeoutAnnotation]
+		}
+	}
+	if !ok {
+		return inplace.DefaultNodeReadyTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s annotation %q: %w", bootstrapv1.InPlaceUpgradeNodeReadyTimeoutAnnotation, v, err)
+	}
+	return timeout, nil
+}
+
+// getNodeName returns the name of the node of the machine.
+func getNodeName(m *clusterv1.Machine) (string, error) {
+	if m.Status.NodeRef == nil {
+		return "", fmt.Errorf("machine %q has no node", m.Name)
+	}
+	return m.Status.NodeRef.Name, nil
+}
+
+// getCurrentRelease returns the release of the k8s snap the node of the machine runs, as published on the node.
+// Nodes that did not publish it fall back to the release carried over from the previous in-place upgrade of the machine.
+// It returns an empty string if neither is known, failed upgrades of such machines are not rolled back.
+func (r *InPlaceUpgradeReconciler) getCurrentRelease(ctx context.Context, scope *UpgradeScope) (string, error) {
+	nodeName, err := getNodeName(scope.Machine)
+	if err != nil {
+		return "", err
+	}
+
+	release, err := scope.WorkloadCluster.GetNodeSnapRelease(ctx, nodeName)
+	if err != nil {
+		return "", err
+	}
+	if release != "" {
+		return release, nil
+	}
+	return scope.Status().PreviousRelease, nil
+}
+
 func (r *InPlaceUpgradeReconciler) handleUpgradeRequest(ctx context.Context, scope *UpgradeScope) (reconcile.Result, error) {
-	// Lookup the node token for the machine
-	nodeToken, err := token.LookupNodeToken(ctx, r.Client, util.ObjectKey(scope.Cluster), scope.Machine.Name)
+	status := scope.Status()
+
+	// NOTE: Pausing only holds back the start of an attempt, an attempt in flight is left to complete.
+	paused, err := r.isUpgradePaused(ctx, scope)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to lookup node token: %w", err)
+		return ctrl.Result{}, fmt.Errorf("failed to check if in-place upgrade is paused: %w", err)
+	}
+	if paused {
+		scope.Log.Info("In-place upgrade is paused, not starting the upgrade")
+		inplace.SetPhase(status, bootstrapv1.InPlaceUpgradePhasePaused)
+		if err := r.patchUpgrade(ctx, scope); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 	}
 
-	mAnnotations := scope.Machine.GetAnnotations()
+	drainOptions, err := r.getDrainOptions(ctx, scope.Machine)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get drain options: %w", err)
+	}
 
-	delete(mAnnotations, bootstrapv1.InPlaceUpgradeStatusAnnotation)
-	delete(mAnnotations, bootstrapv1.InPlaceUpgradeChangeIDAnnotation)
-	delete(mAnnotations, bootstrapv1.InPlaceUpgradeReleaseAnnotation)
-	scope.Machine.SetAnnotations(mAnnotations)
-	if err := scope.PatchHelper.Patch(ctx, scope.Machine); err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to patch machine annotations: %w", err)
+	// Record the release the machine runs before the upgrade, so that we can roll back to it on failure.
+	// NOTE: Retries keep the release recorded on the first attempt.
+	if status.Attempts == 0 {
+		release, err := r.getCurrentRelease(ctx, scope)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to get the current release of the machine: %w", err)
+		}
+		status.PreviousRelease = release
+	}
+
+	now := metav1.Now()
+	if status.StartedAt == nil {
+		status.StartedAt = &now
+	}
+	status.Attempts++
+	status.ChangeID = ""
+	status.RefreshedAt = nil
+	status.RollbackPhase = ""
+
+	delete(scope.Machine.Annotations, bootstrapv1.InPlaceUpgradeChangeIDAnnotation)
+	delete(scope.Machine.Annotations, bootstrapv1.InPlaceUpgradeReleaseAnnotation)
+	// NOTE: The attempt is recorded before starting it, a reconciliation that read a stale status fails here.
+	if err := r.patchUpgrade(ctx, scope); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if drainOptions != nil {
+		if err := r.markDraining(ctx, scope); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to start draining node: %w", err)
+		}
+
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	return r.refreshMachine(ctx, scope)
+}
+
+// refreshMachine performs the in-place upgrade through snap refresh.
+func (r *InPlaceUpgradeReconciler) refreshMachine(ctx context.Context, scope *UpgradeScope) (reconcile.Result, error) {
+	// Lookup the node token for the machine
+	nodeToken, err := token.LookupNodeToken(ctx, r.Client, util.ObjectKey(scope.Cluster), scope.Machine.Name)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to lookup node token: %w", err)
 	}
 
 	// Perform the in-place upgrade through snap refresh
@@ -231,17 +601,226 @@ func (r *InPlaceUpgradeReconciler) handleUpgradeRequest(ctx context.Context, sco
 		return ctrl.Result{}, fmt.Errorf("failed to mark in place upgrade status: %w", err)
 	}
 
+	return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+}
+
+// handleDraining evicts the pods of the cordoned node and refreshes the machine once the node is drained.
+// The upgrade fails if the node does not drain within the drain timeout.
+func (r *InPlaceUpgradeReconciler) handleDraining(ctx context.Context, scope *UpgradeScope) (reconcile.Result, error) {
+	nodeName, err := getNodeName(scope.Machine)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	remaining, drainErr := scope.WorkloadCluster.DrainNode(ctx, nodeName)
+	if drainErr != nil || remaining > 0 {
+		drainOptions, err := r.getDrainOptions(ctx, scope.Machine)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to get drain options: %w", err)
+		}
+		timeout := inplace.DefaultDrainTimeout
+		if drainOptions != nil {
+			timeout = drainOptions.Timeout
+		}
+
+		// NOTE: A node that keeps failing to drain times out as well, otherwise it would stay cordoned forever.
+		startedAt := scope.Status().DrainStartedAt
+		if startedAt == nil || time.Since(startedAt.Time) > timeout {
+			failure := fmt.Sprintf("timed out draining node %q with %d pods left to evict", nodeName, remaining)
+			if drainErr != nil {
+				failure = fmt.Sprintf("timed out draining node %q: %v", nodeName, drainErr)
+			}
+			scope.Log.Info("Timed out draining node, uncordoning", "node", nodeName, "remainingPods", remaining, "error", drainErr)
+			if err := r.markUpgradeFailed(ctx, scope, failure); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to mark in place upgrade status: %w", err)
+			}
+			return ctrl.Result{}, nil
+		}
+
+		if drainErr != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to drain node: %w", drainErr)
+		}
+		scope.Log.Info("Node is still draining, requeuing...", "node", nodeName, "remainingPods", remaining)
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	r.recorder.Eventf(scope.Machine, corev1.EventTypeNormal, bootstrapv1.InPlaceUpgradeDrainedEvent, "Drained node %q", nodeName)
+
+	return r.refreshMachine(ctx, scope)
+}
+
+// handleWaitingForNode waits for the refreshed node to become Ready and uncordons it if it was drained.
+// The upgrade is rolled back if the node does not become Ready in time.
+func (r *InPlaceUpgradeReconciler) handleWaitingForNode(ctx context.Context, scope *UpgradeScope) (reconcile.Result, error) {
+	ready, failure, err := r.checkNodeReady(ctx, scope)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if failure != "" {
+		scope.Log.Info("Node did not become ready in time, rolling back")
+		return r.rollback(ctx, scope, failure)
+	}
+	if !ready {
+		scope.Log.Info("Node is not ready yet, requeuing...")
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	// The node was drained before the refresh, we can now put it back in service.
+	if err := r.uncordonDrainedNode(ctx, scope); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.markUpgradeDone(ctx, scope); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to mark in place upgrade status: %w", err)
+	}
+
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// checkNodeReady checks if the node of the machine is Ready after the refresh recorded in the status.
+// It returns a failure message if the node did not become Ready within the node ready timeout.
+func (r *InPlaceUpgradeReconciler) checkNodeReady(ctx context.Context, scope *UpgradeScope) (bool, string, error) {
+	nodeName, err := getNodeName(scope.Machine)
+	if err != nil {
+		return false, "", err
+	}
+
+	ready, err := scope.WorkloadCluster.IsNodeReady(ctx, nodeName)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to check node readiness: %w", err)
+	}
+	if ready {
+		return true, "", nil
+	}
+
+	timeout, err := r.getNodeReadyTimeout(ctx, scope.Machine)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get node ready timeout: %w", err)
+	}
+
+	refreshedAt := scope.Status().RefreshedAt
+	if refreshedAt == nil || time.Since(refreshedAt.Time) > timeout {
+		return false, fmt.Sprintf("node %q did not become ready within %s", nodeName, timeout), nil
+	}
+	return false, "", nil
+}
+
+// rollback refreshes the machine back to the release it ran before the upgrade.
+// The upgrade is marked as failed right away if there is no release to roll back to.
+func (r *InPlaceUpgradeReconciler) rollback(ctx context.Context, scope *UpgradeScope, failure string) (reconcile.Result, error) {
+	previousRelease := scope.Status().PreviousRelease
+	if previousRelease == "" {
+		if err := r.markUpgradeFailed(ctx, scope, fmt.Sprintf("%s, no previous release to roll back to", failure)); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to mark in place upgrade status: %w", err)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Lookup the node token for the machine
+	nodeToken, err := token.LookupNodeToken(ctx, r.Client, util.ObjectKey(scope.Cluster), scope.Machine.Name)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to lookup node token: %w", err)
+	}
+
+	changeID, err := scope.WorkloadCluster.RefreshMachine(ctx, scope.Machine, *nodeToken, previousRelease)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to refresh machine back to %s: %w", previousRelease, err)
+	}
+
+	if err := r.markRollingBack(ctx, scope, changeID, failure); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to mark in place upgrade status: %w", err)
+	}
+
+	return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+}
+
+// handleRollingBack waits for the rollback refresh to complete and reports its outcome.
+// Either way, the upgrade is marked as failed.
+func (r *InPlaceUpgradeReconciler) handleRollingBack(ctx context.Context, scope *UpgradeScope) (reconcile.Result, error) {
+	// Lookup the node token for the machine
+	nodeToken, err := token.LookupNodeToken(ctx, r.Client, util.ObjectKey(scope.Cluster), scope.Machine.Name)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to lookup node token: %w", err)
+	}
+
+	status, err := scope.WorkloadCluster.GetRefreshStatusForMachine(ctx, scope.Machine, *nodeToken, scope.Status().ChangeID)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get rollback status for machine: %w", err)
+	}
+
+	if !status.Completed {
+		scope.Log.Info("In-place upgrade rollback still in progress, requeuing...")
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	return r.completeRollback(ctx, scope, status)
+}
+
+// completeRollback waits for the node to become Ready after the completed rollback refresh, reports the outcome
+// of the rollback and marks the upgrade as failed.
+func (r *InPlaceUpgradeReconciler) completeRollback(ctx context.Context, scope *UpgradeScope, refreshStatus *apiv1.SnapRefreshStatusResponse) (reconcile.Result, error) {
+	if refreshStatus.Status != "Done" {
+		return r.markRollbackFailed(ctx, scope, refreshStatus.ErrorMessage)
+	}
+
+	status := scope.Status()
+	if status.RefreshedAt == nil {
+		scope.Log.Info("In-place upgrade rollback refresh completed, waiting for node to be ready")
+		now := metav1.Now()
+		status.RefreshedAt = &now
+		if err := r.patchUpgrade(ctx, scope); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	ready, failure, err := r.checkNodeReady(ctx, scope)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if failure != "" {
+		return r.markRollbackFailed(ctx, scope, failure)
+	}
+	if !ready {
+		scope.Log.Info("Node is not ready yet after rollback, requeuing...")
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	if err := r.setNodeRelease(ctx, scope, status.PreviousRelease); err != nil {
+		return ctrl.Result{}, err
+	}
+	scope.Log.Info("In-place upgrade rolled back", "release", status.PreviousRelease)
+	status.RollbackPhase = bootstrapv1.InPlaceUpgradePhaseSucceeded
+	r.recorder.Eventf(scope.Machine, corev1.EventTypeNormal, bootstrapv1.InPlaceUpgradeRolledBackEvent, "Rolled back to %s", status.PreviousRelease)
+
+	if err := r.markUpgradeFailed(ctx, scope, fmt.Sprintf("%s, rolled back to %s", status.Message, status.PreviousRelease)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to mark in place upgrade status: %w", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// markRollbackFailed reports the failed rollback and marks the upgrade as failed.
+func (r *InPlaceUpgradeReconciler) markRollbackFailed(ctx context.Context, scope *UpgradeScope, failure string) (reconcile.Result, error) {
+	status := scope.Status()
+
+	scope.Log.Info("In-place upgrade rollback failed", "release", status.PreviousRelease, "error", failure)
+	status.RollbackPhase = bootstrapv1.InPlaceUpgradePhaseFailed
+	r.recorder.Eventf(scope.Machine, corev1.EventTypeWarning, bootstrapv1.InPlaceUpgradeRolledBackEvent, "Failed to roll back to %s: %s", status.PreviousRelease, failure)
+
+	if err := r.markUpgradeFailed(ctx, scope, fmt.Sprintf("%s, failed to roll back to %s: %s", status.Message, status.PreviousRelease, failure)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to mark in place upgrade status: %w", err)
+	}
 	return ctrl.Result{}, nil
 }
 
-func (r *InPlaceUpgradeReconciler) handleUpgradeInProgress(ctx context.Context, scope *UpgradeScope, changeID string) (reconcile.Result, error) {
+func (r *InPlaceUpgradeReconciler) handleUpgradeInProgress(ctx context.Context, scope *UpgradeScope) (reconcile.Result, error) {
 	// Lookup the node token for the machine
 	nodeToken, err := token.LookupNodeToken(ctx, r.Client, util.ObjectKey(scope.Cluster), scope.Machine.Name)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to lookup node token: %w", err)
 	}
 
-	status, err := scope.WorkloadCluster.GetRefreshStatusForMachine(ctx, scope.Machine, *nodeToken, changeID)
+	status, err := scope.WorkloadCluster.GetRefreshStatusForMachine(ctx, scope.Machine, *nodeToken, scope.Status().ChangeID)
 	if err != nil {
 		scope.Log.Info("Failed to get refresh status for machine", "error", err)
 		return ctrl.Result{}, fmt.Errorf("failed to get refresh status for machine: %w", err)
@@ -252,17 +831,21 @@ func (r *InPlaceUpgradeReconciler) handleUpgradeInProgress(ctx context.Context,
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 	}
 
+	return r.completeUpgrade(ctx, scope, status)
+}
+
+// completeUpgrade moves the upgrade forward once its refresh completed, rolling it back if the refresh failed.
+func (r *InPlaceUpgradeReconciler) completeUpgrade(ctx context.Context, scope *UpgradeScope, status *apiv1.SnapRefreshStatusResponse) (reconcile.Result, error) {
 	switch status.Status {
 	case "Done":
-		scope.Log.Info("In-place upgrade completed successfully")
-		if err := r.markUpgradeDone(ctx, scope); err != nil {
+		scope.Log.Info("In-place upgrade refresh completed, waiting for node to be ready")
+		if err := r.markWaitingForNode(ctx, scope); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to mark in place upgrade status: %w", err)
 		}
+		return ctrl.Result{Requeue: true}, nil
 	case "Error":
-		scope.Log.Info("In-place upgrade failed", "error", status.ErrorMessage)
-		if err := r.markUpgradeFailed(ctx, scope, status.ErrorMessage); err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to mark in place upgrade status: %w", err)
-		}
+		scope.Log.Info("In-place upgrade failed, rolling back", "error", status.ErrorMessage)
+		return r.rollback(ctx, scope, status.ErrorMessage)
 	default:
 		scope.Log.Info("Found invalid refresh status, marking as failed")
 		if err := r.markUpgradeFailed(ctx, scope, "invalid refresh status"); err != nil {
@@ -277,7 +860,6 @@ func (r *InPlaceUpgradeReconciler) handleUpgradeDone(ctx context.Context, scope
 	mAnnotations := scope.Machine.GetAnnotations()
 
 	delete(mAnnotations, bootstrapv1.InPlaceUpgradeToAnnotation)
-	delete(mAnnotations, bootstrapv1.InPlaceUpgradeChangeIDAnnotation)
 	delete(mAnnotations, bootstrapv1.InPlaceUpgradeLastFailedAttemptAtAnnotation)
 	mAnnotations[bootstrapv1.InPlaceUpgradeReleaseAnnotation] = scope.UpgradeOption
 	scope.Machine.SetAnnotations(mAnnotations)
@@ -288,22 +870,56 @@ func (r *InPlaceUpgradeReconciler) handleUpgradeDone(ctx context.Context, scope
 	return ctrl.Result{}, nil
 }
 
+// handleUpgradeFailed retries the failed upgrade once the backoff elapsed,
+// or gives up if the upgrade ran out of attempts.
 func (r *InPlaceUpgradeReconciler) handleUpgradeFailed(ctx context.Context, scope *UpgradeScope) (reconcile.Result, error) {
-	mAnnotations := scope.Machine.GetAnnotations()
+	policy, err := r.getRetryPolicy(ctx, scope.Machine)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get retry policy: %w", err)
+	}
+
+	status := scope.Status()
+	attempts := int(status.Attempts)
+	if policy.Exhausted(attempts) {
+		scope.Log.Info("In-place upgrade ran out of attempts, waiting for the failure to be acknowledged", "attempts", attempts)
+		if err := r.markRetriesExhausted(ctx, scope); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to mark in place upgrade status: %w", err)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if status.LastFailedAttemptAt != nil {
+		if wait := time.Until(status.LastFailedAttemptAt.Add(policy.BackoffFor(attempts))); wait > 0 {
+			scope.Log.Info("Waiting before retrying in-place upgrade", "attempts", attempts, "wait", wait)
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
 
 	// NOTE(Hue): We don't remove the `LastFailedAttemptAt` annotation here
 	// because we want to know if the upgrade failed at some point in the `MachineDeploymentReconciler`.
-	// This function triggers a retry by removing the `Status` and `ChangeID` annotations,
-	// but the `LastFailedAttemptAt` lets us descriminiate between a retry and a fresh upgrade.
+	// The `LastFailedAttemptAt` lets us descriminiate between a retry and a fresh upgrade.
 	// Overall, we don't remove the `LastFailedAttemptAt` annotation in the `InPlaceUpgradeReconciler`.
 	// That's the responsibility of the `MachineDeploymentReconciler`.
 
-	delete(mAnnotations, bootstrapv1.InPlaceUpgradeStatusAnnotation)
-	delete(mAnnotations, bootstrapv1.InPlaceUpgradeChangeIDAnnotation)
-	scope.Machine.SetAnnotations(mAnnotations)
-	if err := scope.PatchHelper.Patch(ctx, scope.Machine); err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to patch machine annotations: %w", err)
+	return r.handleUpgradeRequest(ctx, scope)
+}
+
+// handleRetriesExhausted waits for the user to acknowledge the failure of the upgrade before attempting it again.
+func (r *InPlaceUpgradeReconciler) handleRetriesExhausted(ctx context.Context, scope *UpgradeScope) (reconcile.Result, error) {
+	if _, ok := scope.Machine.Annotations[bootstrapv1.InPlaceUpgradeAcknowledgeFailureAnnotation]; !ok {
+		scope.Log.V(1).Info("In-place upgrade ran out of attempts, waiting for the failure to be acknowledged")
+		return ctrl.Result{}, nil
 	}
 
-	return ctrl.Result{}, nil
+	status := scope.Status()
+	status.Attempts = 0
+	inplace.SetPhase(status, bootstrapv1.InPlaceUpgradePhasePending)
+
+	delete(scope.Machine.Annotations, bootstrapv1.InPlaceUpgradeAcknowledgeFailureAnnotation)
+	if err := r.patchUpgrade(ctx, scope); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	r.recorder.Eventf(scope.Machine, corev1.EventTypeNormal, bootstrapv1.InPlaceUpgradeFailureAcknowledgedEvent, "In place upgrade failure acknowledged, retrying with %s", scope.UpgradeOption)
+	return ctrl.Result{Requeue: true}, nil
 }
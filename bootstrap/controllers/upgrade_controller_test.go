@@ -0,0 +1,359 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiv1 "github.com/canonical/k8s-snap-api/api/v1"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/ck8s"
+)
+
+// newDrainedUpgradeScope returns an upgrade scope for a machine whose node was cordoned and drained for the upgrade.
+// The status is applied to the in-place upgrade status of the CK8sConfig of the machine.
+func newDrainedUpgradeScope(g *WithT, annotations map[string]string, status bootstrapv1.InPlaceUpgradeStatus) (*InPlaceUpgradeReconciler, *UpgradeScope, client.Client) {
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(bootstrapv1.AddToScheme(scheme)).To(Succeed())
+
+	m := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "machine",
+			Annotations: map[string]string{
+				bootstrapv1.InPlaceUpgradeToAnnotation:     "channel=1.31-classic/stable",
+				bootstrapv1.InPlaceUpgradeStatusAnnotation: bootstrapv1.InPlaceUpgradeInProgressStatus,
+			},
+		},
+		Status: clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: "node"}},
+	}
+	for k, v := range annotations {
+		m.Annotations[k] = v
+	}
+
+	drainStartedAt := metav1.Now()
+	status.Release = m.Annotations[bootstrapv1.InPlaceUpgradeToAnnotation]
+	status.Attempts = max(status.Attempts, 1)
+	if status.DrainStartedAt == nil {
+		status.DrainStartedAt = &drainStartedAt
+	}
+	config := &bootstrapv1.CK8sConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "config"},
+		Status:     bootstrapv1.CK8sConfigStatus{InPlaceUpgrade: &status},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m, config).WithStatusSubresource(config).Build()
+
+	patchHelper, err := patch.NewHelper(m, c)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Spec:       corev1.NodeSpec{Unschedulable: true},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	workloadClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(node).Build()
+
+	r := &InPlaceUpgradeReconciler{Client: c, recorder: record.NewFakeRecorder(10)}
+	return r, &UpgradeScope{
+		Log:             logr.Discard(),
+		WorkloadCluster: &ck8s.Workload{Client: workloadClient},
+		Machine:         m,
+		PatchHelper:     patchHelper,
+		UpgradeOption:   m.Annotations[bootstrapv1.InPlaceUpgradeToAnnotation],
+		Config:          config,
+		configBefore:    config.DeepCopy(),
+	}, workloadClient
+}
+
+// getUpgradeStatus returns the persisted in-place upgrade status of the machine of the scope.
+func getUpgradeStatus(g *WithT, c client.Client, scope *UpgradeScope) *bootstrapv1.InPlaceUpgradeStatus {
+	config := &bootstrapv1.CK8sConfig{}
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(scope.Config), config)).To(Succeed())
+	g.Expect(config.Status.InPlaceUpgrade).ToNot(BeNil())
+	return config.Status.InPlaceUpgrade
+}
+
+func TestInPlaceUpgradeTerminalStatesUncordonNode(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name           string
+		annotations    map[string]string
+		status         bootstrapv1.InPlaceUpgradeStatus
+		reconcile      func(r *InPlaceUpgradeReconciler, scope *UpgradeScope) error
+		expectedPhase  bootstrapv1.InPlaceUpgradePhase
+		expectedStatus string
+	}{
+		{
+			name:   "no previous release to roll back to",
+			status: bootstrapv1.InPlaceUpgradeStatus{Phase: bootstrapv1.InPlaceUpgradePhaseWaitingForNode},
+			reconcile: func(r *InPlaceUpgradeReconciler, scope *UpgradeScope) error {
+				_, err := r.rollback(ctx, scope, "refresh failed")
+				return err
+			},
+			expectedPhase:  bootstrapv1.InPlaceUpgradePhaseFailed,
+			expectedStatus: bootstrapv1.InPlaceUpgradeFailedStatus,
+		},
+		{
+			name:   "invalid refresh status",
+			status: bootstrapv1.InPlaceUpgradeStatus{Phase: bootstrapv1.InPlaceUpgradePhaseUpgrading},
+			reconcile: func(r *InPlaceUpgradeReconciler, scope *UpgradeScope) error {
+				_, err := r.completeUpgrade(ctx, scope, &apiv1.SnapRefreshStatusResponse{Completed: true, Status: "Unknown"})
+				return err
+			},
+			expectedPhase:  bootstrapv1.InPlaceUpgradePhaseFailed,
+			expectedStatus: bootstrapv1.InPlaceUpgradeFailedStatus,
+		},
+		{
+			name: "rollback failed",
+			status: bootstrapv1.InPlaceUpgradeStatus{
+				Phase:           bootstrapv1.InPlaceUpgradePhaseRollingBack,
+				PreviousRelease: "channel=1.30-classic/stable",
+			},
+			reconcile: func(r *InPlaceUpgradeReconciler, scope *UpgradeScope) error {
+				_, err := r.completeRollback(ctx, scope, &apiv1.SnapRefreshStatusResponse{Completed: true, Status: "Error", ErrorMessage: "boom"})
+				return err
+			},
+			expectedPhase:  bootstrapv1.InPlaceUpgradePhaseFailed,
+			expectedStatus: bootstrapv1.InPlaceUpgradeFailedStatus,
+		},
+		{
+			name: "rollback done",
+			status: bootstrapv1.InPlaceUpgradeStatus{
+				Phase:           bootstrapv1.InPlaceUpgradePhaseRollingBack,
+				PreviousRelease: "channel=1.30-classic/stable",
+				RefreshedAt:     ptr.To(metav1.Now()),
+			},
+			reconcile: func(r *InPlaceUpgradeReconciler, scope *UpgradeScope) error {
+				_, err := r.completeRollback(ctx, scope, &apiv1.SnapRefreshStatusResponse{Completed: true, Status: "Done"})
+				return err
+			},
+			expectedPhase:  bootstrapv1.InPlaceUpgradePhaseFailed,
+			expectedStatus: bootstrapv1.InPlaceUpgradeFailedStatus,
+		},
+		{
+			name: "drain failing past the timeout",
+			status: bootstrapv1.InPlaceUpgradeStatus{
+				Phase:          bootstrapv1.InPlaceUpgradePhaseDraining,
+				DrainStartedAt: ptr.To(metav1.NewTime(time.Now().Add(-24 * time.Hour))),
+			},
+			reconcile: func(r *InPlaceUpgradeReconciler, scope *UpgradeScope) error {
+				// NOTE: Listing the pods of the node fails, the fake workload client has no spec.nodeName index.
+				_, err := r.handleDraining(ctx, scope)
+				return err
+			},
+			expectedPhase:  bootstrapv1.InPlaceUpgradePhaseFailed,
+			expectedStatus: bootstrapv1.InPlaceUpgradeFailedStatus,
+		},
+		{
+			name: "retries exhausted",
+			annotations: map[string]string{
+				bootstrapv1.InPlaceUpgradeStatusAnnotation:      bootstrapv1.InPlaceUpgradeFailedStatus,
+				bootstrapv1.InPlaceUpgradeMaxAttemptsAnnotation: "1",
+			},
+			status: bootstrapv1.InPlaceUpgradeStatus{Phase: bootstrapv1.InPlaceUpgradePhaseFailed, Attempts: 1},
+			reconcile: func(r *InPlaceUpgradeReconciler, scope *UpgradeScope) error {
+				_, err := r.handleUpgradeFailed(ctx, scope)
+				return err
+			},
+			expectedPhase:  bootstrapv1.InPlaceUpgradePhaseRetriesExhausted,
+			expectedStatus: bootstrapv1.InPlaceUpgradeFailedStatus,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			r, scope, workloadClient := newDrainedUpgradeScope(g, tc.annotations, tc.status)
+
+			g.Expect(tc.reconcile(r, scope)).To(Succeed())
+
+			status := getUpgradeStatus(g, r.Client, scope)
+			g.Expect(status.Phase).To(Equal(tc.expectedPhase))
+			g.Expect(status.DrainStartedAt).To(BeNil())
+
+			m := &clusterv1.Machine{}
+			g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(scope.Machine), m)).To(Succeed())
+			g.Expect(m.Annotations).To(HaveKeyWithValue(bootstrapv1.InPlaceUpgradeStatusAnnotation, tc.expectedStatus))
+
+			node := &corev1.Node{}
+			g.Expect(workloadClient.Get(ctx, client.ObjectKey{Name: "node"}, node)).To(Succeed())
+			g.Expect(node.Spec.Unschedulable).To(BeFalse())
+		})
+	}
+}
+
+func TestInPlaceUpgradeRollbackWaitsForNode(t *testing.T) {
+	ctx := context.Background()
+	done := &apiv1.SnapRefreshStatusResponse{Completed: true, Status: "Done"}
+	rollingBack := bootstrapv1.InPlaceUpgradeStatus{
+		Phase:           bootstrapv1.InPlaceUpgradePhaseRollingBack,
+		PreviousRelease: "channel=1.30-classic/stable",
+	}
+
+	setNodeReady := func(g *WithT, c client.Client, ready corev1.ConditionStatus) {
+		node := &corev1.Node{}
+		g.Expect(c.Get(ctx, client.ObjectKey{Name: "node"}, node)).To(Succeed())
+		node.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}}
+		g.Expect(c.Status().Update(ctx, node)).To(Succeed())
+	}
+
+	t.Run("waits until the node is ready", func(t *testing.T) {
+		g := NewWithT(t)
+		r, scope, workloadClient := newDrainedUpgradeScope(g, nil, rollingBack)
+		setNodeReady(g, workloadClient, corev1.ConditionFalse)
+
+		// The rollback refresh completed, the node gets the node ready timeout to become ready.
+		result, err := r.completeRollback(ctx, scope, done)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(result.RequeueAfter).ToNot(BeZero())
+		g.Expect(getUpgradeStatus(g, r.Client, scope).RefreshedAt).ToNot(BeNil())
+
+		result, err = r.completeRollback(ctx, scope, done)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(result.RequeueAfter).ToNot(BeZero())
+		g.Expect(getUpgradeStatus(g, r.Client, scope).Phase).To(Equal(bootstrapv1.InPlaceUpgradePhaseRollingBack))
+
+		node := &corev1.Node{}
+		g.Expect(workloadClient.Get(ctx, client.ObjectKey{Name: "node"}, node)).To(Succeed())
+		g.Expect(node.Spec.Unschedulable).To(BeTrue())
+
+		setNodeReady(g, workloadClient, corev1.ConditionTrue)
+
+		_, err = r.completeRollback(ctx, scope, done)
+		g.Expect(err).ToNot(HaveOccurred())
+		status := getUpgradeStatus(g, r.Client, scope)
+		g.Expect(status.Phase).To(Equal(bootstrapv1.InPlaceUpgradePhaseFailed))
+		g.Expect(status.RollbackPhase).To(Equal(bootstrapv1.InPlaceUpgradePhaseSucceeded))
+		g.Expect(scope.Machine.Annotations).To(HaveKeyWithValue(bootstrapv1.InPlaceUpgradeStatusAnnotation, bootstrapv1.InPlaceUpgradeFailedStatus))
+		g.Expect(workloadClient.Get(ctx, client.ObjectKey{Name: "node"}, node)).To(Succeed())
+		g.Expect(node.Spec.Unschedulable).To(BeFalse())
+		g.Expect(node.Annotations).To(HaveKeyWithValue(ck8s.SnapReleaseAnnotation, "channel=1.30-classic/stable"))
+	})
+
+	t.Run("fails the rollback if the node does not become ready in time", func(t *testing.T) {
+		g := NewWithT(t)
+		status := rollingBack
+		status.RefreshedAt = ptr.To(metav1.NewTime(time.Now().Add(-time.Hour)))
+		r, scope, workloadClient := newDrainedUpgradeScope(g, map[string]string{
+			bootstrapv1.InPlaceUpgradeNodeReadyTimeoutAnnotation: "10m",
+		}, status)
+		setNodeReady(g, workloadClient, corev1.ConditionFalse)
+
+		_, err := r.completeRollback(ctx, scope, done)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(getUpgradeStatus(g, r.Client, scope).Phase).To(Equal(bootstrapv1.InPlaceUpgradePhaseFailed))
+		g.Expect(getUpgradeStatus(g, r.Client, scope).RollbackPhase).To(Equal(bootstrapv1.InPlaceUpgradePhaseFailed))
+		g.Expect(scope.Machine.Annotations).To(HaveKeyWithValue(bootstrapv1.InPlaceUpgradeStatusAnnotation, bootstrapv1.InPlaceUpgradeFailedStatus))
+	})
+}
+
+func TestInPlaceUpgradeRecordsNodeRelease(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name            string
+		status          bootstrapv1.InPlaceUpgradeStatus
+		nodeRelease     string
+		expectedRelease string
+	}{
+		{
+			name:            "release published by the node",
+			status:          bootstrapv1.InPlaceUpgradeStatus{PreviousRelease: "channel=1.29-classic/stable"},
+			nodeRelease:     "channel=1.30-classic/stable",
+			expectedRelease: "channel=1.30-classic/stable",
+		},
+		{
+			name:            "release of the last in-place upgrade",
+			status:          bootstrapv1.InPlaceUpgradeStatus{PreviousRelease: "channel=1.29-classic/stable"},
+			expectedRelease: "channel=1.29-classic/stable",
+		},
+		{
+			name: "unknown release",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			tc.status.Phase = bootstrapv1.InPlaceUpgradePhasePending
+			r, scope, workloadClient := newDrainedUpgradeScope(g, map[string]string{bootstrapv1.InPlaceUpgradeDrainAnnotation: "true"}, tc.status)
+			scope.Status().Attempts = 0
+			scope.Cluster = &clusterv1.Cluster{}
+
+			node := &corev1.Node{}
+			g.Expect(workloadClient.Get(ctx, client.ObjectKey{Name: "node"}, node)).To(Succeed())
+			node.Annotations = map[string]string{ck8s.SnapReleaseAnnotation: tc.nodeRelease}
+			g.Expect(workloadClient.Update(ctx, node)).To(Succeed())
+
+			_, err := r.handleUpgradeRequest(ctx, scope)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			status := getUpgradeStatus(g, r.Client, scope)
+			g.Expect(status.PreviousRelease).To(Equal(tc.expectedRelease))
+			g.Expect(status.Phase).To(Equal(bootstrapv1.InPlaceUpgradePhaseDraining))
+			g.Expect(status.Attempts).To(BeEquivalentTo(1))
+		})
+	}
+}
+
+func TestInPlaceUpgradeConvertsAnnotationsOnce(t *testing.T) {
+	ctx := context.Background()
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(bootstrapv1.AddToScheme(scheme)).To(Succeed())
+
+	m := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "machine",
+			Annotations: map[string]string{
+				bootstrapv1.InPlaceUpgradeReleaseAnnotation:  "channel=1.31-classic/stable",
+				bootstrapv1.InPlaceUpgradeStatusAnnotation:   bootstrapv1.InPlaceUpgradeDoneStatus,
+				bootstrapv1.InPlaceUpgradeChangeIDAnnotation: "1",
+			},
+		},
+		Spec: clusterv1.MachineSpec{
+			Bootstrap: clusterv1.Bootstrap{ConfigRef: &corev1.ObjectReference{Name: "config"}},
+		},
+	}
+	config := &bootstrapv1.CK8sConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "config"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m, config).WithStatusSubresource(config).Build()
+	r := &InPlaceUpgradeReconciler{Client: c, Log: logr.Discard(), recorder: record.NewFakeRecorder(10)}
+
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(m)}
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(config), config)).To(Succeed())
+	g.Expect(config.Status.InPlaceUpgrade).ToNot(BeNil())
+	g.Expect(config.Status.InPlaceUpgrade.Phase).To(Equal(bootstrapv1.InPlaceUpgradePhaseSucceeded))
+	g.Expect(config.Status.InPlaceUpgrade.Release).To(Equal("channel=1.31-classic/stable"))
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(m), m)).To(Succeed())
+	g.Expect(m.Annotations).ToNot(HaveKey(bootstrapv1.InPlaceUpgradeChangeIDAnnotation))
+
+	// The status is the source of truth once converted, the annotations are not converted again.
+	m.Annotations[bootstrapv1.InPlaceUpgradeReleaseAnnotation] = "channel=1.32-classic/stable"
+	g.Expect(c.Update(ctx, m)).To(Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(config), config)).To(Succeed())
+	g.Expect(config.Status.InPlaceUpgrade.Release).To(Equal("channel=1.31-classic/stable"))
+}
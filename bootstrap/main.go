@@ -36,6 +36,7 @@ import (
 
 	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
 	"github.com/canonical/cluster-api-k8s/bootstrap/controllers"
+	"github.com/canonical/cluster-api-k8s/bootstrap/webhooks"
 )
 
 var (
@@ -56,6 +57,7 @@ func main() {
 	var enableLeaderElection bool
 	var syncPeriod time.Duration
 	var k8sdDialTimeout time.Duration
+	var certificatesExpirySyncInterval time.Duration
 
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
@@ -68,6 +70,9 @@ func main() {
 	flag.DurationVar(&k8sdDialTimeout, "k8sd-dial-timeout-duration", 60*time.Second,
 		"Duration that the proxy client waits at most to establish a connection with k8sd")
 
+	flag.DurationVar(&certificatesExpirySyncInterval, "certificates-expiry-sync-interval", time.Hour,
+		"The interval at which the certificates expiry of machines is queried again. If 0, it is only queried once")
+
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
@@ -118,22 +123,32 @@ func main() {
 	}
 
 	if err = (&controllers.CertificatesReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Certificates"),
-		Scheme: mgr.GetScheme(),
+		Client:             mgr.GetClient(),
+		Log:                ctrl.Log.WithName("controllers").WithName("Certificates"),
+		Scheme:             mgr.GetScheme(),
+		ExpirySyncInterval: certificatesExpirySyncInterval,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Certificates")
 		os.Exit(1)
 	}
 
 	if err = (&controllers.OrchestratedInPlaceUpgradeController{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("OrchestratedInPlaceUpgrade"),
+		Client:          mgr.GetClient(),
+		Log:             ctrl.Log.WithName("controllers").WithName("OrchestratedInPlaceUpgrade"),
+		K8sdDialTimeout: k8sdDialTimeout,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "OrchestratedInPlaceUpgrade")
 		os.Exit(1)
 	}
 
+	if err = (&controllers.MachineDeployCertificatesReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("MachineDeployCertificates"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MachineDeployCertificates")
+		os.Exit(1)
+	}
+
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
 		if err = (&bootstrapv1.CK8sConfig{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "CK8sConfig")
@@ -143,6 +158,10 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "CK8sConfigTemplate")
 			os.Exit(1)
 		}
+		if err = webhooks.SetupCertificatesRefreshWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "CertificatesRefresh")
+			os.Exit(1)
+		}
 	}
 	// +kubebuilder:scaffold:builder
 
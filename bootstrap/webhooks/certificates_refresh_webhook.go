@@ -0,0 +1,31 @@
+package webhooks
+
+import (
+	"fmt"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/canonical/cluster-api-k8s/pkg/certificates"
+)
+
+// NOTE: Failures are ignored, so that machines of the management cluster can still be updated while the
+// bootstrap provider is unavailable. The certificates controllers report invalid refresh requests anyway.
+// +kubebuilder:webhook:verbs=create;update,path=/validate-cluster-x-k8s-io-v1beta1-machine,mutating=false,failurePolicy=ignore,matchPolicy=Equivalent,groups=cluster.x-k8s.io,resources=machines,versions=v1beta1,name=validation-certificates-refresh.machine.bootstrap.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:webhook:verbs=create;update,path=/validate-cluster-x-k8s-io-v1beta1-machinedeployment,mutating=false,failurePolicy=ignore,matchPolicy=Equivalent,groups=cluster.x-k8s.io,resources=machinedeployments,versions=v1beta1,name=validation-certificates-refresh.machinedeployment.bootstrap.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
+
+// SetupCertificatesRefreshWebhookWithManager sets up the webhooks validating the certificates refresh
+// annotations of Machines and MachineDeployments.
+func SetupCertificatesRefreshWebhookWithManager(mgr ctrl.Manager) error {
+	validator := &certificates.RefreshValidator{Client: mgr.GetClient()}
+	for _, obj := range []client.Object{&clusterv1.Machine{}, &clusterv1.MachineDeployment{}} {
+		if err := ctrl.NewWebhookManagedBy(mgr).
+			For(obj).
+			WithValidator(validator).
+			Complete(); err != nil {
+			return fmt.Errorf("failed to create webhook for %T: %w", obj, err)
+		}
+	}
+	return nil
+}
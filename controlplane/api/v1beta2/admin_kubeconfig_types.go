@@ -0,0 +1,82 @@
+package v1beta2
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// DefaultAdminKubeconfigValidity is the default validity of the client certificate of the admin kubeconfig.
+	DefaultAdminKubeconfigValidity = 365 * 24 * time.Hour
+)
+
+// AdminKubeconfigIdentity is the identity that the admin kubeconfig authenticates as.
+type AdminKubeconfigIdentity string
+
+const (
+	// AdminKubeconfigIdentitySystemMasters authenticates as a member of the system:masters group.
+	// Access of the kubeconfig cannot be revoked until its client certificate expires.
+	AdminKubeconfigIdentitySystemMasters AdminKubeconfigIdentity = "SystemMasters"
+	// AdminKubeconfigIdentityClusterAdmin authenticates as a user bound to the cluster-admin ClusterRole.
+	// Access of the kubeconfig is revoked by deleting the ClusterRoleBinding of the user.
+	AdminKubeconfigIdentityClusterAdmin AdminKubeconfigIdentity = "ClusterAdmin"
+)
+
+// AdminKubeconfigSpec configures the admin kubeconfig secret of the cluster.
+type AdminKubeconfigSpec struct {
+	// Validity is how long the client certificate of the kubeconfig is valid. Defaults to 1 year.
+	// +optional
+	Validity *metav1.Duration `json:"validity,omitempty"`
+
+	// RenewBefore is how long before its client certificate expires the kubeconfig is re-issued.
+	// Must be shorter than Validity. Defaults to half of Validity.
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+
+	// Identity is the identity that the kubeconfig authenticates as. Defaults to SystemMasters.
+	// With ClusterAdmin, the kubeconfig is issued with SystemMasters until the control plane is initialized.
+	// Every re-issued kubeconfig then authenticates as a new user, and only the users of the current and
+	// the previous kubeconfigs stay bound to cluster-admin.
+	// +optional
+	// +kubebuilder:validation:Enum=SystemMasters;ClusterAdmin
+	Identity AdminKubeconfigIdentity `json:"identity,omitempty"`
+}
+
+// GetValidity returns how long the client certificate of the kubeconfig is valid.
+func (s *AdminKubeconfigSpec) GetValidity() time.Duration {
+	if s == nil || s.Validity == nil || s.Validity.Duration <= 0 {
+		return DefaultAdminKubeconfigValidity
+	}
+	return s.Validity.Duration
+}
+
+// GetRenewBefore returns how long before its client certificate expires the kubeconfig is re-issued.
+func (s *AdminKubeconfigSpec) GetRenewBefore() time.Duration {
+	if s == nil || s.RenewBefore == nil || s.RenewBefore.Duration <= 0 {
+		return s.GetValidity() / 2
+	}
+	return s.RenewBefore.Duration
+}
+
+// GetIdentity returns the identity that the kubeconfig authenticates as.
+func (s *AdminKubeconfigSpec) GetIdentity() AdminKubeconfigIdentity {
+	if s == nil || s.Identity == "" {
+		return AdminKubeconfigIdentitySystemMasters
+	}
+	return s.Identity
+}
+
+// Validate checks that the kubeconfig is renewed before its client certificate expires, and not as soon as it is issued.
+func (s *AdminKubeconfigSpec) Validate(path *field.Path) field.ErrorList {
+	if s == nil || s.RenewBefore == nil {
+		return nil
+	}
+
+	if renewBefore, validity := s.GetRenewBefore(), s.GetValidity(); renewBefore >= validity {
+		return field.ErrorList{field.Invalid(path.Child("renewBefore"), s.RenewBefore.Duration.String(), fmt.Sprintf("must be shorter than the validity %s", validity))}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package v1beta2
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// DefaultCertificateAuthorityDuration is the default validity requested for the certificate authorities
+	// issued by an external issuer.
+	DefaultCertificateAuthorityDuration = 5 * 365 * 24 * time.Hour
+)
+
+// CertificateAuthorityIssuerSpec configures an external issuer for the certificate authorities of the cluster.
+// The certificate authorities are issued as intermediates before the first control plane machine is created,
+// and are written to the <cluster>-ca, <cluster>-cca and <cluster>-proxy secrets. Existing secrets are never
+// re-issued.
+// Exactly one of CertManager and Webhook must be set.
+// +kubebuilder:validation:XValidation:rule="has(self.certManager) != has(self.webhook)",message="exactly one of certManager and webhook must be set"
+type CertificateAuthorityIssuerSpec struct {
+	// CertManager issues the certificate authorities from a cert-manager Issuer or ClusterIssuer.
+	// +optional
+	CertManager *CertManagerIssuerSpec `json:"certManager,omitempty"`
+
+	// Webhook issues the certificate authorities from an external signing endpoint.
+	// +optional
+	Webhook *WebhookIssuerSpec `json:"webhook,omitempty"`
+
+	// Duration is the validity requested for the certificate authorities. Defaults to 5 years.
+	// The issuer may issue certificates with a shorter validity.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+}
+
+// CertManagerIssuerSpec references a cert-manager Issuer or ClusterIssuer.
+type CertManagerIssuerSpec struct {
+	// Name is the name of the issuer. Issuers are looked up in the namespace of the CK8sControlPlane.
+	Name string `json:"name"`
+
+	// Kind is the kind of the issuer. Defaults to Issuer.
+	// +optional
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	Kind string `json:"kind,omitempty"`
+
+	// Group is the API group of the issuer. Defaults to cert-manager.io.
+	// +optional
+	Group string `json:"group,omitempty"`
+}
+
+// WebhookIssuerSpec configures an external signing endpoint.
+// The endpoint receives a POST request with a JSON body holding the PEM encoded certificate signing
+// request ("csr"), the cluster ("cluster"), the certificate authority ("purpose") and the requested
+// validity in seconds ("durationSeconds"). It returns a JSON body holding the PEM encoded certificate
+// ("certificate"), optionally followed by its chain.
+type WebhookIssuerSpec struct {
+	// URL is the HTTPS URL of the signing endpoint.
+	// +kubebuilder:validation:Pattern=`^https://`
+	URL string `json:"url"`
+
+	// CABundle is the PEM encoded CA bundle to verify the signing endpoint.
+	// Defaults to the system trust store.
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
+
+	// TokenSecretRef references a secret in the namespace of the CK8sControlPlane, whose "token" key
+	// holds the bearer token sent to the signing endpoint.
+	// +optional
+	TokenSecretRef *corev1.LocalObjectReference `json:"tokenSecretRef,omitempty"`
+}
+
+// GetDuration returns the validity requested for the certificate authorities.
+func (s *CertificateAuthorityIssuerSpec) GetDuration() time.Duration {
+	if s == nil || s.Duration == nil || s.Duration.Duration <= 0 {
+		return DefaultCertificateAuthorityDuration
+	}
+	return s.Duration.Duration
+}
+
+// Validate checks that exactly one of CertManager and Webhook is set.
+func (s *CertificateAuthorityIssuerSpec) Validate(path *field.Path) field.ErrorList {
+	if s == nil {
+		return nil
+	}
+
+	switch {
+	case s.CertManager != nil && s.Webhook != nil:
+		return field.ErrorList{field.Forbidden(path.Child("webhook"), "must not be set together with certManager")}
+	case s.CertManager == nil && s.Webhook == nil:
+		return field.ErrorList{field.Required(path, "one of certManager and webhook must be set")}
+	}
+	return nil
+}
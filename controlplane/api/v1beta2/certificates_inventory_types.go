@@ -0,0 +1,42 @@
+package v1beta2
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+)
+
+const (
+	// DefaultCertificatesExpiryThreshold is how long before a certificate of the cluster expires the
+	// CertificatesExpiringSoon condition is set by default.
+	DefaultCertificatesExpiryThreshold = 30 * 24 * time.Hour
+)
+
+// CertificatesInventory is the observed expiry of the certificates of the cluster, as seen from the management cluster.
+type CertificatesInventory struct {
+	// Secrets are the certificates stored in the secrets of the cluster that are not certificate authorities,
+	// e.g. the client certificate of the kubeconfig. They are named after their secret.
+	// The certificate authorities are reported in the CertificateAuthorities status of the CK8sControlPlane.
+	// +optional
+	Secrets []bootstrapv1.CertificateStatus `json:"secrets,omitempty"`
+
+	// EarliestMachineExpiry is the earliest expiry of the certificates of the machines of the cluster,
+	// control plane and workers, as recorded on the machines.
+	// +optional
+	EarliestMachineExpiry *metav1.Time `json:"earliestMachineExpiry,omitempty"`
+
+	// EarliestExpiringMachine is the name of the machine whose certificates expire first.
+	// +optional
+	EarliestExpiringMachine string `json:"earliestExpiringMachine,omitempty"`
+}
+
+// GetCertificatesExpiryThreshold returns how long before a certificate of the cluster expires the
+// CertificatesExpiringSoon condition is set.
+func (s *CK8sControlPlaneSpec) GetCertificatesExpiryThreshold() time.Duration {
+	if s.CertificatesExpiryThreshold == nil || s.CertificatesExpiryThreshold.Duration <= 0 {
+		return DefaultCertificatesExpiryThreshold
+	}
+	return s.CertificatesExpiryThreshold.Duration
+}
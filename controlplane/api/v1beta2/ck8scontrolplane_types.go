@@ -88,6 +88,34 @@ type CK8sControlPlaneSpec struct {
 	// +optional
 	// +kubebuilder:default={rollingUpdate: {maxSurge: 1}}
 	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// CertificatesRenewal configures the automatic renewal of the certificates of the control plane
+	// machines before they expire.
+	// +optional
+	CertificatesRenewal *bootstrapv1.CertificatesRenewalPolicy `json:"certificatesRenewal,omitempty"`
+
+	// AdminKubeconfig configures the admin kubeconfig secret of the cluster.
+	// +optional
+	AdminKubeconfig *AdminKubeconfigSpec `json:"adminKubeconfig,omitempty"`
+
+	// CertificateAuthorityIssuer configures an external issuer for the certificate authorities of the cluster.
+	// By default, the certificate authorities are self-signed.
+	// +optional
+	CertificateAuthorityIssuer *CertificateAuthorityIssuerSpec `json:"certificateAuthorityIssuer,omitempty"`
+
+	// CertificatesExpiryThreshold is how long before a certificate of the cluster expires the
+	// CertificatesExpiringSoon condition is set. Defaults to 30 days.
+	// +optional
+	CertificatesExpiryThreshold *metav1.Duration `json:"certificatesExpiryThreshold,omitempty"`
+
+	// K8sdConnection configures how the controllers reach k8sd on the machines of the cluster.
+	// By default, k8sd is reached through the workload cluster API server and the k8sd-proxy pods.
+	// +optional
+	K8sdConnection *K8sdConnectionSpec `json:"k8sdConnection,omitempty"`
+
+	// K8sdProxy configures the k8sd-proxy DaemonSet that the controllers maintain in the workload cluster.
+	// +optional
+	K8sdProxy *K8sdProxySpec `json:"k8sdProxy,omitempty"`
 }
 
 // MachineTemplate contains information about how machines should be shaped
@@ -248,6 +276,15 @@ type CK8sControlPlaneStatus struct {
 	// LastRemediation stores info about last remediation performed.
 	// +optional
 	LastRemediation *LastRemediationStatus `json:"lastRemediation,omitempty"`
+
+	// CertificateAuthorities are the certificate authorities of the cluster, as stored in the
+	// management cluster. They include the CA of an external datastore.
+	// +optional
+	CertificateAuthorities []bootstrapv1.CertificateStatus `json:"certificateAuthorities,omitempty"`
+
+	// Certificates is the inventory of the other certificates of the cluster, and of its machines.
+	// +optional
+	Certificates *CertificatesInventory `json:"certificates,omitempty"`
 }
 
 // LastRemediationStatus  stores info about last remediation performed.
@@ -60,6 +60,34 @@ type CK8sControlPlaneTemplateResourceSpec struct {
 	// +optional
 	// +kubebuilder:default={rollingUpdate: {maxSurge: 1}}
 	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// CertificatesRenewal configures the automatic renewal of the certificates of the control plane
+	// machines before they expire.
+	// +optional
+	CertificatesRenewal *bootstrapv1beta2.CertificatesRenewalPolicy `json:"certificatesRenewal,omitempty"`
+
+	// AdminKubeconfig configures the admin kubeconfig secret of the cluster.
+	// +optional
+	AdminKubeconfig *AdminKubeconfigSpec `json:"adminKubeconfig,omitempty"`
+
+	// CertificateAuthorityIssuer configures an external issuer for the certificate authorities of the cluster.
+	// By default, the certificate authorities are self-signed.
+	// +optional
+	CertificateAuthorityIssuer *CertificateAuthorityIssuerSpec `json:"certificateAuthorityIssuer,omitempty"`
+
+	// CertificatesExpiryThreshold is how long before a certificate of the cluster expires the
+	// CertificatesExpiringSoon condition is set. Defaults to 30 days.
+	// +optional
+	CertificatesExpiryThreshold *metav1.Duration `json:"certificatesExpiryThreshold,omitempty"`
+
+	// K8sdConnection configures how the controllers reach k8sd on the machines of the cluster.
+	// By default, k8sd is reached through the workload cluster API server and the k8sd-proxy pods.
+	// +optional
+	K8sdConnection *K8sdConnectionSpec `json:"k8sdConnection,omitempty"`
+
+	// K8sdProxy configures the k8sd-proxy DaemonSet that the controllers maintain in the workload cluster.
+	// +optional
+	K8sdProxy *K8sdProxySpec `json:"k8sdProxy,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,147 @@
+package v1beta2
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	"github.com/canonical/cluster-api-k8s/pkg/kubeconfig"
+)
+
+// CK8sKubeconfigSpec defines the desired state of CK8sKubeconfig.
+// +kubebuilder:validation:XValidation:rule="!has(self.renewBefore) || duration(self.renewBefore) < duration(self.validity)",message="renewBefore must be shorter than validity"
+type CK8sKubeconfigSpec struct {
+	// ClusterName is the name of the Cluster the kubeconfig is for, in the namespace of the CK8sKubeconfig.
+	// +kubebuilder:validation:MinLength=1
+	ClusterName string `json:"clusterName"`
+
+	// User is the user that the kubeconfig authenticates as.
+	// Users with the "system:" prefix are reserved by Kubernetes, and users with the "ck8s-capi-admin-" prefix
+	// are bound to cluster-admin for the admin kubeconfig of the cluster, so both are rejected.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="!self.startsWith('system:')",message="user must not have the system: prefix"
+	// +kubebuilder:validation:XValidation:rule="!self.startsWith('ck8s-capi-admin-')",message="user must not have the ck8s-capi-admin- prefix"
+	User string `json:"user"`
+
+	// Groups are the groups that the kubeconfig authenticates as.
+	// Groups with the "system:" prefix are reserved by Kubernetes and rejected. In particular, a member of
+	// system:masters bypasses RBAC, and its access cannot be revoked until the client certificate expires.
+	// Grant access by binding the user or the groups to Roles or ClusterRoles in the workload cluster instead,
+	// so that it can be revoked by deleting the bindings.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self.all(g, !g.startsWith('system:'))",message="groups must not have the system: prefix"
+	Groups []string `json:"groups,omitempty"`
+
+	// Validity is how long the client certificate of the kubeconfig is valid.
+	// +kubebuilder:validation:XValidation:rule="duration(self) > duration('0s')",message="validity must be positive"
+	Validity metav1.Duration `json:"validity"`
+
+	// RenewBefore is how long before its client certificate expires the kubeconfig is re-issued.
+	// Must be shorter than Validity. Defaults to a third of Validity.
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+
+	// SecretName is the name of the secret the kubeconfig is written to, in the namespace of the CK8sKubeconfig.
+	// Defaults to the name of the CK8sKubeconfig.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// GetRenewBefore returns how long before its client certificate expires the kubeconfig is re-issued.
+func (s *CK8sKubeconfigSpec) GetRenewBefore() time.Duration {
+	if s.RenewBefore == nil || s.RenewBefore.Duration <= 0 {
+		return s.Validity.Duration / 3
+	}
+	return s.RenewBefore.Duration
+}
+
+// Validate checks the CK8sKubeconfig spec. It duplicates the CRD validation rules, so that the
+// controller does not issue kubeconfigs for objects stored before the rules existed.
+func (s *CK8sKubeconfigSpec) Validate() error {
+	if strings.HasPrefix(s.User, "system:") {
+		return fmt.Errorf("user %q must not have the system: prefix", s.User)
+	}
+	if strings.HasPrefix(s.User, kubeconfig.ClusterAdminUserPrefix) {
+		return fmt.Errorf("user %q must not have the %s prefix", s.User, kubeconfig.ClusterAdminUserPrefix)
+	}
+	for _, group := range s.Groups {
+		if strings.HasPrefix(group, "system:") {
+			return fmt.Errorf("group %q must not have the system: prefix", group)
+		}
+	}
+	if s.Validity.Duration <= 0 {
+		return fmt.Errorf("validity must be positive")
+	}
+	if s.GetRenewBefore() >= s.Validity.Duration {
+		return fmt.Errorf("renewBefore %s must be shorter than validity %s", s.GetRenewBefore(), s.Validity.Duration)
+	}
+	return nil
+}
+
+// CK8sKubeconfigStatus defines the observed state of CK8sKubeconfig.
+type CK8sKubeconfigStatus struct {
+	// SecretName is the name of the secret the kubeconfig was written to.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// ExpiresAt is when the client certificate of the kubeconfig expires.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// ObservedGeneration is the latest generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions defines current service state of the CK8sKubeconfig.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=".spec.clusterName",description="Cluster the kubeconfig is for"
+// +kubebuilder:printcolumn:name="User",type=string,JSONPath=".spec.user",description="User the kubeconfig authenticates as"
+// +kubebuilder:printcolumn:name="Secret",type=string,JSONPath=".status.secretName",description="Secret the kubeconfig is written to"
+// +kubebuilder:printcolumn:name="Expires",type=string,format=date-time,JSONPath=".status.expiresAt",description="Time the client certificate of the kubeconfig expires"
+
+// CK8sKubeconfig is the Schema for the ck8skubeconfigs API.
+type CK8sKubeconfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CK8sKubeconfigSpec   `json:"spec,omitempty"`
+	Status CK8sKubeconfigStatus `json:"status,omitempty"`
+}
+
+func (in *CK8sKubeconfig) GetConditions() clusterv1.Conditions {
+	return in.Status.Conditions
+}
+
+func (in *CK8sKubeconfig) SetConditions(conditions clusterv1.Conditions) {
+	in.Status.Conditions = conditions
+}
+
+// GetSecretName returns the name of the secret the kubeconfig is written to.
+func (in *CK8sKubeconfig) GetSecretName() string {
+	if in.Spec.SecretName == "" {
+		return in.Name
+	}
+	return in.Spec.SecretName
+}
+
+// +kubebuilder:object:root=true
+
+// CK8sKubeconfigList contains a list of CK8sKubeconfig.
+type CK8sKubeconfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CK8sKubeconfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CK8sKubeconfig{}, &CK8sKubeconfigList{})
+}
@@ -34,6 +34,10 @@ const (
 	// an error while generating certificates; those kind of errors are usually temporary and the controller
 	// automatically recover from them.
 	CertificatesGenerationFailedReason = "CertificatesGenerationFailed"
+
+	// CertificatesIssuancePendingReason (Severity=Info) documents a CK8sControlPlane controller waiting for
+	// the certificate authorities of the cluster to be issued by an external issuer.
+	CertificatesIssuancePendingReason = "CertificatesIssuancePending"
 )
 
 const (
@@ -104,3 +108,59 @@ const (
 	// TokenGenerationFailedReason documents that the token required for nodes to join the cluster could not be generated.
 	TokenGenerationFailedReason = "TokenGenerationFailed"
 )
+
+const (
+	// KubeconfigAvailableCondition documents that the kubeconfig of a CK8sKubeconfig was written to its secret.
+	KubeconfigAvailableCondition clusterv1.ConditionType = "KubeconfigAvailable"
+
+	// WaitingForClusterReason (Severity=Info) documents a CK8sKubeconfig waiting for its cluster to have
+	// a control plane endpoint and certificates.
+	WaitingForClusterReason = "WaitingForCluster"
+
+	// KubeconfigGenerationFailedReason (Severity=Warning) documents a CK8sKubeconfig controller detecting
+	// an error while generating the kubeconfig.
+	KubeconfigGenerationFailedReason = "KubeconfigGenerationFailed"
+)
+
+const (
+	// CertificatesExpiringSoonCondition documents that a certificate of the cluster, or of one of its machines,
+	// expires within the certificates expiry threshold of the CK8sControlPlane.
+	CertificatesExpiringSoonCondition clusterv1.ConditionType = "CertificatesExpiringSoon"
+
+	// CertificatesExpiringReason documents a certificate of the cluster expiring within the threshold.
+	CertificatesExpiringReason = "CertificatesExpiring"
+
+	// CertificatesValidReason documents every known certificate of the cluster being valid beyond the threshold.
+	CertificatesValidReason = "CertificatesValid"
+)
+
+const (
+	// K8sdCertificateVerifiedCondition documents that k8sd on a control plane machine serves the certificate
+	// whose fingerprint was published by its node.
+	K8sdCertificateVerifiedCondition clusterv1.ConditionType = "K8sdCertificateVerified"
+
+	// K8sdCertificatesVerifiedCondition documents that k8sd serves the published certificate on all the
+	// control plane machines.
+	K8sdCertificatesVerifiedCondition clusterv1.ConditionType = "K8sdCertificatesVerified"
+
+	// K8sdCertificateMismatchReason (Severity=Error) documents k8sd serving a certificate that does not match
+	// the fingerprint published by its node. Connections to k8sd on the node are refused.
+	K8sdCertificateMismatchReason = "K8sdCertificateMismatch"
+
+	// K8sdCertificateNotPublishedReason (Severity=Warning) documents a node that did not publish the fingerprint
+	// of its k8sd certificate, e.g. because it joined the cluster before fingerprints were published, and whose
+	// certificate could not be recorded on first use. Connections to k8sd on the node are refused, unless the
+	// k8sd connection allows unverified certificates.
+	K8sdCertificateNotPublishedReason = "K8sdCertificateNotPublished"
+
+	// K8sdCertificateTrustedOnFirstUseReason (Severity=Info) documents a node that did not publish the fingerprint
+	// of its k8sd certificate, whose certificate was recorded on the first connection to k8sd instead.
+	// Connections to k8sd on the node are verified against the recorded certificate.
+	K8sdCertificateTrustedOnFirstUseReason = "K8sdCertificateTrustedOnFirstUse"
+
+	// K8sdCertificatesUnverifiedReason documents control plane machines whose k8sd certificate could not be verified.
+	K8sdCertificatesUnverifiedReason = "K8sdCertificatesUnverified"
+
+	// K8sdCertificateInspectionFailedReason documents a failure in connecting to k8sd to verify its certificate.
+	K8sdCertificateInspectionFailedReason = "K8sdCertificateInspectionFailed"
+)
@@ -0,0 +1,69 @@
+package v1beta2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// K8sdConnectionMode is how the controllers reach k8sd on the machines of the cluster.
+type K8sdConnectionMode string
+
+const (
+	// BastionCredentialsUsernameKey is the key of the username in the secret of the bastion credentials.
+	BastionCredentialsUsernameKey = "username"
+
+	// BastionCredentialsPasswordKey is the key of the password in the secret of the bastion credentials.
+	BastionCredentialsPasswordKey = "password"
+)
+
+const (
+	// K8sdConnectionModeProxy tunnels the connections through the port-forward of the workload cluster
+	// API server into the k8sd-proxy pods.
+	K8sdConnectionModeProxy K8sdConnectionMode = "Proxy"
+
+	// K8sdConnectionModeDirect dials the internal IP of the nodes and the microcluster port directly.
+	K8sdConnectionModeDirect K8sdConnectionMode = "Direct"
+)
+
+// K8sdConnectionSpec configures how the controllers reach k8sd on the machines of the cluster.
+// +kubebuilder:validation:XValidation:rule="!has(self.credentialsSecretRef) || has(self.bastion)",message="credentialsSecretRef requires a bastion"
+type K8sdConnectionSpec struct {
+	// Mode is how the controllers reach k8sd. Defaults to Proxy.
+	// Direct requires the management cluster to reach the internal IP of the nodes, or the bastion to.
+	// +optional
+	// +kubebuilder:validation:Enum=Proxy;Direct
+	Mode K8sdConnectionMode `json:"mode,omitempty"`
+
+	// Bastion is the URL of a SOCKS5 or HTTP CONNECT proxy to dial the nodes through in Direct mode,
+	// e.g. "socks5://bastion:1080" or "http://bastion:3128".
+	// The URL must not have credentials, set CredentialsSecretRef instead.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(socks5|http)://`
+	// +kubebuilder:validation:XValidation:rule="!self.matches('^[a-z0-9]+://[^/]*@')",message="bastion must not have credentials, use credentialsSecretRef instead"
+	Bastion string `json:"bastion,omitempty"`
+
+	// CredentialsSecretRef references a secret in the namespace of the control plane, whose "username" and
+	// "password" keys authenticate with the bastion.
+	// +optional
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+
+	// AllowUnverifiedCertificates allows connecting to k8sd on nodes that did not publish the fingerprint of their
+	// k8sd certificate, e.g. nodes that joined before fingerprints were published, without verifying the certificate.
+	// By default, the certificate served by k8sd on such nodes is recorded on the first connection, and the later
+	// connections are verified against it.
+	// +optional
+	AllowUnverifiedCertificates bool `json:"allowUnverifiedCertificates,omitempty"`
+}
+
+// GetMode returns how the controllers reach k8sd.
+func (s *K8sdConnectionSpec) GetMode() K8sdConnectionMode {
+	if s == nil || s.Mode == "" {
+		return K8sdConnectionModeProxy
+	}
+	return s.Mode
+}
+
+// GetAllowUnverifiedCertificates returns whether k8sd is reached on nodes that did not publish the fingerprint
+// of their k8sd certificate.
+func (s *K8sdConnectionSpec) GetAllowUnverifiedCertificates() bool {
+	return s != nil && s.AllowUnverifiedCertificates
+}
@@ -0,0 +1,30 @@
+package v1beta2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// K8sdProxySpec configures the k8sd-proxy DaemonSet forwarding the connections of the controllers to k8sd on the nodes.
+type K8sdProxySpec struct {
+	// Image is the image of the k8sd-proxy containers. Defaults to the socat image released with the provider.
+	// The image must provide /bin/sh and socat.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// ImageRegistry is the registry to pull the default k8sd-proxy image from instead of ghcr.io, e.g. a mirror.
+	// It is ignored if Image is set.
+	// +optional
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+
+	// Resources are the compute resources of the k8sd-proxy containers.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Tolerations are added to the default tolerations of the k8sd-proxy pods, that tolerate the control plane taints.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// PriorityClassName is the priority class of the k8sd-proxy pods.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+}
@@ -21,12 +21,39 @@ limitations under the License.
 package v1beta2
 
 import (
+	apiv1beta2 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdminKubeconfigSpec) DeepCopyInto(out *AdminKubeconfigSpec) {
+	*out = *in
+	if in.Validity != nil {
+		in, out := &in.Validity, &out.Validity
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdminKubeconfigSpec.
+func (in *AdminKubeconfigSpec) DeepCopy() *AdminKubeconfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AdminKubeconfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CK8sControlPlane) DeepCopyInto(out *CK8sControlPlane) {
 	*out = *in
@@ -142,6 +169,36 @@ func (in *CK8sControlPlaneSpec) DeepCopyInto(out *CK8sControlPlaneSpec) {
 		*out = new(RolloutStrategy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CertificatesRenewal != nil {
+		in, out := &in.CertificatesRenewal, &out.CertificatesRenewal
+		*out = new(apiv1beta2.CertificatesRenewalPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdminKubeconfig != nil {
+		in, out := &in.AdminKubeconfig, &out.AdminKubeconfig
+		*out = new(AdminKubeconfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertificateAuthorityIssuer != nil {
+		in, out := &in.CertificateAuthorityIssuer, &out.CertificateAuthorityIssuer
+		*out = new(CertificateAuthorityIssuerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertificatesExpiryThreshold != nil {
+		in, out := &in.CertificatesExpiryThreshold, &out.CertificatesExpiryThreshold
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.K8sdConnection != nil {
+		in, out := &in.K8sdConnection, &out.K8sdConnection
+		*out = new(K8sdConnectionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.K8sdProxy != nil {
+		in, out := &in.K8sdProxy, &out.K8sdProxy
+		*out = new(K8sdProxySpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CK8sControlPlaneSpec.
@@ -179,6 +236,18 @@ func (in *CK8sControlPlaneStatus) DeepCopyInto(out *CK8sControlPlaneStatus) {
 		*out = new(LastRemediationStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CertificateAuthorities != nil {
+		in, out := &in.CertificateAuthorities, &out.CertificateAuthorities
+		*out = make([]apiv1beta2.CertificateStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = new(CertificatesInventory)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CK8sControlPlaneStatus.
@@ -285,6 +354,36 @@ func (in *CK8sControlPlaneTemplateResourceSpec) DeepCopyInto(out *CK8sControlPla
 		*out = new(RolloutStrategy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CertificatesRenewal != nil {
+		in, out := &in.CertificatesRenewal, &out.CertificatesRenewal
+		*out = new(apiv1beta2.CertificatesRenewalPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdminKubeconfig != nil {
+		in, out := &in.AdminKubeconfig, &out.AdminKubeconfig
+		*out = new(AdminKubeconfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertificateAuthorityIssuer != nil {
+		in, out := &in.CertificateAuthorityIssuer, &out.CertificateAuthorityIssuer
+		*out = new(CertificateAuthorityIssuerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertificatesExpiryThreshold != nil {
+		in, out := &in.CertificatesExpiryThreshold, &out.CertificatesExpiryThreshold
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.K8sdConnection != nil {
+		in, out := &in.K8sdConnection, &out.K8sdConnection
+		*out = new(K8sdConnectionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.K8sdProxy != nil {
+		in, out := &in.K8sdProxy, &out.K8sdProxy
+		*out = new(K8sdProxySpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CK8sControlPlaneTemplateResourceSpec.
@@ -313,6 +412,235 @@ func (in *CK8sControlPlaneTemplateSpec) DeepCopy() *CK8sControlPlaneTemplateSpec
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CK8sKubeconfig) DeepCopyInto(out *CK8sKubeconfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CK8sKubeconfig.
+func (in *CK8sKubeconfig) DeepCopy() *CK8sKubeconfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CK8sKubeconfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CK8sKubeconfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CK8sKubeconfigList) DeepCopyInto(out *CK8sKubeconfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CK8sKubeconfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CK8sKubeconfigList.
+func (in *CK8sKubeconfigList) DeepCopy() *CK8sKubeconfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(CK8sKubeconfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CK8sKubeconfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CK8sKubeconfigSpec) DeepCopyInto(out *CK8sKubeconfigSpec) {
+	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Validity = in.Validity
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CK8sKubeconfigSpec.
+func (in *CK8sKubeconfigSpec) DeepCopy() *CK8sKubeconfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CK8sKubeconfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CK8sKubeconfigStatus) DeepCopyInto(out *CK8sKubeconfigStatus) {
+	*out = *in
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(v1beta1.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CK8sKubeconfigStatus.
+func (in *CK8sKubeconfigStatus) DeepCopy() *CK8sKubeconfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CK8sKubeconfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerIssuerSpec) DeepCopyInto(out *CertManagerIssuerSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerIssuerSpec.
+func (in *CertManagerIssuerSpec) DeepCopy() *CertManagerIssuerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerIssuerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateAuthorityIssuerSpec) DeepCopyInto(out *CertificateAuthorityIssuerSpec) {
+	*out = *in
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(CertManagerIssuerSpec)
+		**out = **in
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookIssuerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateAuthorityIssuerSpec.
+func (in *CertificateAuthorityIssuerSpec) DeepCopy() *CertificateAuthorityIssuerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateAuthorityIssuerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificatesInventory) DeepCopyInto(out *CertificatesInventory) {
+	*out = *in
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]apiv1beta2.CertificateStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EarliestMachineExpiry != nil {
+		in, out := &in.EarliestMachineExpiry, &out.EarliestMachineExpiry
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificatesInventory.
+func (in *CertificatesInventory) DeepCopy() *CertificatesInventory {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificatesInventory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K8sdConnectionSpec) DeepCopyInto(out *K8sdConnectionSpec) {
+	*out = *in
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K8sdConnectionSpec.
+func (in *K8sdConnectionSpec) DeepCopy() *K8sdConnectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(K8sdConnectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K8sdProxySpec) DeepCopyInto(out *K8sdProxySpec) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K8sdProxySpec.
+func (in *K8sdProxySpec) DeepCopy() *K8sdProxySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(K8sdProxySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LastRemediationStatus) DeepCopyInto(out *LastRemediationStatus) {
 	*out = *in
@@ -394,3 +722,28 @@ func (in *RolloutStrategy) DeepCopy() *RolloutStrategy {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookIssuerSpec) DeepCopyInto(out *WebhookIssuerSpec) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.TokenSecretRef != nil {
+		in, out := &in.TokenSecretRef, &out.TokenSecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookIssuerSpec.
+func (in *WebhookIssuerSpec) DeepCopy() *WebhookIssuerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookIssuerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
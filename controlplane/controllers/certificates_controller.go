@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/collections"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/certificates"
+	"github.com/canonical/cluster-api-k8s/pkg/ck8s"
+	"github.com/canonical/cluster-api-k8s/pkg/maintenance"
+	"github.com/canonical/cluster-api-k8s/pkg/trace"
+	"github.com/canonical/cluster-api-k8s/pkg/upgrade/inplace"
+)
+
+// ControlPlaneCertificatesReconciler reconciles a CK8sControlPlane object and orchestrates
+// the certificates refresh of the control plane machines.
+type ControlPlaneCertificatesReconciler struct {
+	scheme        *runtime.Scheme
+	recorder      record.EventRecorder
+	machineGetter inplace.MachineGetter
+	orchestrator  *certificates.Orchestrator
+
+	client.Client
+	Log logr.Logger
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ControlPlaneCertificatesReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.scheme = mgr.GetScheme()
+	r.recorder = mgr.GetEventRecorderFor("ck8s-cp-certificates-controller")
+	r.orchestrator = &certificates.Orchestrator{
+		Client:   r.Client,
+		Recorder: r.recorder,
+	}
+	r.machineGetter = &ck8s.Management{
+		Client: r.Client,
+	}
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&controlplanev1.CK8sControlPlane{}).
+		Owns(&clusterv1.Machine{}).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to get new controller builder: %w", err)
+	}
+
+	return nil
+}
+
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile handles the reconciliation of a CK8sControlPlane object.
+func (r *ControlPlaneCertificatesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	traceID := trace.NewID()
+	log := r.Log.WithValues("controlplane_certificates", req.NamespacedName, "trace_id", traceID)
+	log.V(1).Info("Reconciliation started...")
+
+	ck8sCP := &controlplanev1.CK8sControlPlane{}
+	if err := r.Get(ctx, req.NamespacedName, ck8sCP); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.V(1).Info("CK8sControlPlane resource not found. Ignoring since the object must be deleted.")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to get CK8sControlPlane: %w", err)
+	}
+
+	renewal := ck8sCP.Spec.CertificatesRenewal
+	if certificates.GetRefreshTTL(ck8sCP) == "" && renewal == nil {
+		log.V(1).Info("CK8sControlPlane has no certificates refresh request nor renewal policy, skipping reconciliation")
+		return ctrl.Result{}, nil
+	}
+
+	if isDeleted(ck8sCP) {
+		log.V(1).Info("CK8sControlPlane is being deleted, skipping reconciliation")
+		return ctrl.Result{}, nil
+	}
+
+	scope, err := r.createScope(ctx, ck8sCP)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to create scope: %w", err)
+	}
+
+	// NOTE: A requested refresh takes precedence over the renewal policy, it refreshes every machine anyway.
+	if certificates.GetRefreshTTL(ck8sCP) == "" {
+		return r.orchestrator.ReconcileRenewal(ctx, log, scope, renewal)
+	}
+
+	return r.orchestrator.ReconcileRefresh(ctx, log, scope)
+}
+
+// createScope creates the certificates refresh scope of the CK8sControlPlane.
+func (r *ControlPlaneCertificatesReconciler) createScope(ctx context.Context, ck8sCP *controlplanev1.CK8sControlPlane) (*certificates.Scope, error) {
+	patchHelper, err := patch.NewHelper(ck8sCP, r.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new patch helper: %w", err)
+	}
+
+	cluster, err := util.GetOwnerCluster(ctx, r.Client, ck8sCP.ObjectMeta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+	if cluster == nil {
+		return nil, fmt.Errorf("cluster controller has not yet set OwnerRef")
+	}
+
+	ownedMachines, err := r.machineGetter.GetMachinesForCluster(ctx, client.ObjectKeyFromObject(cluster), collections.OwnedMachines(ck8sCP))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owned machines: %w", err)
+	}
+
+	window, err := maintenance.ResolveWindow(ck8sCP, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance window: %w", err)
+	}
+
+	return &certificates.Scope{
+		Owner:   ck8sCP,
+		Patcher: patchHelper,
+		// NOTE: Sorting by creation timestamp gives a deterministic order across reconciliations.
+		Machines: ownedMachines.SortedByCreationTimestamp(),
+		Window:   window,
+	}, nil
+}
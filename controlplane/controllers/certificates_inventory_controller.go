@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/certificates"
+	"github.com/canonical/cluster-api-k8s/pkg/ck8s"
+	"github.com/canonical/cluster-api-k8s/pkg/trace"
+)
+
+// CertificatesInventoryReconciler reconciles a CK8sControlPlane object and records the expiry of the
+// certificates of its cluster and of its machines in its status.
+type CertificatesInventoryReconciler struct {
+	managementCluster ck8s.ManagementCluster
+
+	client.Client
+	Log logr.Logger
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CertificatesInventoryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.managementCluster = &ck8s.Management{
+		Client: r.Client,
+	}
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("certificates-inventory").
+		For(&controlplanev1.CK8sControlPlane{}).
+		Watches(&clusterv1.Machine{}, handler.EnqueueRequestsFromMapFunc(r.machineToCK8sControlPlane)).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to get new controller builder: %w", err)
+	}
+
+	return nil
+}
+
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+
+// Reconcile handles the reconciliation of a CK8sControlPlane object.
+func (r *CertificatesInventoryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	traceID := trace.NewID()
+	log := r.Log.WithValues("certificates_inventory", req.NamespacedName, "trace_id", traceID)
+	log.V(1).Info("Reconciliation started...")
+
+	kcp := &controlplanev1.CK8sControlPlane{}
+	if err := r.Get(ctx, req.NamespacedName, kcp); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.V(1).Info("CK8sControlPlane resource not found. Ignoring since the object must be deleted.")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to get CK8sControlPlane: %w", err)
+	}
+
+	if isDeleted(kcp) {
+		log.V(1).Info("CK8sControlPlane is being deleted, skipping reconciliation")
+		return ctrl.Result{}, nil
+	}
+
+	cluster, err := util.GetOwnerCluster(ctx, r.Client, kcp.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get owner cluster: %w", err)
+	}
+	if cluster == nil {
+		log.V(1).Info("Cluster Controller has not yet set OwnerRef")
+		return ctrl.Result{}, nil
+	}
+
+	machines, err := r.managementCluster.GetMachinesForCluster(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get machines of cluster: %w", err)
+	}
+
+	inventory, err := certificates.NewInventory(ctx, r.Client, util.ObjectKey(cluster), machines.UnsortedList())
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get certificates inventory: %w", err)
+	}
+
+	patchHelper, err := patch.NewHelper(kcp, r.Client)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to create patch helper: %w", err)
+	}
+
+	// NOTE: The inventory only changes with the certificates, so that patching it does not retrigger a reconciliation.
+	kcp.Status.Certificates = inventory
+	now := time.Now()
+	threshold := kcp.Spec.GetCertificatesExpiryThreshold()
+	requeueAfter := certificates.RenewalResyncPeriod
+
+	expiry, name, ok := certificates.EarliestExpiry(kcp.Status.CertificateAuthorities, inventory)
+	switch {
+	case !ok:
+		conditions.MarkFalse(kcp, controlplanev1.CertificatesExpiringSoonCondition, controlplanev1.CertificatesValidReason,
+			clusterv1.ConditionSeverityNone, "No certificate expiry known yet")
+	case now.Add(threshold).After(expiry):
+		conditions.Set(kcp, &clusterv1.Condition{
+			Type:    controlplanev1.CertificatesExpiringSoonCondition,
+			Status:  corev1.ConditionTrue,
+			Reason:  controlplanev1.CertificatesExpiringReason,
+			Message: fmt.Sprintf("Certificates of %s expire at %s", name, expiry.Format(time.RFC3339)),
+		})
+	default:
+		conditions.MarkFalse(kcp, controlplanev1.CertificatesExpiringSoonCondition, controlplanev1.CertificatesValidReason,
+			clusterv1.ConditionSeverityNone, "Certificates of %s expire first, at %s", name, expiry.Format(time.RFC3339))
+		// NOTE: Re-evaluate the condition as soon as the earliest certificate enters the threshold.
+		requeueAfter = min(requeueAfter, max(expiry.Add(-threshold).Sub(now), time.Second))
+	}
+
+	if err := patchHelper.Patch(ctx, kcp, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+		controlplanev1.CertificatesExpiringSoonCondition,
+	}}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to patch CK8sControlPlane: %w", err)
+	}
+
+	log.V(1).Info("Updated certificates inventory", "earliest", name, "expiry", expiry)
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// machineToCK8sControlPlane enqueues the CK8sControlPlane of the cluster of a machine, so that the
+// certificates expiry of worker machines is picked up too.
+func (r *CertificatesInventoryReconciler) machineToCK8sControlPlane(ctx context.Context, o client.Object) []ctrl.Request {
+	m, ok := o.(*clusterv1.Machine)
+	if !ok {
+		panic(fmt.Sprintf("Expected a Machine but got a %T", o))
+	}
+
+	cluster, err := util.GetClusterByName(ctx, r.Client, m.Namespace, m.Spec.ClusterName)
+	if err != nil {
+		return nil
+	}
+
+	controlPlaneRef := cluster.Spec.ControlPlaneRef
+	if controlPlaneRef != nil && controlPlaneRef.Kind == "CK8sControlPlane" {
+		return []ctrl.Request{{NamespacedName: client.ObjectKey{Namespace: controlPlaneRef.Namespace, Name: controlPlaneRef.Name}}}
+	}
+
+	return nil
+}
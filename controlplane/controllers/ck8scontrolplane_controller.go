@@ -73,6 +73,7 @@ type CK8sControlPlaneReconciler struct {
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificaterequests,verbs=get;create;delete
 
 func (r *CK8sControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := r.Log.WithValues("namespace", req.Namespace, "ck8sControlPlane", req.Name)
@@ -437,6 +438,61 @@ func (r *CK8sControlPlaneReconciler) updateStatus(ctx context.Context, kcp *cont
 	return nil
 }
 
+// lookupOrIssueCertificates looks up the certificates of the cluster, and issues the missing certificate
+// authorities with the issuer of the CK8sControlPlane, if any. Certificate authorities are only issued
+// before the control plane is initialized, the missing certificates are generated otherwise.
+func (r *CK8sControlPlaneReconciler) lookupOrIssueCertificates(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.CK8sControlPlane, certificates secret.Certificates, owner metav1.OwnerReference) error {
+	if kcp.Spec.CertificateAuthorityIssuer == nil || kcp.Status.Initialized {
+		return certificates.LookupOrGenerate(ctx, r.Client, util.ObjectKey(cluster), owner)
+	}
+
+	issuer, err := r.newCertificateAuthorityIssuer(ctx, kcp)
+	if err != nil {
+		return fmt.Errorf("invalid certificate authority issuer: %w", err)
+	}
+	return certificates.LookupOrIssue(ctx, r.Client, util.ObjectKey(cluster), owner, issuer)
+}
+
+// newCertificateAuthorityIssuer returns the issuer of the certificate authorities configured on the CK8sControlPlane.
+func (r *CK8sControlPlaneReconciler) newCertificateAuthorityIssuer(ctx context.Context, kcp *controlplanev1.CK8sControlPlane) (secret.Issuer, error) {
+	spec := kcp.Spec.CertificateAuthorityIssuer
+
+	switch {
+	case spec.CertManager != nil && spec.Webhook != nil:
+		return nil, errors.New("only one of certManager and webhook can be set")
+	case spec.CertManager != nil:
+		issuer := &secret.CertManagerIssuer{
+			IssuerName:  spec.CertManager.Name,
+			IssuerKind:  spec.CertManager.Kind,
+			IssuerGroup: spec.CertManager.Group,
+			Duration:    spec.GetDuration(),
+		}
+		if issuer.IssuerKind == "" {
+			issuer.IssuerKind = "Issuer"
+		}
+		if issuer.IssuerGroup == "" {
+			issuer.IssuerGroup = secret.CertManagerGroup
+		}
+		return issuer, nil
+	case spec.Webhook != nil:
+		issuer := &secret.WebhookIssuer{
+			URL:      spec.Webhook.URL,
+			CABundle: spec.Webhook.CABundle,
+			Duration: spec.GetDuration(),
+		}
+		if ref := spec.Webhook.TokenSecretRef; ref != nil {
+			tokenSecret := &corev1.Secret{}
+			if err := r.Get(ctx, client.ObjectKey{Namespace: kcp.Namespace, Name: ref.Name}, tokenSecret); err != nil {
+				return nil, fmt.Errorf("failed to get token secret %s: %w", ref.Name, err)
+			}
+			issuer.Token = string(tokenSecret.Data["token"])
+		}
+		return issuer, nil
+	default:
+		return nil, errors.New("one of certManager and webhook must be set")
+	}
+}
+
 // reconcile handles CK8sControlPlane reconciliation.
 func (r *CK8sControlPlaneReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.CK8sControlPlane) (ctrl.Result, error) {
 	logger := r.Log.WithValues("namespace", kcp.Namespace, "CK8sControlPlane", kcp.Name, "cluster", cluster.Name)
@@ -448,14 +504,31 @@ func (r *CK8sControlPlaneReconciler) reconcile(ctx context.Context, cluster *clu
 	}
 
 	certificates := secret.NewCertificatesForInitialControlPlane(&kcp.Spec.CK8sConfigSpec)
+	// NOTE: k8sd generated the service account key of the clusters initialized before the key was part of
+	// the certificates. A key generated now would not be the one the cluster uses, so it is only looked up.
+	if kcp.Status.Initialized {
+		certificates.GetByPurpose(secret.ServiceAccount).Optional = true
+	}
 	controllerRef := metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("CK8sControlPlane"))
-	if err := certificates.LookupOrGenerate(ctx, r.Client, util.ObjectKey(cluster), *controllerRef); err != nil {
+	if err := r.lookupOrIssueCertificates(ctx, cluster, kcp, certificates, *controllerRef); err != nil {
+		if errors.Is(err, secret.ErrIssuancePending) {
+			logger.Info("Waiting for the certificate authorities to be issued")
+			conditions.MarkFalse(kcp, controlplanev1.CertificatesAvailableCondition, controlplanev1.CertificatesIssuancePendingReason, clusterv1.ConditionSeverityInfo, "Waiting for the certificate authorities to be issued")
+			return reconcile.Result{RequeueAfter: 5 * time.Second}, nil
+		}
 		logger.Error(err, "unable to lookup or create cluster certificates")
 		conditions.MarkFalse(kcp, controlplanev1.CertificatesAvailableCondition, controlplanev1.CertificatesGenerationFailedReason, clusterv1.ConditionSeverityWarning, "%s", err.Error())
 		return reconcile.Result{}, err
 	}
 	conditions.MarkTrue(kcp, controlplanev1.CertificatesAvailableCondition)
 
+	certificateAuthorities, err := certificates.CertificateAuthoritiesStatus(util.ObjectKey(cluster))
+	if err != nil {
+		logger.Error(err, "unable to read the certificate authorities of the cluster")
+	} else {
+		kcp.Status.CertificateAuthorities = certificateAuthorities
+	}
+
 	if err := token.Reconcile(ctx, r.Client, client.ObjectKeyFromObject(cluster), kcp); err != nil {
 		conditions.MarkFalse(kcp, controlplanev1.TokenAvailableCondition, controlplanev1.TokenGenerationFailedReason, clusterv1.ConditionSeverityWarning, "%s", err.Error())
 		return reconcile.Result{}, err
@@ -469,9 +542,10 @@ func (r *CK8sControlPlaneReconciler) reconcile(ctx context.Context, cluster *clu
 	}
 
 	// Generate Cluster Kubeconfig if needed
-	if result, err := r.reconcileKubeconfig(ctx, util.ObjectKey(cluster), cluster.Spec.ControlPlaneEndpoint, kcp); err != nil {
+	kubeconfigResult, err := r.reconcileKubeconfig(ctx, util.ObjectKey(cluster), cluster.Spec.ControlPlaneEndpoint, kcp)
+	if err != nil {
 		logger.Error(err, "failed to reconcile Kubeconfig")
-		return result, err
+		return kubeconfigResult, err
 	}
 
 	controlPlaneMachines, err := r.managementClusterUncached.GetMachinesForCluster(ctx, util.ObjectKey(cluster), collections.ControlPlaneMachines(cluster.Name))
@@ -558,7 +632,8 @@ func (r *CK8sControlPlaneReconciler) reconcile(ctx context.Context, cluster *clu
 		return r.scaleDownControlPlane(ctx, cluster, kcp, controlPlane, collections.Machines{})
 	}
 
-	return reconcile.Result{}, nil
+	// Requeue to renew the kubeconfig in time.
+	return kubeconfigResult, nil
 }
 
 func (r *CK8sControlPlaneReconciler) reconcileExternalReference(ctx context.Context, cluster *clusterv1.Cluster, ref corev1.ObjectReference) error {
@@ -593,22 +668,45 @@ func (r *CK8sControlPlaneReconciler) reconcileKubeconfig(ctx context.Context, cl
 		return reconcile.Result{}, nil
 	}
 
+	spec := kcp.Spec.AdminKubeconfig
+	opts := kubeconfig.Options{
+		Identity: kubeconfig.SystemMastersIdentity,
+		Validity: spec.GetValidity(),
+	}
+	// Users can only be bound to cluster-admin once the workload cluster is reachable.
+	clusterAdmin := spec.GetIdentity() == controlplanev1.AdminKubeconfigIdentityClusterAdmin && kcp.Status.Initialized
+
 	controllerOwnerRef := *metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("CK8sControlPlane"))
 	configSecret, err := secret.GetFromNamespacedName(ctx, r.Client, clusterName, secret.Kubeconfig)
 	switch {
 	case apierrors.IsNotFound(err):
+		if clusterAdmin {
+			workloadClient, err := r.getWorkloadAdminClient(ctx, clusterName, endpoint)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			opts.Identity = kubeconfig.NewClusterAdminIdentity(time.Now())
+			if err := kubeconfig.EnsureClusterAdminBinding(ctx, workloadClient, opts.Identity.User); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+
 		createErr := kubeconfig.CreateSecretWithOwner(
 			ctx,
 			r.Client,
 			clusterName,
 			endpoint.String(),
 			controllerOwnerRef,
+			opts,
 		)
 		if errors.Is(createErr, kubeconfig.ErrDependentCertificateNotFound) {
 			return ctrl.Result{RequeueAfter: dependentCertRequeueAfter}, nil
 		}
+		if createErr != nil {
+			return reconcile.Result{}, createErr
+		}
 		// always return if we have just created in order to skip rotation checks
-		return reconcile.Result{}, createErr
+		return reconcile.Result{RequeueAfter: opts.Validity - spec.GetRenewBefore()}, nil
 
 	case err != nil:
 		return reconcile.Result{}, fmt.Errorf("failed to retrieve kubeconfig Secret: %w", err)
@@ -619,22 +717,69 @@ func (r *CK8sControlPlaneReconciler) reconcileKubeconfig(ctx context.Context, cl
 		return reconcile.Result{}, nil
 	}
 
-	/**
-	// TODO rotation
-	needsRotation, err := kubeconfig.NeedsClientCertRotation(configSecret, certs.ClientCertificateRenewalDuration)
+	cert, err := kubeconfig.GetClientCertificate(configSecret)
 	if err != nil {
-		return err
+		return reconcile.Result{}, fmt.Errorf("failed to get kubeconfig client certificate: %w", err)
+	}
+
+	wasClusterAdmin := kubeconfig.IsClusterAdminIdentity(cert)
+	if renewAfter := time.Until(cert.NotAfter) - spec.GetRenewBefore(); renewAfter > 0 && wasClusterAdmin == clusterAdmin {
+		return reconcile.Result{RequeueAfter: renewAfter}, nil
 	}
 
-	if needsRotation {
-		r.Log.Info("rotating kubeconfig secret")
-		if err := kubeconfig.RegenerateSecret(ctx, r.Client, configSecret); err != nil {
-			return fmt.Errorf("failed to regenerate kubeconfig")
+	var workloadClient client.Client
+	if clusterAdmin || wasClusterAdmin {
+		if workloadClient, err = r.getWorkloadAdminClient(ctx, clusterName, endpoint); err != nil {
+			return reconcile.Result{}, err
 		}
 	}
-	**/
 
-	return reconcile.Result{}, nil
+	if clusterAdmin {
+		opts.Identity = kubeconfig.NewClusterAdminIdentity(time.Now())
+		if err := kubeconfig.EnsureClusterAdminBinding(ctx, workloadClient, opts.Identity.User); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	r.Log.Info("rotating kubeconfig secret", "expiry", cert.NotAfter, "user", opts.Identity.User)
+	if err := kubeconfig.RegenerateSecret(ctx, r.Client, configSecret, clusterName, endpoint.String(), opts); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to regenerate kubeconfig: %w", err)
+	}
+	r.recorder.Eventf(kcp, corev1.EventTypeNormal, "KubeconfigRotated",
+		"Rotated kubeconfig for cluster %s/%s, authenticating as %s", clusterName.Namespace, clusterName.Name, opts.Identity.User)
+
+	if workloadClient != nil {
+		// Keep the user of the previous kubeconfig bound until the next rotation, so that clients
+		// still using it are not cut off.
+		var keep []string
+		if clusterAdmin {
+			keep = append(keep, opts.Identity.User)
+		}
+		if wasClusterAdmin {
+			keep = append(keep, cert.Subject.CommonName)
+		}
+		if err := kubeconfig.RevokeClusterAdminBindings(ctx, workloadClient, keep...); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	return reconcile.Result{RequeueAfter: opts.Validity - spec.GetRenewBefore()}, nil
+}
+
+// getWorkloadAdminClient returns a client for the workload cluster that authenticates as a member of system:masters,
+// independently of the kubeconfig secret.
+func (r *CK8sControlPlaneReconciler) getWorkloadAdminClient(ctx context.Context, clusterName client.ObjectKey, endpoint clusterv1.APIEndpoint) (client.Client, error) {
+	restConfig, err := kubeconfig.NewAdminRESTConfig(ctx, r.Client, clusterName, endpoint.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workload cluster REST config: %w", err)
+	}
+	restConfig.Timeout = 30 * time.Second
+
+	c, err := client.New(restConfig, client.Options{Scheme: r.Client.Scheme()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workload cluster client: %w", err)
+	}
+	return c, nil
 }
 
 // reconcileControlPlaneConditions is responsible of reconciling conditions reporting the status of static pods.
@@ -653,6 +798,7 @@ func (r *CK8sControlPlaneReconciler) reconcileControlPlaneConditions(ctx context
 
 	// Update conditions status
 	workloadCluster.UpdateAgentConditions(ctx, controlPlane)
+	workloadCluster.UpdateK8sdCertificateConditions(ctx, controlPlane)
 
 	// Patch machines with the updated conditions.
 	if err := controlPlane.PatchMachines(ctx); err != nil {
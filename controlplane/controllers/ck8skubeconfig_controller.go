@@ -0,0 +1,285 @@
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/kubeconfig"
+	"github.com/canonical/cluster-api-k8s/pkg/secret"
+	"github.com/canonical/cluster-api-k8s/pkg/trace"
+)
+
+// minKubeconfigRenewalRequeueAfter is the minimum time between two renewals of a kubeconfig, so that
+// a kubeconfig that is due for renewal as soon as it is issued is not re-issued in a tight loop.
+const minKubeconfigRenewalRequeueAfter = time.Minute
+
+// CK8sKubeconfigReconciler reconciles a CK8sKubeconfig object and writes the kubeconfig
+// it describes to its secret, renewing it before its client certificate expires.
+type CK8sKubeconfigReconciler struct {
+	recorder record.EventRecorder
+
+	client.Client
+	Log logr.Logger
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CK8sKubeconfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("ck8s-kubeconfig-controller")
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&controlplanev1.CK8sKubeconfig{}).
+		Owns(&corev1.Secret{}).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to get new controller builder: %w", err)
+	}
+
+	return nil
+}
+
+// +kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=ck8skubeconfigs,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=ck8skubeconfigs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile handles the reconciliation of a CK8sKubeconfig object.
+func (r *CK8sKubeconfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
+	traceID := trace.NewID()
+	log := r.Log.WithValues("ck8skubeconfig", req.NamespacedName, "trace_id", traceID)
+	log.V(1).Info("Reconciliation started...")
+
+	kc := &controlplanev1.CK8sKubeconfig{}
+	if err := r.Get(ctx, req.NamespacedName, kc); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.V(1).Info("CK8sKubeconfig resource not found. Ignoring since the object must be deleted.")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to get CK8sKubeconfig: %w", err)
+	}
+
+	if isDeleted(kc) {
+		// NOTE: The secret is owned by the CK8sKubeconfig and is garbage collected with it.
+		log.V(1).Info("CK8sKubeconfig is being deleted, skipping reconciliation")
+		return ctrl.Result{}, nil
+	}
+
+	patchHelper, err := patch.NewHelper(kc, r.Client)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to create patch helper: %w", err)
+	}
+	defer func() {
+		kc.Status.ObservedGeneration = kc.Generation
+		if err := patchHelper.Patch(ctx, kc, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+			controlplanev1.KubeconfigAvailableCondition,
+		}}); err != nil && rerr == nil {
+			rerr = fmt.Errorf("failed to patch CK8sKubeconfig: %w", err)
+		}
+	}()
+
+	if err := kc.Spec.Validate(); err != nil {
+		conditions.MarkFalse(kc, controlplanev1.KubeconfigAvailableCondition, controlplanev1.KubeconfigGenerationFailedReason,
+			clusterv1.ConditionSeverityWarning, "invalid spec: %s", err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	cluster, err := util.GetClusterByName(ctx, r.Client, kc.Namespace, kc.Spec.ClusterName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			conditions.MarkFalse(kc, controlplanev1.KubeconfigAvailableCondition, controlplanev1.WaitingForClusterReason,
+				clusterv1.ConditionSeverityInfo, "cluster %s not found", kc.Spec.ClusterName)
+			return ctrl.Result{RequeueAfter: dependentCertRequeueAfter}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to get cluster: %w", err)
+	}
+
+	if cluster.Spec.ControlPlaneEndpoint.IsZero() {
+		log.V(1).Info("Cluster does not yet have a ControlPlaneEndpoint defined")
+		conditions.MarkFalse(kc, controlplanev1.KubeconfigAvailableCondition, controlplanev1.WaitingForClusterReason,
+			clusterv1.ConditionSeverityInfo, "cluster %s has no control plane endpoint", cluster.Name)
+		return ctrl.Result{RequeueAfter: dependentCertRequeueAfter}, nil
+	}
+
+	configSecret, err := r.getSecret(ctx, kc)
+	if err != nil {
+		conditions.MarkFalse(kc, controlplanev1.KubeconfigAvailableCondition, controlplanev1.KubeconfigGenerationFailedReason,
+			clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	now := time.Now()
+	if configSecret != nil {
+		cert, err := kubeconfig.GetClientCertificate(configSecret)
+		if err != nil {
+			log.Info("Failed to read the client certificate of the kubeconfig, regenerating it", "error", err)
+		} else if !kubeconfigNeedsRenewal(kc, cert, now) {
+			return r.markKubeconfigAvailable(kc, cert, now), nil
+		}
+	}
+
+	opts := kubeconfig.Options{
+		Identity: kubeconfig.Identity{
+			User:   kc.Spec.User,
+			Groups: kc.Spec.Groups,
+		},
+		Validity: kc.Spec.Validity.Duration,
+	}
+	data, err := kubeconfig.Generate(ctx, r.Client, util.ObjectKey(cluster), cluster.Spec.ControlPlaneEndpoint.String(), opts)
+	if err != nil {
+		if errors.Is(err, kubeconfig.ErrDependentCertificateNotFound) {
+			log.V(1).Info("Cluster certificates not found, requeueing")
+			conditions.MarkFalse(kc, controlplanev1.KubeconfigAvailableCondition, controlplanev1.WaitingForClusterReason,
+				clusterv1.ConditionSeverityInfo, "certificates of cluster %s not found", cluster.Name)
+			return ctrl.Result{RequeueAfter: dependentCertRequeueAfter}, nil
+		}
+		conditions.MarkFalse(kc, controlplanev1.KubeconfigAvailableCondition, controlplanev1.KubeconfigGenerationFailedReason,
+			clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, fmt.Errorf("failed to generate kubeconfig: %w", err)
+	}
+
+	if configSecret, err = r.writeSecret(ctx, kc, cluster, configSecret, data); err != nil {
+		conditions.MarkFalse(kc, controlplanev1.KubeconfigAvailableCondition, controlplanev1.KubeconfigGenerationFailedReason,
+			clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	if err := r.deletePreviousSecret(ctx, kc); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	cert, err := kubeconfig.GetClientCertificate(configSecret)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get client certificate of kubeconfig: %w", err)
+	}
+
+	log.Info("Issued kubeconfig", "secret", configSecret.Name, "user", kc.Spec.User, "expiry", cert.NotAfter)
+	r.recorder.Eventf(kc, corev1.EventTypeNormal, "KubeconfigIssued",
+		"Issued kubeconfig for user %s in secret %s, valid until %s", kc.Spec.User, configSecret.Name, cert.NotAfter.Format(time.RFC3339))
+
+	return r.markKubeconfigAvailable(kc, cert, now), nil
+}
+
+// getSecret returns the secret of the CK8sKubeconfig, or nil if it does not exist yet.
+// It fails if the secret exists but is not controlled by the CK8sKubeconfig.
+func (r *CK8sKubeconfigReconciler) getSecret(ctx context.Context, kc *controlplanev1.CK8sKubeconfig) (*corev1.Secret, error) {
+	configSecret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: kc.Namespace, Name: kc.GetSecretName()}, configSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get secret %s: %w", kc.GetSecretName(), err)
+	}
+
+	if !metav1.IsControlledBy(configSecret, kc) {
+		return nil, fmt.Errorf("secret %s already exists and is not controlled by the CK8sKubeconfig", configSecret.Name)
+	}
+	return configSecret, nil
+}
+
+// writeSecret creates or updates the secret of the CK8sKubeconfig with the kubeconfig data.
+func (r *CK8sKubeconfigReconciler) writeSecret(ctx context.Context, kc *controlplanev1.CK8sKubeconfig, cluster *clusterv1.Cluster, configSecret *corev1.Secret, data []byte) (*corev1.Secret, error) {
+	if configSecret != nil {
+		configSecret.Data = map[string][]byte{
+			secret.KubeconfigDataName: data,
+		}
+		if err := r.Update(ctx, configSecret); err != nil {
+			return nil, fmt.Errorf("failed to update secret %s: %w", configSecret.Name, err)
+		}
+		return configSecret, nil
+	}
+
+	configSecret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kc.GetSecretName(),
+			Namespace: kc.Namespace,
+			Labels: map[string]string{
+				clusterv1.ClusterNameLabel: cluster.Name,
+			},
+		},
+		Data: map[string][]byte{
+			secret.KubeconfigDataName: data,
+		},
+	}
+	if err := controllerutil.SetControllerReference(kc, configSecret, r.Scheme()); err != nil {
+		return nil, fmt.Errorf("failed to set owner of secret %s: %w", configSecret.Name, err)
+	}
+	if err := r.Create(ctx, configSecret); err != nil {
+		return nil, fmt.Errorf("failed to create secret %s: %w", configSecret.Name, err)
+	}
+	return configSecret, nil
+}
+
+// deletePreviousSecret deletes the secret the kubeconfig was written to before the secret name of the
+// CK8sKubeconfig changed.
+func (r *CK8sKubeconfigReconciler) deletePreviousSecret(ctx context.Context, kc *controlplanev1.CK8sKubeconfig) error {
+	if kc.Status.SecretName == "" || kc.Status.SecretName == kc.GetSecretName() {
+		return nil
+	}
+
+	previous := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: kc.Namespace, Name: kc.Status.SecretName}, previous); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get previous secret %s: %w", kc.Status.SecretName, err)
+	}
+
+	if !metav1.IsControlledBy(previous, kc) {
+		return nil
+	}
+	if err := r.Delete(ctx, previous); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete previous secret %s: %w", previous.Name, err)
+	}
+	return nil
+}
+
+// markKubeconfigAvailable records the kubeconfig in the status of the CK8sKubeconfig, and returns
+// a result that requeues the CK8sKubeconfig when the kubeconfig needs to be renewed.
+func (r *CK8sKubeconfigReconciler) markKubeconfigAvailable(kc *controlplanev1.CK8sKubeconfig, cert *x509.Certificate, now time.Time) ctrl.Result {
+	expiresAt := metav1.NewTime(cert.NotAfter)
+	kc.Status.SecretName = kc.GetSecretName()
+	kc.Status.ExpiresAt = &expiresAt
+	conditions.MarkTrue(kc, controlplanev1.KubeconfigAvailableCondition)
+
+	renewAt := cert.NotAfter.Add(-kc.Spec.GetRenewBefore())
+	return ctrl.Result{RequeueAfter: max(renewAt.Sub(now), minKubeconfigRenewalRequeueAfter)}
+}
+
+// kubeconfigNeedsRenewal checks if the client certificate of the kubeconfig is due for renewal,
+// or no longer matches the identity and validity of the CK8sKubeconfig.
+func kubeconfigNeedsRenewal(kc *controlplanev1.CK8sKubeconfig, cert *x509.Certificate, now time.Time) bool {
+	if cert.Subject.CommonName != kc.Spec.User {
+		return true
+	}
+
+	groups := slices.Clone(kc.Spec.Groups)
+	certGroups := slices.Clone(cert.Subject.Organization)
+	slices.Sort(groups)
+	slices.Sort(certGroups)
+	if !slices.Equal(groups, certGroups) {
+		return true
+	}
+
+	// NOTE: A shortened validity applies immediately rather than on the next renewal.
+	if cert.NotAfter.After(now.Add(kc.Spec.Validity.Duration)) {
+		return true
+	}
+
+	return !now.Before(cert.NotAfter.Add(-kc.Spec.GetRenewBefore()))
+}
@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/kubeconfig"
+	"github.com/canonical/cluster-api-k8s/pkg/secret"
+)
+
+// newKubeconfigReconciler returns a reconciler for the CK8sKubeconfig, with a cluster that has a control plane
+// endpoint and certificates.
+func newKubeconfigReconciler(g *WithT, kc *controlplanev1.CK8sKubeconfig) *CK8sKubeconfigReconciler {
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: kc.Namespace, Name: kc.Spec.ClusterName},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "10.0.0.1", Port: 6443},
+		},
+	}
+
+	certificates := secret.Certificates{
+		&secret.Certificate{Purpose: secret.ClusterCA},
+		&secret.Certificate{Purpose: secret.ClientClusterCA},
+	}
+	g.Expect(certificates.Generate()).To(Succeed())
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(kc).WithObjects(
+		kc,
+		cluster,
+		certificates[0].AsSecret(util.ObjectKey(cluster), metav1.OwnerReference{}),
+		certificates[1].AsSecret(util.ObjectKey(cluster), metav1.OwnerReference{}),
+	).Build()
+
+	return &CK8sKubeconfigReconciler{Client: c, Log: logr.Discard(), recorder: record.NewFakeRecorder(10)}
+}
+
+func newCK8sKubeconfig() *controlplanev1.CK8sKubeconfig {
+	return &controlplanev1.CK8sKubeconfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ci"},
+		Spec: controlplanev1.CK8sKubeconfigSpec{
+			ClusterName: "cluster",
+			User:        "ci",
+			Groups:      []string{"viewers"},
+			Validity:    metav1.Duration{Duration: 24 * time.Hour},
+		},
+	}
+}
+
+func TestCK8sKubeconfigReconcile(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("issues the kubeconfig", func(t *testing.T) {
+		g := NewWithT(t)
+		kc := newCK8sKubeconfig()
+		r := newKubeconfigReconciler(g, kc)
+
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kc)})
+		g.Expect(err).ToNot(HaveOccurred())
+		// The kubeconfig is renewed a third of its validity before it expires.
+		g.Expect(result.RequeueAfter).To(BeNumerically("~", 16*time.Hour, time.Minute))
+
+		configSecret := &corev1.Secret{}
+		g.Expect(r.Get(ctx, client.ObjectKeyFromObject(kc), configSecret)).To(Succeed())
+		g.Expect(metav1.IsControlledBy(configSecret, kc)).To(BeTrue())
+
+		cert, err := kubeconfig.GetClientCertificate(configSecret)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(cert.Subject.CommonName).To(Equal("ci"))
+		g.Expect(cert.Subject.Organization).To(Equal([]string{"viewers"}))
+
+		g.Expect(r.Get(ctx, client.ObjectKeyFromObject(kc), kc)).To(Succeed())
+		g.Expect(conditions.IsTrue(kc, controlplanev1.KubeconfigAvailableCondition)).To(BeTrue())
+		g.Expect(kc.Status.SecretName).To(Equal("ci"))
+		g.Expect(kc.Status.ExpiresAt.Time).To(BeTemporally("~", cert.NotAfter, time.Second))
+
+		// The kubeconfig is not re-issued until it is due for renewal.
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kc)})
+		g.Expect(err).ToNot(HaveOccurred())
+		reconciled := &corev1.Secret{}
+		g.Expect(r.Get(ctx, client.ObjectKeyFromObject(kc), reconciled)).To(Succeed())
+		g.Expect(reconciled.Data).To(Equal(configSecret.Data))
+	})
+
+	t.Run("moves the kubeconfig to a new secret", func(t *testing.T) {
+		g := NewWithT(t)
+		kc := newCK8sKubeconfig()
+		r := newKubeconfigReconciler(g, kc)
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kc)})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(r.Get(ctx, client.ObjectKeyFromObject(kc), kc)).To(Succeed())
+		kc.Spec.SecretName = "ci-kubeconfig"
+		g.Expect(r.Update(ctx, kc)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kc)})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "ci-kubeconfig"}, &corev1.Secret{})).To(Succeed())
+		err = r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "ci"}, &corev1.Secret{})
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("leaves the secret to the garbage collector on deletion", func(t *testing.T) {
+		g := NewWithT(t)
+		kc := newCK8sKubeconfig()
+		r := newKubeconfigReconciler(g, kc)
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kc)})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(r.Get(ctx, client.ObjectKeyFromObject(kc), kc)).To(Succeed())
+		g.Expect(r.Delete(ctx, kc)).To(Succeed())
+
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kc)})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(result).To(Equal(ctrl.Result{}))
+
+		configSecret := &corev1.Secret{}
+		g.Expect(r.Get(ctx, client.ObjectKeyFromObject(kc), configSecret)).To(Succeed())
+		g.Expect(metav1.IsControlledBy(configSecret, kc)).To(BeTrue())
+	})
+
+	for _, tc := range []struct {
+		name   string
+		mutate func(kc *controlplanev1.CK8sKubeconfig)
+	}{
+		{
+			name:   "system:masters group",
+			mutate: func(kc *controlplanev1.CK8sKubeconfig) { kc.Spec.Groups = []string{"viewers", "system:masters"} },
+		},
+		{
+			name:   "system: group",
+			mutate: func(kc *controlplanev1.CK8sKubeconfig) { kc.Spec.Groups = []string{"system:nodes"} },
+		},
+		{
+			name:   "system: user",
+			mutate: func(kc *controlplanev1.CK8sKubeconfig) { kc.Spec.User = "system:kube-controller-manager" },
+		},
+		{
+			name:   "cluster admin user",
+			mutate: func(kc *controlplanev1.CK8sKubeconfig) { kc.Spec.User = kubeconfig.ClusterAdminUserPrefix + "1" },
+		},
+		{
+			name: "renewBefore not shorter than validity",
+			mutate: func(kc *controlplanev1.CK8sKubeconfig) {
+				kc.Spec.RenewBefore = &metav1.Duration{Duration: kc.Spec.Validity.Duration}
+			},
+		},
+		{
+			name:   "zero validity",
+			mutate: func(kc *controlplanev1.CK8sKubeconfig) { kc.Spec.Validity = metav1.Duration{} },
+		},
+	} {
+		t.Run("rejects "+tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			kc := newCK8sKubeconfig()
+			tc.mutate(kc)
+			r := newKubeconfigReconciler(g, kc)
+
+			result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kc)})
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(result).To(Equal(ctrl.Result{}))
+			g.Expect(r.Get(ctx, client.ObjectKeyFromObject(kc), &corev1.Secret{})).ToNot(Succeed())
+
+			g.Expect(r.Get(ctx, client.ObjectKeyFromObject(kc), kc)).To(Succeed())
+			g.Expect(conditions.GetReason(kc, controlplanev1.KubeconfigAvailableCondition)).To(Equal(controlplanev1.KubeconfigGenerationFailedReason))
+		})
+	}
+}
+
+func TestKubeconfigRenewal(t *testing.T) {
+	now := time.Now()
+	kc := newCK8sKubeconfig()
+	kc.Spec.RenewBefore = &metav1.Duration{Duration: 8 * time.Hour}
+
+	newCert := func(expiresIn time.Duration, groups ...string) *x509.Certificate {
+		return &x509.Certificate{
+			Subject:  pkix.Name{CommonName: "ci", Organization: groups},
+			NotAfter: now.Add(expiresIn),
+		}
+	}
+
+	for _, tc := range []struct {
+		name                 string
+		cert                 *x509.Certificate
+		expectRenewal        bool
+		expectedRequeueAfter time.Duration
+	}{
+		{
+			name:                 "fresh",
+			cert:                 newCert(24*time.Hour, "viewers"),
+			expectedRequeueAfter: 16 * time.Hour,
+		},
+		{
+			name:                 "just before renewal",
+			cert:                 newCert(8*time.Hour+time.Second, "viewers"),
+			expectedRequeueAfter: minKubeconfigRenewalRequeueAfter,
+		},
+		{
+			name:          "due for renewal",
+			cert:          newCert(8*time.Hour, "viewers"),
+			expectRenewal: true,
+		},
+		{
+			name:          "groups changed",
+			cert:          newCert(24*time.Hour, "editors"),
+			expectRenewal: true,
+		},
+		{
+			name:          "validity shortened",
+			cert:          newCert(48*time.Hour, "viewers"),
+			expectRenewal: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(kubeconfigNeedsRenewal(kc, tc.cert, now)).To(Equal(tc.expectRenewal))
+			if !tc.expectRenewal {
+				r := &CK8sKubeconfigReconciler{}
+				g.Expect(r.markKubeconfigAvailable(kc.DeepCopy(), tc.cert, now).RequeueAfter).To(Equal(tc.expectedRequeueAfter))
+			}
+		})
+	}
+}
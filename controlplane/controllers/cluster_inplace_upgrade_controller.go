@@ -0,0 +1,322 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/trace"
+	"github.com/canonical/cluster-api-k8s/pkg/upgrade/inplace"
+)
+
+// ClusterInPlaceUpgradeController reconciles a Cluster object and orchestrates the in-place upgrade
+// of the whole cluster. The control plane is upgraded first, then the MachineDeployments one by one.
+type ClusterInPlaceUpgradeController struct {
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+
+	client.Client
+	Log logr.Logger
+}
+
+// clusterInPlaceUpgradeScope is a struct that holds the context of the upgrade process.
+type clusterInPlaceUpgradeScope struct {
+	cluster            *clusterv1.Cluster
+	clusterPatcher     inplace.Patcher
+	upgradeTo          string
+	ck8sControlPlane   *controlplanev1.CK8sControlPlane
+	machineDeployments []*clusterv1.MachineDeployment
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterInPlaceUpgradeController) SetupWithManager(mgr ctrl.Manager) error {
+	r.scheme = mgr.GetScheme()
+	r.recorder = mgr.GetEventRecorderFor("ck8s-cluster-inplace-upgrade-controller")
+
+	// NOTE: The Cluster is not the controller of the CK8sControlPlane and the MachineDeployments,
+	// so we enqueue the Cluster for any owner reference rather than the controller one only.
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("cluster-inplace-upgrade").
+		For(&clusterv1.Cluster{}).
+		Watches(
+			&controlplanev1.CK8sControlPlane{},
+			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &clusterv1.Cluster{}),
+		).
+		Watches(
+			&clusterv1.MachineDeployment{},
+			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &clusterv1.Cluster{}),
+		).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to get new controller builder: %w", err)
+	}
+
+	return nil
+}
+
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments;machinedeployments/status,verbs=get;list;watch;update;patch
+
+// Reconcile handles the reconciliation of a Cluster object.
+func (r *ClusterInPlaceUpgradeController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	traceID := trace.NewID()
+	log := r.Log.WithValues("cluster_inplace_upgrade", req.NamespacedName, "trace_id", traceID)
+	log.V(1).Info("Reconciliation started...")
+
+	cluster := &clusterv1.Cluster{}
+	if err := r.Get(ctx, req.NamespacedName, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.V(1).Info("Cluster resource not found. Ignoring since the object must be deleted.")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to get Cluster: %w", err)
+	}
+
+	if inplace.GetUpgradeInstructions(cluster) == "" {
+		log.V(1).Info("Cluster has no upgrade instructions, skipping reconciliation")
+		return ctrl.Result{}, nil
+	}
+
+	if isDeleted(cluster) {
+		log.V(1).Info("Cluster is being deleted, skipping reconciliation")
+		return ctrl.Result{}, nil
+	}
+
+	if cluster.Spec.ControlPlaneRef == nil || cluster.Spec.ControlPlaneRef.Kind != "CK8sControlPlane" {
+		log.V(1).Info("Cluster is not managed by a CK8sControlPlane, skipping reconciliation")
+		return ctrl.Result{}, nil
+	}
+
+	scope, err := r.createScope(ctx, cluster)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to create scope: %w", err)
+	}
+
+	// NOTE: The stages are handed over regardless of the maintenance window. Their orchestrators
+	// defer the machine upgrades outside of it and report it on the CK8sControlPlane or MachineDeployment,
+	// the Cluster status is owned by Cluster API.
+	// Stage 1: the control plane
+	if !inplace.IsUpgraded(scope.ck8sControlPlane, scope.upgradeTo) {
+		return r.reconcileStage(ctx, log, scope, scope.ck8sControlPlane)
+	}
+
+	// Stage 2: the workers, one MachineDeployment at a time
+	for _, md := range scope.machineDeployments {
+		if inplace.IsUpgraded(md, scope.upgradeTo) {
+			log.V(1).Info("MachineDeployment is already upgraded", "machineDeployment", md.Name)
+			continue
+		}
+
+		return r.reconcileStage(ctx, log, scope, md)
+	}
+
+	if inplace.IsUpgraded(scope.cluster, scope.upgradeTo) && !inplace.IsUpgrading(scope.cluster, scope.upgradeTo) {
+		log.V(1).Info("Cluster is already upgraded")
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.markUpgradeDone(ctx, scope); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to mark upgrade as done: %w", err)
+	}
+
+	log.V(1).Info("Control plane and all MachineDeployments are upgraded")
+	return ctrl.Result{}, nil
+}
+
+// reconcileStage hands the upgrade over to the orchestrator of obj and waits for it to finish.
+// obj is either the CK8sControlPlane or one of the MachineDeployments of the cluster.
+func (r *ClusterInPlaceUpgradeController) reconcileStage(ctx context.Context, log logr.Logger, scope *clusterInPlaceUpgradeScope, obj client.Object) (ctrl.Result, error) {
+	kind := stageKind(obj)
+	log = log.WithValues("kind", kind, "name", obj.GetName())
+
+	if isDeleted(obj) {
+		log.V(1).Info("Object is being deleted, requeuing...")
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	if inplace.IsUpgradeFailed(obj, scope.upgradeTo) {
+		// NOTE: The orchestrator of obj keeps retrying the failed machine, so we keep watching
+		// in case the upgrade recovers.
+		log.Info("Upgrade failed, requeuing...")
+		if err := r.markUpgradeFailed(ctx, scope, obj); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to mark upgrade as failed: %w", err)
+		}
+
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	if inplace.IsUpgrading(obj, scope.upgradeTo) {
+		// The failed machine recovered after a retry, the upgrade is moving again.
+		if inplace.IsUpgradeFailed(scope.cluster, scope.upgradeTo) {
+			if err := r.markUpgradeInProgress(ctx, scope, obj); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to mark upgrade as in-progress: %w", err)
+			}
+		}
+
+		log.V(1).Info("Upgrade is in-progress, requeuing...")
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	// NOTE: The stages in flight see the pause on the Cluster and hold back their machine upgrades.
+	if inplace.IsPaused(scope.cluster) {
+		log.Info("In-place upgrade is paused, holding back the next stage")
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.markToUpgrade(ctx, scope, obj); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to mark %s to upgrade: %w", kind, err)
+	}
+
+	log.V(1).Info("Marked for upgrade")
+
+	if err := r.markUpgradeInProgress(ctx, scope, obj); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to mark upgrade as in-progress: %w", err)
+	}
+
+	return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+}
+
+// markToUpgrade marks the CK8sControlPlane or MachineDeployment to upgrade.
+func (r *ClusterInPlaceUpgradeController) markToUpgrade(ctx context.Context, scope *clusterInPlaceUpgradeScope, obj client.Object) error {
+	if err := inplace.MarkToUpgrade(ctx, obj, scope.upgradeTo, r.Client); err != nil {
+		return fmt.Errorf("failed to mark object to upgrade: %w", err)
+	}
+
+	r.recorder.Eventf(
+		scope.cluster,
+		corev1.EventTypeNormal,
+		bootstrapv1.InPlaceUpgradeInProgressEvent,
+		"%s %q is upgrading to %q",
+		stageKind(obj),
+		obj.GetName(),
+		scope.upgradeTo,
+	)
+
+	return nil
+}
+
+// markUpgradeInProgress annotates the Cluster with in-place upgrade in-progress.
+func (r *ClusterInPlaceUpgradeController) markUpgradeInProgress(ctx context.Context, scope *clusterInPlaceUpgradeScope, upgrading client.Object) error {
+	if err := inplace.MarkUpgradeInProgress(ctx, scope.cluster, scope.upgradeTo, scope.clusterPatcher); err != nil {
+		return fmt.Errorf("failed to mark object with upgrade in-progress: %w", err)
+	}
+
+	r.recorder.Eventf(
+		scope.cluster,
+		corev1.EventTypeNormal,
+		bootstrapv1.InPlaceUpgradeInProgressEvent,
+		"In-place upgrade is in-progress for %s %q",
+		stageKind(upgrading),
+		upgrading.GetName(),
+	)
+	return nil
+}
+
+// markUpgradeDone annotates the Cluster with in-place upgrade done.
+func (r *ClusterInPlaceUpgradeController) markUpgradeDone(ctx context.Context, scope *clusterInPlaceUpgradeScope) error {
+	if err := inplace.MarkUpgradeDone(ctx, scope.cluster, scope.upgradeTo, scope.clusterPatcher); err != nil {
+		return fmt.Errorf("failed to mark object with upgrade done: %w", err)
+	}
+
+	r.recorder.Eventf(
+		scope.cluster,
+		corev1.EventTypeNormal,
+		bootstrapv1.InPlaceUpgradeDoneEvent,
+		"In-place upgrade is done",
+	)
+	return nil
+}
+
+// markUpgradeFailed annotates the Cluster with in-place upgrade failed.
+func (r *ClusterInPlaceUpgradeController) markUpgradeFailed(ctx context.Context, scope *clusterInPlaceUpgradeScope, failed client.Object) error {
+	if inplace.IsUpgradeFailed(scope.cluster, scope.upgradeTo) {
+		// Already reported
+		return nil
+	}
+
+	if err := inplace.MarkUpgradeFailed(ctx, scope.cluster, scope.clusterPatcher); err != nil {
+		return fmt.Errorf("failed to mark object with upgrade failed: %w", err)
+	}
+
+	r.recorder.Eventf(
+		scope.cluster,
+		corev1.EventTypeWarning,
+		bootstrapv1.InPlaceUpgradeFailedEvent,
+		"In-place upgrade failed for %s %q.",
+		stageKind(failed),
+		failed.GetName(),
+	)
+	return nil
+}
+
+// createScope creates a new clusterInPlaceUpgradeScope.
+func (r *ClusterInPlaceUpgradeController) createScope(ctx context.Context, cluster *clusterv1.Cluster) (*clusterInPlaceUpgradeScope, error) {
+	patchHelper, err := patch.NewHelper(cluster, r.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new patch helper: %w", err)
+	}
+
+	ck8sCP := &controlplanev1.CK8sControlPlane{}
+	cpKey := client.ObjectKey{
+		Namespace: cluster.Spec.ControlPlaneRef.Namespace,
+		Name:      cluster.Spec.ControlPlaneRef.Name,
+	}
+	if err := r.Get(ctx, cpKey, ck8sCP); err != nil {
+		return nil, fmt.Errorf("failed to get CK8sControlPlane: %w", err)
+	}
+
+	machineDeployments, err := r.getMachineDeployments(ctx, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MachineDeployments: %w", err)
+	}
+
+	return &clusterInPlaceUpgradeScope{
+		cluster:            cluster,
+		clusterPatcher:     patchHelper,
+		upgradeTo:          inplace.GetUpgradeInstructions(cluster),
+		ck8sControlPlane:   ck8sCP,
+		machineDeployments: machineDeployments,
+	}, nil
+}
+
+// getMachineDeployments gets the MachineDeployments of the cluster, sorted by name.
+func (r *ClusterInPlaceUpgradeController) getMachineDeployments(ctx context.Context, cluster *clusterv1.Cluster) ([]*clusterv1.MachineDeployment, error) {
+	var mdList clusterv1.MachineDeploymentList
+	if err := r.List(ctx, &mdList, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: cluster.Name}); err != nil {
+		return nil, fmt.Errorf("failed to get MachineDeploymentList: %w", err)
+	}
+
+	machineDeployments := make([]*clusterv1.MachineDeployment, len(mdList.Items))
+	for i := range mdList.Items {
+		machineDeployments[i] = &mdList.Items[i]
+	}
+
+	// NOTE: Sorting by name gives a deterministic upgrade order across reconciliations.
+	slices.SortStableFunc(machineDeployments, func(md1, md2 *clusterv1.MachineDeployment) int {
+		return strings.Compare(md1.Name, md2.Name)
+	})
+
+	return machineDeployments, nil
+}
+
+// stageKind returns the kind of the object upgraded in a stage, used in logs and events.
+func stageKind(obj client.Object) string {
+	if _, ok := obj.(*controlplanev1.CK8sControlPlane); ok {
+		return "CK8sControlPlane"
+	}
+	return "MachineDeployment"
+}
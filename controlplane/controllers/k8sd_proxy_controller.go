@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/ck8s"
+	"github.com/canonical/cluster-api-k8s/pkg/trace"
+)
+
+// k8sdProxyResyncPeriod is how often the k8sd-proxy daemonset is checked for drift in the workload cluster,
+// as changes in the workload cluster are not watched.
+const k8sdProxyResyncPeriod = 5 * time.Minute
+
+// K8sdProxyReconciler reconciles a CK8sControlPlane object and maintains the k8sd-proxy daemonset in its workload cluster.
+type K8sdProxyReconciler struct {
+	managementCluster ck8s.ManagementCluster
+
+	client.Client
+	Log logr.Logger
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *K8sdProxyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.managementCluster = &ck8s.Management{
+		Client: r.Client,
+	}
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("k8sd-proxy").
+		For(&controlplanev1.CK8sControlPlane{}).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to get new controller builder: %w", err)
+	}
+
+	return nil
+}
+
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+
+// Reconcile handles the reconciliation of a CK8sControlPlane object.
+func (r *K8sdProxyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	traceID := trace.NewID()
+	log := r.Log.WithValues("k8sd_proxy", req.NamespacedName, "trace_id", traceID)
+	log.V(1).Info("Reconciliation started...")
+
+	kcp := &controlplanev1.CK8sControlPlane{}
+	if err := r.Get(ctx, req.NamespacedName, kcp); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.V(1).Info("CK8sControlPlane resource not found. Ignoring since the object must be deleted.")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to get CK8sControlPlane: %w", err)
+	}
+
+	if isDeleted(kcp) {
+		log.V(1).Info("CK8sControlPlane is being deleted, skipping reconciliation")
+		return ctrl.Result{}, nil
+	}
+
+	cluster, err := util.GetOwnerCluster(ctx, r.Client, kcp.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get owner cluster: %w", err)
+	}
+	if cluster == nil {
+		log.V(1).Info("Cluster Controller has not yet set OwnerRef")
+		return ctrl.Result{}, nil
+	}
+
+	if annotations.IsPaused(cluster, kcp) {
+		log.V(1).Info("Reconciliation is paused for this object")
+		return ctrl.Result{}, nil
+	}
+
+	// NOTE: The workload cluster is not reachable before the control plane is initialized. Until then, the
+	// k8sd-proxy daemonset is deployed by the init control plane node.
+	if !kcp.Status.Initialized {
+		log.V(1).Info("Control plane is not initialized yet")
+		return ctrl.Result{}, nil
+	}
+
+	microclusterPort := kcp.Spec.CK8sConfigSpec.ControlPlaneConfig.GetMicroclusterPort()
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(cluster), microclusterPort)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get workload cluster: %w", err)
+	}
+
+	changed, err := workloadCluster.ReconcileK8sdProxy(ctx, ck8s.NewK8sdProxyDaemonSetInput(microclusterPort, kcp.Spec.K8sdProxy))
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to reconcile k8sd-proxy: %w", err)
+	}
+	if changed {
+		log.Info("Applied the k8sd-proxy daemonset to the workload cluster")
+	}
+
+	return ctrl.Result{RequeueAfter: k8sdProxyResyncPeriod}, nil
+}
@@ -13,6 +13,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/collections"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -20,6 +21,7 @@ import (
 	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
 	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
 	"github.com/canonical/cluster-api-k8s/pkg/ck8s"
+	"github.com/canonical/cluster-api-k8s/pkg/maintenance"
 	"github.com/canonical/cluster-api-k8s/pkg/trace"
 	"github.com/canonical/cluster-api-k8s/pkg/upgrade/inplace"
 )
@@ -33,6 +35,10 @@ type OrchestratedInPlaceUpgradeController struct {
 	client.Client
 	Log  logr.Logger
 	lock inplace.UpgradeLock
+
+	K8sdDialTimeout time.Duration
+
+	managementCluster ck8s.ManagementCluster
 }
 
 // OrchestratedInPlaceUpgradeScope is a struct that holds the context of the upgrade process.
@@ -42,6 +48,9 @@ type OrchestratedInPlaceUpgradeScope struct {
 	ck8sPatcher      inplace.Patcher
 	upgradeTo        string
 	ownedMachines    collections.Machines
+	healthChecks     *inplace.HealthChecks
+	window           *maintenance.Window
+	paused           bool
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -52,6 +61,12 @@ func (r *OrchestratedInPlaceUpgradeController) SetupWithManager(mgr ctrl.Manager
 		Client: r.Client,
 	}
 	r.lock = inplace.NewUpgradeLock(r.Client)
+	if r.managementCluster == nil {
+		r.managementCluster = &ck8s.Management{
+			Client:          r.Client,
+			K8sdDialTimeout: r.K8sdDialTimeout,
+		}
+	}
 
 	if err := ctrl.NewControllerManagedBy(mgr).
 		For(&controlplanev1.CK8sControlPlane{}).
@@ -70,7 +85,7 @@ func (r *OrchestratedInPlaceUpgradeController) SetupWithManager(mgr ctrl.Manager
 // +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
 
 // Reconcile handles the reconciliation of a CK8sControlPlane object.
-func (r *OrchestratedInPlaceUpgradeController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *OrchestratedInPlaceUpgradeController) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
 	traceID := trace.NewID()
 	log := r.Log.WithValues("orchestrated_inplace_upgrade", req.NamespacedName, "trace_id", traceID)
 	log.V(1).Info("Reconciliation started...")
@@ -104,6 +119,16 @@ func (r *OrchestratedInPlaceUpgradeController) Reconcile(ctx context.Context, re
 		return ctrl.Result{}, fmt.Errorf("failed to create scope: %w", err)
 	}
 
+	// Always report the progress of the upgrade, whatever the outcome of the reconciliation.
+	defer func() {
+		if err := r.updateProgress(ctx, scope); err != nil {
+			log.Error(err, "Failed to update in-place upgrade progress", "rerr", rerr)
+			if rerr == nil {
+				rerr = err
+			}
+		}
+	}()
+
 	upgradingMachine, err := r.lock.IsLocked(ctx, scope.cluster)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to check if upgrade is locked: %w", err)
@@ -157,6 +182,30 @@ func (r *OrchestratedInPlaceUpgradeController) Reconcile(ctx context.Context, re
 			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 		}
 
+		// NOTE: Pausing only holds back the start of a machine upgrade, like the maintenance window.
+		if scope.paused {
+			log.Info("In-place upgrade is paused, holding back the upgrade of the next machine", "machine", m.Name)
+			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+
+		// NOTE: Only the start of a machine upgrade waits for the maintenance window,
+		// a machine that is already upgrading is left to complete above.
+		if open, err := r.checkMaintenanceWindow(ctx, scope); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to check maintenance window: %w", err)
+		} else if !open {
+			log.Info("Outside of the maintenance window, deferring the upgrade of the next machine", "machine", m.Name)
+			return ctrl.Result{RequeueAfter: scope.window.RequeueAfter(time.Now())}, nil
+		}
+
+		// NOTE: This also acts as the post-upgrade gate of the previously upgraded machine,
+		// so a bad release stops after the first machine.
+		if passed, err := r.checkHealthGates(ctx, scope); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to check health gates: %w", err)
+		} else if !passed {
+			log.Info("Health gates failed, holding back the upgrade of the next machine", "machine", m.Name)
+			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+
 		// Lock the process for the machine and start the upgrade
 		if err := r.lock.Lock(ctx, scope.cluster, m); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to lock upgrade for machine %q: %w", m.Name, err)
@@ -176,6 +225,13 @@ func (r *OrchestratedInPlaceUpgradeController) Reconcile(ctx context.Context, re
 	}
 
 	if upgradedMachines == len(scope.ownedMachines) {
+		if passed, err := r.checkHealthGates(ctx, scope); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to check health gates: %w", err)
+		} else if !passed {
+			log.Info("Health gates failed after upgrading all machines, requeuing...")
+			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+
 		if err := r.markUpgradeDone(ctx, scope); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to mark upgrade as done: %w", err)
 		}
@@ -234,6 +290,78 @@ func (r *OrchestratedInPlaceUpgradeController) markUpgradeFailed(ctx context.Con
 	return nil
 }
 
+// checkHealthGates runs the health gates configured on the CK8sControlPlane and reports the outcome in the
+// InPlaceUpgradeHealthGatesPassed condition. Returns false if the upgrade must be held back.
+func (r *OrchestratedInPlaceUpgradeController) checkHealthGates(ctx context.Context, scope *OrchestratedInPlaceUpgradeScope) (bool, error) {
+	if scope.healthChecks == nil {
+		return true, nil
+	}
+
+	microclusterPort := scope.ck8sControlPlane.Spec.CK8sConfigSpec.ControlPlaneConfig.GetMicroclusterPort()
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(scope.cluster), microclusterPort)
+	if err != nil {
+		return false, fmt.Errorf("failed to get workload cluster: %w", err)
+	}
+
+	if err := scope.healthChecks.Check(ctx, scope.ck8sControlPlane, workloadCluster, nil); err != nil {
+		conditions.MarkFalse(scope.ck8sControlPlane, bootstrapv1.InPlaceUpgradeHealthGatesPassedCondition, bootstrapv1.InPlaceUpgradeHealthGateFailedReason, clusterv1.ConditionSeverityWarning, "%s", err.Error())
+		if err := scope.ck8sPatcher.Patch(ctx, scope.ck8sControlPlane); err != nil {
+			return false, fmt.Errorf("failed to patch CK8sControlPlane: %w", err)
+		}
+
+		r.recorder.Eventf(
+			scope.ck8sControlPlane,
+			corev1.EventTypeWarning,
+			bootstrapv1.InPlaceUpgradeHealthGateFailedEvent,
+			"In-place upgrade held back by health gates: %s",
+			err.Error(),
+		)
+		return false, nil
+	}
+
+	if !conditions.IsTrue(scope.ck8sControlPlane, bootstrapv1.InPlaceUpgradeHealthGatesPassedCondition) {
+		conditions.MarkTrue(scope.ck8sControlPlane, bootstrapv1.InPlaceUpgradeHealthGatesPassedCondition)
+		if err := scope.ck8sPatcher.Patch(ctx, scope.ck8sControlPlane); err != nil {
+			return false, fmt.Errorf("failed to patch CK8sControlPlane: %w", err)
+		}
+	}
+
+	return true, nil
+}
+
+// updateProgress reports the progress of the upgrade in the MachinesInPlaceUpgraded condition of the
+// CK8sControlPlane, and emits an event when the upgrade is paused or resumed.
+func (r *OrchestratedInPlaceUpgradeController) updateProgress(ctx context.Context, scope *OrchestratedInPlaceUpgradeScope) error {
+	wasPaused := conditions.GetReason(scope.ck8sControlPlane, bootstrapv1.MachinesInPlaceUpgradedCondition) == bootstrapv1.InPlaceUpgradePausedReason
+
+	progress := inplace.GetProgress(scope.ownedMachines.UnsortedList(), scope.upgradeTo, scope.paused)
+	inplace.MarkProgress(scope.ck8sControlPlane, progress)
+	if err := scope.ck8sPatcher.Patch(ctx, scope.ck8sControlPlane); err != nil {
+		return fmt.Errorf("failed to patch CK8sControlPlane: %w", err)
+	}
+
+	isPaused := conditions.GetReason(scope.ck8sControlPlane, bootstrapv1.MachinesInPlaceUpgradedCondition) == bootstrapv1.InPlaceUpgradePausedReason
+	switch {
+	case isPaused && !wasPaused:
+		r.recorder.Eventf(scope.ck8sControlPlane, corev1.EventTypeNormal, bootstrapv1.InPlaceUpgradePausedEvent, "In-place upgrade is paused. %s", progress)
+	case !isPaused && wasPaused:
+		r.recorder.Eventf(scope.ck8sControlPlane, corev1.EventTypeNormal, bootstrapv1.InPlaceUpgradeResumedEvent, "In-place upgrade is resumed. %s", progress)
+	}
+
+	return nil
+}
+
+// checkMaintenanceWindow reports the maintenance window in the MaintenanceWindowOpen condition of the
+// CK8sControlPlane. Returns false if no new machine upgrade may start.
+func (r *OrchestratedInPlaceUpgradeController) checkMaintenanceWindow(ctx context.Context, scope *OrchestratedInPlaceUpgradeScope) (bool, error) {
+	open := maintenance.Check(scope.ck8sControlPlane, scope.window, time.Now())
+	if err := scope.ck8sPatcher.Patch(ctx, scope.ck8sControlPlane); err != nil {
+		return false, fmt.Errorf("failed to patch CK8sControlPlane: %w", err)
+	}
+
+	return open, nil
+}
+
 // createScope creates a new OrchestratedInPlaceUpgradeScope.
 func (r *OrchestratedInPlaceUpgradeController) createScope(ctx context.Context, ck8sCP *controlplanev1.CK8sControlPlane) (*OrchestratedInPlaceUpgradeScope, error) {
 	patchHelper, err := patch.NewHelper(ck8sCP, r.Client)
@@ -251,12 +379,25 @@ func (r *OrchestratedInPlaceUpgradeController) createScope(ctx context.Context,
 		return nil, fmt.Errorf("failed to get owned machines: %w", err)
 	}
 
+	healthChecks, err := inplace.GetHealthChecks(ck8sCP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get health checks: %w", err)
+	}
+
+	window, err := maintenance.ResolveWindow(ck8sCP, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance window: %w", err)
+	}
+
 	return &OrchestratedInPlaceUpgradeScope{
 		cluster:          cluster,
 		ck8sControlPlane: ck8sCP,
 		upgradeTo:        inplace.GetUpgradeInstructions(ck8sCP),
 		ownedMachines:    ownedMachines,
 		ck8sPatcher:      patchHelper,
+		healthChecks:     healthChecks,
+		window:           window,
+		paused:           inplace.IsPaused(ck8sCP, cluster),
 	}, nil
 }
 
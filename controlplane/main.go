@@ -37,6 +37,7 @@ import (
 	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
 	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
 	"github.com/canonical/cluster-api-k8s/controlplane/controllers"
+	"github.com/canonical/cluster-api-k8s/controlplane/webhooks"
 )
 
 var (
@@ -124,17 +125,67 @@ func main() {
 
 	inplaceUpgradeLogger := ctrl.Log.WithName("controllers").WithName("OrchestratedInPlaceUpgrade")
 	if err = (&controllers.OrchestratedInPlaceUpgradeController{
-		Client: mgr.GetClient(),
-		Log:    inplaceUpgradeLogger,
+		Client:          mgr.GetClient(),
+		Log:             inplaceUpgradeLogger,
+		K8sdDialTimeout: k8sdDialTimeout,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "failed to create controller", "controller", "OrchestratedInPlaceUpgrade")
 	}
 
+	clusterUpgradeLogger := ctrl.Log.WithName("controllers").WithName("ClusterInPlaceUpgrade")
+	if err = (&controllers.ClusterInPlaceUpgradeController{
+		Client: mgr.GetClient(),
+		Log:    clusterUpgradeLogger,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterInPlaceUpgrade")
+		os.Exit(1)
+	}
+
+	certificatesLogger := ctrl.Log.WithName("controllers").WithName("ControlPlaneCertificates")
+	if err = (&controllers.ControlPlaneCertificatesReconciler{
+		Client: mgr.GetClient(),
+		Log:    certificatesLogger,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ControlPlaneCertificates")
+		os.Exit(1)
+	}
+
+	inventoryLogger := ctrl.Log.WithName("controllers").WithName("CertificatesInventory")
+	if err = (&controllers.CertificatesInventoryReconciler{
+		Client: mgr.GetClient(),
+		Log:    inventoryLogger,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CertificatesInventory")
+		os.Exit(1)
+	}
+
+	k8sdProxyLogger := ctrl.Log.WithName("controllers").WithName("K8sdProxy")
+	if err = (&controllers.K8sdProxyReconciler{
+		Client: mgr.GetClient(),
+		Log:    k8sdProxyLogger,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "K8sdProxy")
+		os.Exit(1)
+	}
+
+	kubeconfigLogger := ctrl.Log.WithName("controllers").WithName("CK8sKubeconfig")
+	if err = (&controllers.CK8sKubeconfigReconciler{
+		Client: mgr.GetClient(),
+		Log:    kubeconfigLogger,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CK8sKubeconfig")
+		os.Exit(1)
+	}
+
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
 		if err = (&controlplanev1.CK8sControlPlane{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "CK8sControlPlane")
 			os.Exit(1)
 		}
+		if err = webhooks.SetupCertificatesRefreshWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "CertificatesRefresh")
+			os.Exit(1)
+		}
 	}
 	// +kubebuilder:scaffold:builder
 
@@ -0,0 +1,31 @@
+package webhooks
+
+import (
+	"fmt"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/certificates"
+)
+
+// certificatesRefreshPath is the path of the webhook validating the certificates refresh annotations of
+// CK8sControlPlanes. It differs from the path of the CK8sControlPlane validating webhook, which is served too.
+const certificatesRefreshPath = "/validate-controlplane-cluster-x-k8s-io-v1beta2-ck8scontrolplane-certificates-refresh"
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-controlplane-cluster-x-k8s-io-v1beta2-ck8scontrolplane-certificates-refresh,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=controlplane.cluster.x-k8s.io,resources=ck8scontrolplanes,versions=v1beta2,name=validation-certificates-refresh.ck8scontrolplane.controlplane.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
+
+// SetupCertificatesRefreshWebhookWithManager sets up the webhook validating the certificates refresh
+// annotations of CK8sControlPlanes.
+func SetupCertificatesRefreshWebhookWithManager(mgr ctrl.Manager) error {
+	obj := &controlplanev1.CK8sControlPlane{}
+	if _, err := apiutil.GVKForObject(obj, mgr.GetScheme()); err != nil {
+		return fmt.Errorf("failed to create webhook for %T: %w", obj, err)
+	}
+
+	validator := &certificates.RefreshValidator{Client: mgr.GetClient()}
+	mgr.GetWebhookServer().Register(certificatesRefreshPath, admission.WithCustomValidator(mgr.GetScheme(), obj, validator))
+	return nil
+}
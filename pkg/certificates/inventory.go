@@ -0,0 +1,105 @@
+package certificates
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/kubeconfig"
+	"github.com/canonical/cluster-api-k8s/pkg/secret"
+)
+
+// inventoryPurposes are the purposes of the secrets of a cluster that hold a certificate that is not
+// a certificate authority, in inventory order.
+var inventoryPurposes = []secret.Purpose{
+	secret.APIServerEtcdClient,
+	secret.Kubeconfig,
+}
+
+// NewInventory returns the inventory of the certificates of the cluster, from the certificates stored in
+// the secrets of the cluster and the certificates expiry recorded on its machines.
+// The certificate authorities of the cluster are not part of the inventory.
+// Missing secrets and machines that did not record the expiry of their certificates are skipped.
+func NewInventory(ctx context.Context, c client.Reader, clusterName client.ObjectKey, machines []*clusterv1.Machine) (*controlplanev1.CertificatesInventory, error) {
+	inventory := &controlplanev1.CertificatesInventory{}
+
+	for _, purpose := range inventoryPurposes {
+		s, err := secret.GetFromNamespacedName(ctx, c, clusterName, purpose)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get secret %s: %w", secret.Name(clusterName.Name, purpose), err)
+		}
+
+		cert, err := secretCertificate(s, purpose)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the certificate of secret %s: %w", s.Name, err)
+		}
+
+		status := bootstrapv1.CertificateStatus{
+			Name:                 s.Name,
+			ExpiresAt:            &metav1.Time{Time: cert.NotAfter},
+			CertificateAuthority: cert.Issuer.CommonName,
+		}
+		inventory.Secrets = append(inventory.Secrets, status)
+	}
+
+	for _, m := range machines {
+		expiry, ok := GetMachineExpiry(m)
+		if !ok {
+			continue
+		}
+		if inventory.EarliestMachineExpiry == nil || expiry.Before(inventory.EarliestMachineExpiry.Time) {
+			inventory.EarliestMachineExpiry = &metav1.Time{Time: expiry}
+			inventory.EarliestExpiringMachine = m.Name
+		}
+	}
+
+	return inventory, nil
+}
+
+// EarliestExpiry returns the earliest expiry of the certificate authorities and of the inventory, and the name
+// of the certificate or of the machine whose certificate expires then. It returns false if none has an expiry.
+func EarliestExpiry(certificateAuthorities []bootstrapv1.CertificateStatus, inventory *controlplanev1.CertificatesInventory) (time.Time, string, bool) {
+	var earliest time.Time
+	var name string
+	for _, s := range append(slices.Clone(certificateAuthorities), inventory.Secrets...) {
+		if s.ExpiresAt == nil {
+			continue
+		}
+		if name == "" || s.ExpiresAt.Time.Before(earliest) {
+			earliest, name = s.ExpiresAt.Time, s.Name
+		}
+	}
+	if m := inventory.EarliestMachineExpiry; m != nil && (name == "" || m.Time.Before(earliest)) {
+		earliest, name = m.Time, inventory.EarliestExpiringMachine
+	}
+	return earliest, name, name != ""
+}
+
+// secretCertificate returns the certificate stored in the secret of the given purpose.
+func secretCertificate(s *corev1.Secret, purpose secret.Purpose) (*x509.Certificate, error) {
+	if purpose == secret.Kubeconfig {
+		return kubeconfig.GetClientCertificate(s)
+	}
+
+	cert, err := certs.DecodeCertPEM(s.Data[secret.TLSCrtDataName])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode certificate: %w", err)
+	} else if cert == nil {
+		return nil, secret.ErrMissingCrt
+	}
+	return cert, nil
+}
@@ -0,0 +1,128 @@
+package certificates_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/certificates"
+	"github.com/canonical/cluster-api-k8s/pkg/kubeconfig"
+	"github.com/canonical/cluster-api-k8s/pkg/secret"
+)
+
+func TestNewInventory(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	now := time.Now()
+	clusterName := client.ObjectKey{Namespace: "default", Name: "cluster"}
+
+	cas := secret.NewCertificatesForInitialControlPlane(&bootstrapv1.CK8sConfigSpec{})
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	g.Expect(cas.LookupOrGenerate(ctx, c, clusterName, metav1.OwnerReference{})).To(Succeed())
+
+	data, err := kubeconfig.Generate(ctx, c, clusterName, "10.0.0.1:6443", kubeconfig.Options{
+		Identity: kubeconfig.SystemMastersIdentity,
+		Validity: 24 * time.Hour,
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(c.Create(ctx, kubeconfig.GenerateSecretWithOwner(clusterName, data, metav1.OwnerReference{}))).To(Succeed())
+
+	expiresIn := func(d time.Duration) map[string]string {
+		return map[string]string{bootstrapv1.MachineCertificatesExpiryDateAnnotation: now.Add(d).Format(time.RFC3339)}
+	}
+	machines := []*clusterv1.Machine{
+		newMachine("cp-1", expiresIn(72*time.Hour)),
+		newMachine("worker-1", expiresIn(48*time.Hour)),
+		newMachine("worker-2", nil),
+	}
+
+	inventory, err := certificates.NewInventory(ctx, c, clusterName, machines)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// NOTE: The certificate authorities are not part of the inventory.
+	g.Expect(inventory.Secrets).To(HaveLen(1))
+	g.Expect(inventory.Secrets[0].Name).To(Equal("cluster-kubeconfig"))
+	g.Expect(inventory.Secrets[0].CertificateAuthority).To(Equal("kubernetes"))
+	g.Expect(inventory.Secrets[0].ExpiresAt.Time).To(BeTemporally("~", now.Add(24*time.Hour), time.Minute))
+
+	g.Expect(inventory.EarliestExpiringMachine).To(Equal("worker-1"))
+	g.Expect(inventory.EarliestMachineExpiry.Time).To(BeTemporally("~", now.Add(48*time.Hour), time.Second))
+
+	certificateAuthorities, err := cas.CertificateAuthoritiesStatus(clusterName)
+	g.Expect(err).ToNot(HaveOccurred())
+	earliest, name, ok := certificates.EarliestExpiry(certificateAuthorities, inventory)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(name).To(Equal("cluster-kubeconfig"))
+	g.Expect(earliest).To(BeTemporally("~", now.Add(24*time.Hour), time.Minute))
+}
+
+func TestEarliestExpiry(t *testing.T) {
+	now := time.Now()
+	certificateAuthorities := []bootstrapv1.CertificateStatus{
+		{Name: "cluster-ca", ExpiresAt: &metav1.Time{Time: now.Add(2 * time.Hour)}},
+	}
+
+	for _, tc := range []struct {
+		name                   string
+		certificateAuthorities []bootstrapv1.CertificateStatus
+		inventory              *controlplanev1.CertificatesInventory
+		expectName             string
+		expectExpiry           time.Time
+	}{
+		{
+			name:      "empty",
+			inventory: &controlplanev1.CertificatesInventory{},
+		},
+		{
+			name:                   "certificate authority",
+			certificateAuthorities: certificateAuthorities,
+			inventory: &controlplanev1.CertificatesInventory{
+				EarliestMachineExpiry:   &metav1.Time{Time: now.Add(3 * time.Hour)},
+				EarliestExpiringMachine: "worker-1",
+			},
+			expectName:   "cluster-ca",
+			expectExpiry: now.Add(2 * time.Hour),
+		},
+		{
+			name:                   "secret",
+			certificateAuthorities: certificateAuthorities,
+			inventory: &controlplanev1.CertificatesInventory{
+				Secrets: []bootstrapv1.CertificateStatus{
+					{Name: "cluster-kubeconfig", ExpiresAt: &metav1.Time{Time: now.Add(time.Hour)}},
+				},
+				EarliestMachineExpiry:   &metav1.Time{Time: now.Add(3 * time.Hour)},
+				EarliestExpiringMachine: "worker-1",
+			},
+			expectName:   "cluster-kubeconfig",
+			expectExpiry: now.Add(time.Hour),
+		},
+		{
+			name:                   "machine",
+			certificateAuthorities: certificateAuthorities,
+			inventory: &controlplanev1.CertificatesInventory{
+				EarliestMachineExpiry:   &metav1.Time{Time: now.Add(time.Hour)},
+				EarliestExpiringMachine: "worker-1",
+			},
+			expectName:   "worker-1",
+			expectExpiry: now.Add(time.Hour),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			expiry, name, ok := certificates.EarliestExpiry(tc.certificateAuthorities, tc.inventory)
+			g.Expect(ok).To(Equal(tc.expectName != ""))
+			g.Expect(name).To(Equal(tc.expectName))
+			g.Expect(expiry).To(Equal(tc.expectExpiry))
+		})
+	}
+}
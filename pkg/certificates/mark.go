@@ -0,0 +1,91 @@
+package certificates
+
+import (
+	"context"
+	"fmt"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+)
+
+// MarkMachineToRefresh annotates the machine with the certificates refresh TTL.
+func MarkMachineToRefresh(ctx context.Context, m *clusterv1.Machine, ttl string, c client.Client) error {
+	patchHelper, err := patch.NewHelper(m, c)
+	if err != nil {
+		return fmt.Errorf("failed to create new patch helper: %w", err)
+	}
+
+	if m.Annotations == nil {
+		m.Annotations = make(map[string]string)
+	}
+
+	// clean up
+	delete(m.Annotations, bootstrapv1.CertificatesRefreshStatusAnnotation)
+
+	m.Annotations[bootstrapv1.CertificatesRefreshAnnotation] = ttl
+
+	if err := patchHelper.Patch(ctx, m); err != nil {
+		return fmt.Errorf("failed to patch: %w", err)
+	}
+
+	return nil
+}
+
+// ResetMachineRefreshStatus removes the certificates refresh status left on the machine by a previous refresh.
+func ResetMachineRefreshStatus(ctx context.Context, m *clusterv1.Machine, c client.Client) error {
+	if _, ok := m.Annotations[bootstrapv1.CertificatesRefreshStatusAnnotation]; !ok {
+		return nil
+	}
+
+	patchHelper, err := patch.NewHelper(m, c)
+	if err != nil {
+		return fmt.Errorf("failed to create new patch helper: %w", err)
+	}
+
+	delete(m.Annotations, bootstrapv1.CertificatesRefreshStatusAnnotation)
+
+	if err := patchHelper.Patch(ctx, m); err != nil {
+		return fmt.Errorf("failed to patch: %w", err)
+	}
+
+	return nil
+}
+
+// MarkRefreshInProgress annotates the object with certificates refresh in-progress.
+func MarkRefreshInProgress(ctx context.Context, obj client.Object, patcher Patcher) error {
+	return markRefreshStatus(ctx, obj, bootstrapv1.CertificatesRefreshInProgressStatus, false, patcher)
+}
+
+// MarkRefreshDone annotates the object with certificates refresh done and removes the refresh request.
+func MarkRefreshDone(ctx context.Context, obj client.Object, patcher Patcher) error {
+	return markRefreshStatus(ctx, obj, bootstrapv1.CertificatesRefreshDoneStatus, true, patcher)
+}
+
+// MarkRefreshFailed annotates the object with certificates refresh failed and removes the refresh request,
+// so that a new refresh can be requested by annotating the object again.
+func MarkRefreshFailed(ctx context.Context, obj client.Object, patcher Patcher) error {
+	return markRefreshStatus(ctx, obj, bootstrapv1.CertificatesRefreshFailedStatus, true, patcher)
+}
+
+func markRefreshStatus(ctx context.Context, obj client.Object, status string, clearRequest bool, patcher Patcher) error {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	if clearRequest {
+		delete(annotations, bootstrapv1.CertificatesRefreshAnnotation)
+	}
+
+	annotations[bootstrapv1.CertificatesRefreshStatusAnnotation] = status
+	obj.SetAnnotations(annotations)
+
+	if err := patcher.Patch(ctx, obj); err != nil {
+		return fmt.Errorf("failed to patch: %w", err)
+	}
+
+	return nil
+}
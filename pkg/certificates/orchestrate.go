@@ -0,0 +1,235 @@
+package certificates
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/maintenance"
+)
+
+// refreshPollInterval is how often the progress of the machines that are refreshing certificates is checked.
+const refreshPollInterval = 5 * time.Second
+
+// Owner is an object that owns machines and orchestrates their certificates refresh,
+// e.g. a CK8sControlPlane or a MachineDeployment.
+type Owner interface {
+	conditions.Setter
+}
+
+// Scope holds the context of the certificates refresh or renewal of the machines of an owner.
+type Scope struct {
+	Owner   Owner
+	Patcher Patcher
+	// Machines are the machines of the owner, in a deterministic order across reconciliations.
+	Machines []*clusterv1.Machine
+	Window   *maintenance.Window
+}
+
+// Orchestrator refreshes and renews the certificates of the machines of an owner, a few machines at a time.
+type Orchestrator struct {
+	Client   client.Client
+	Recorder record.EventRecorder
+}
+
+// ReconcileRefresh moves forward the certificates refresh requested on the owner.
+func (o *Orchestrator) ReconcileRefresh(ctx context.Context, log logr.Logger, scope *Scope) (ctrl.Result, error) {
+	ttl := GetRefreshTTL(scope.Owner)
+
+	if !IsRefreshInProgress(scope.Owner) {
+		if err := o.markRefreshInProgress(ctx, scope, ttl); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to mark certificates refresh as in-progress: %w", err)
+		}
+	}
+
+	plan := PlanRefresh(scope.Machines, GetRefreshMaxParallel(scope.Owner))
+
+	if len(plan.Failed) > 0 {
+		log.Info("Certificates refresh failed for machines, halting", "machines", plan.FailedMachineNames())
+		if err := o.markRefreshFailed(ctx, scope, plan); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to mark certificates refresh as failed: %w", err)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if plan.Done() {
+		if err := o.markRefreshDone(ctx, scope, plan); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to mark certificates refresh as done: %w", err)
+		}
+
+		log.V(1).Info("All machines have their certificates refreshed")
+		return ctrl.Result{}, nil
+	}
+
+	if len(plan.ToRefresh) > 0 && !maintenance.Check(scope.Owner, scope.Window, time.Now()) {
+		// NOTE: Only the start of machine refreshes waits for the maintenance window,
+		// the machines that are already refreshing are left to complete.
+		log.Info("Outside of the maintenance window, deferring the certificates refresh of the next machines")
+		if err := o.updateProgress(ctx, scope, plan); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update certificates refresh progress: %w", err)
+		}
+		return ctrl.Result{RequeueAfter: scope.Window.RequeueAfter(time.Now())}, nil
+	}
+
+	for _, m := range plan.ToRefresh {
+		if err := o.markMachineToRefresh(ctx, scope, m, ttl); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to mark machine to refresh certificates: %w", err)
+		}
+
+		log.V(1).Info("Machine marked for certificates refresh", "machine", m.Name)
+	}
+
+	if err := o.updateProgress(ctx, scope, plan); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update certificates refresh progress: %w", err)
+	}
+
+	return ctrl.Result{RequeueAfter: refreshPollInterval}, nil
+}
+
+// markRefreshInProgress starts a new certificates refresh for the owner.
+// The status left on the machines by any previous refresh is cleared, so every machine gets refreshed again.
+func (o *Orchestrator) markRefreshInProgress(ctx context.Context, scope *Scope, ttl string) error {
+	for _, m := range scope.Machines {
+		if err := ResetMachineRefreshStatus(ctx, m, o.Client); err != nil {
+			return fmt.Errorf("failed to reset certificates refresh status of machine %q: %w", m.Name, err)
+		}
+	}
+
+	conditions.MarkFalse(scope.Owner, bootstrapv1.CertificatesRefreshedCondition, bootstrapv1.CertificatesRefreshInProgressReason, clusterv1.ConditionSeverityInfo, "Refreshed 0 of %d machines", len(scope.Machines))
+	if err := MarkRefreshInProgress(ctx, scope.Owner, scope.Patcher); err != nil {
+		return fmt.Errorf("failed to mark object with certificates refresh in-progress: %w", err)
+	}
+
+	o.Recorder.Eventf(
+		scope.Owner,
+		corev1.EventTypeNormal,
+		bootstrapv1.CertificatesRefreshInProgressEvent,
+		"Certificates refresh in progress. TTL: %s", ttl,
+	)
+	return nil
+}
+
+// updateProgress reports the certificates refresh progress on the owner.
+func (o *Orchestrator) updateProgress(ctx context.Context, scope *Scope, plan RefreshPlan) error {
+	conditions.MarkFalse(scope.Owner, bootstrapv1.CertificatesRefreshedCondition, bootstrapv1.CertificatesRefreshInProgressReason, clusterv1.ConditionSeverityInfo, "Refreshed %d of %d machines", plan.Refreshed, plan.Total)
+	return scope.Patcher.Patch(ctx, scope.Owner)
+}
+
+// markRefreshDone marks the owner as certificates refresh done.
+func (o *Orchestrator) markRefreshDone(ctx context.Context, scope *Scope, plan RefreshPlan) error {
+	conditions.MarkTrue(scope.Owner, bootstrapv1.CertificatesRefreshedCondition)
+	if err := MarkRefreshDone(ctx, scope.Owner, scope.Patcher); err != nil {
+		return fmt.Errorf("failed to mark object with certificates refresh done: %w", err)
+	}
+
+	o.Recorder.Eventf(
+		scope.Owner,
+		corev1.EventTypeNormal,
+		bootstrapv1.CertificatesRefreshDoneEvent,
+		"Certificates refreshed on %d machines",
+		plan.Total,
+	)
+	return nil
+}
+
+// markRefreshFailed marks the owner as certificates refresh failed.
+func (o *Orchestrator) markRefreshFailed(ctx context.Context, scope *Scope, plan RefreshPlan) error {
+	failed := plan.FailedMachineNames()
+	conditions.MarkFalse(scope.Owner, bootstrapv1.CertificatesRefreshedCondition, bootstrapv1.CertificatesRefreshFailedReason, clusterv1.ConditionSeverityError, "Certificates refresh failed for machines %s (refreshed %d of %d machines)", failed, plan.Refreshed, plan.Total)
+	if err := MarkRefreshFailed(ctx, scope.Owner, scope.Patcher); err != nil {
+		return fmt.Errorf("failed to mark object with certificates refresh failed: %w", err)
+	}
+
+	o.Recorder.Eventf(
+		scope.Owner,
+		corev1.EventTypeWarning,
+		bootstrapv1.CertificatesRefreshFailedEvent,
+		"Certificates refresh failed for machines %s",
+		failed,
+	)
+	return nil
+}
+
+// markMachineToRefresh marks the machine to refresh its certificates.
+func (o *Orchestrator) markMachineToRefresh(ctx context.Context, scope *Scope, m *clusterv1.Machine, ttl string) error {
+	if err := MarkMachineToRefresh(ctx, m, ttl, o.Client); err != nil {
+		return fmt.Errorf("failed to mark machine to refresh certificates: %w", err)
+	}
+
+	o.Recorder.Eventf(
+		scope.Owner,
+		corev1.EventTypeNormal,
+		bootstrapv1.CertificatesRefreshInProgressEvent,
+		"Machine %q is refreshing certificates. TTL: %s",
+		m.Name,
+		ttl,
+	)
+
+	return nil
+}
+
+// ReconcileRenewal marks the machines of the owner whose certificates are about to expire for refresh,
+// following the renewal policy.
+func (o *Orchestrator) ReconcileRenewal(ctx context.Context, log logr.Logger, scope *Scope, policy *bootstrapv1.CertificatesRenewalPolicy) (ctrl.Result, error) {
+	if err := ValidateRenewalPolicy(policy); err != nil {
+		log.Info("Invalid certificates renewal policy, skipping certificates renewal", "error", err.Error())
+		conditions.MarkFalse(scope.Owner, bootstrapv1.CertificatesRenewalPolicyValidCondition, bootstrapv1.CertificatesRenewalPolicyInvalidReason, clusterv1.ConditionSeverityError, "%s", err.Error())
+		if err := scope.Patcher.Patch(ctx, scope.Owner); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to patch: %w", err)
+		}
+		// NOTE: Retrying does not help until the policy is fixed. Updates of the owner are reconciled anyway,
+		// the resync picks up policies fixed elsewhere, e.g. in the CK8sConfigTemplate of a MachineDeployment.
+		return ctrl.Result{RequeueAfter: RenewalResyncPeriod}, nil
+	}
+	validated := !conditions.IsTrue(scope.Owner, bootstrapv1.CertificatesRenewalPolicyValidCondition)
+	conditions.MarkTrue(scope.Owner, bootstrapv1.CertificatesRenewalPolicyValidCondition)
+
+	now := time.Now()
+	plan := PlanRenewal(scope.Machines, policy, now)
+
+	open := true
+	if len(plan.ToRenew) > 0 {
+		open = maintenance.Check(scope.Owner, scope.Window, now)
+	}
+	if validated || len(plan.ToRenew) > 0 {
+		if err := scope.Patcher.Patch(ctx, scope.Owner); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to patch: %w", err)
+		}
+	}
+	if !open {
+		log.Info("Outside of the maintenance window, deferring the certificates renewal of the next machines")
+		return ctrl.Result{RequeueAfter: scope.Window.RequeueAfter(now)}, nil
+	}
+
+	for _, m := range plan.ToRenew {
+		if err := MarkMachineToRefresh(ctx, m, policy.TTL, o.Client); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to mark machine to renew certificates: %w", err)
+		}
+
+		expiry, _ := GetMachineExpiry(m)
+		o.Recorder.Eventf(
+			scope.Owner,
+			corev1.EventTypeNormal,
+			bootstrapv1.CertificatesRenewalInProgressEvent,
+			"Machine %q is renewing certificates expiring at %s. TTL: %s",
+			m.Name,
+			expiry.Format(time.RFC3339),
+			policy.TTL,
+		)
+		log.V(1).Info("Machine marked for certificates renewal", "machine", m.Name)
+	}
+
+	if plan.Refreshing > 0 || len(plan.ToRenew) > 0 {
+		return ctrl.Result{RequeueAfter: refreshPollInterval}, nil
+	}
+	return ctrl.Result{RequeueAfter: plan.RequeueAfter(now)}, nil
+}
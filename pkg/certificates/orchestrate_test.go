@@ -0,0 +1,163 @@
+package certificates_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/certificates"
+)
+
+// newOrchestrator returns an orchestrator and the scope of a MachineDeployment with the given annotations,
+// and one machine for each of the given machine annotations.
+func newOrchestrator(g *WithT, annotations map[string]string, machineAnnotations ...map[string]string) (client.Client, *certificates.Orchestrator, *certificates.Scope) {
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	md := &clusterv1.MachineDeployment{}
+	md.Namespace, md.Name = "default", "md"
+	md.Annotations = annotations
+
+	builder := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(md).WithObjects(md)
+	machines := make([]*clusterv1.Machine, 0, len(machineAnnotations))
+	for i, annotations := range machineAnnotations {
+		m := newMachine(string(rune('a'+i)), annotations)
+		m.Namespace = md.Namespace
+		builder = builder.WithObjects(m)
+		machines = append(machines, m)
+	}
+	c := builder.Build()
+
+	patcher, err := patch.NewHelper(md, c)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	orchestrator := &certificates.Orchestrator{Client: c, Recorder: record.NewFakeRecorder(10)}
+	return c, orchestrator, &certificates.Scope{Owner: md, Patcher: patcher, Machines: machines}
+}
+
+func TestOrchestratorReconcileRefresh(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(g *WithT, machineStatuses ...string) (client.Client, *certificates.Orchestrator, *certificates.Scope) {
+		machineAnnotations := make([]map[string]string, 0, len(machineStatuses))
+		for _, status := range machineStatuses {
+			annotations := map[string]string{}
+			if status != "" {
+				annotations[bootstrapv1.CertificatesRefreshStatusAnnotation] = status
+			}
+			machineAnnotations = append(machineAnnotations, annotations)
+		}
+		return newOrchestrator(g, map[string]string{
+			bootstrapv1.CertificatesRefreshAnnotation:            "1y",
+			bootstrapv1.CertificatesRefreshMaxParallelAnnotation: "2",
+		}, machineAnnotations...)
+	}
+
+	t.Run("starts", func(t *testing.T) {
+		g := NewWithT(t)
+		c, orchestrator, scope := setup(g, "", "", "")
+
+		result, err := orchestrator.ReconcileRefresh(ctx, logr.Discard(), scope)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(result.RequeueAfter).ToNot(BeZero())
+
+		md := &clusterv1.MachineDeployment{}
+		g.Expect(c.Get(ctx, client.ObjectKeyFromObject(scope.Owner), md)).To(Succeed())
+		g.Expect(md.Annotations).To(HaveKeyWithValue(bootstrapv1.CertificatesRefreshStatusAnnotation, bootstrapv1.CertificatesRefreshInProgressStatus))
+		g.Expect(conditions.IsFalse(md, bootstrapv1.CertificatesRefreshedCondition)).To(BeTrue())
+
+		marked := 0
+		for _, m := range scope.Machines {
+			got := &clusterv1.Machine{}
+			g.Expect(c.Get(ctx, client.ObjectKeyFromObject(m), got)).To(Succeed())
+			if got.Annotations[bootstrapv1.CertificatesRefreshAnnotation] == "1y" {
+				marked++
+			}
+		}
+		g.Expect(marked).To(Equal(2))
+	})
+
+	t.Run("done", func(t *testing.T) {
+		g := NewWithT(t)
+		c, orchestrator, scope := setup(g, bootstrapv1.CertificatesRefreshDoneStatus, bootstrapv1.CertificatesRefreshDoneStatus)
+		scope.Owner.GetAnnotations()[bootstrapv1.CertificatesRefreshStatusAnnotation] = bootstrapv1.CertificatesRefreshInProgressStatus
+
+		result, err := orchestrator.ReconcileRefresh(ctx, logr.Discard(), scope)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(result.RequeueAfter).To(BeZero())
+
+		md := &clusterv1.MachineDeployment{}
+		g.Expect(c.Get(ctx, client.ObjectKeyFromObject(scope.Owner), md)).To(Succeed())
+		g.Expect(md.Annotations).ToNot(HaveKey(bootstrapv1.CertificatesRefreshAnnotation))
+		g.Expect(md.Annotations).To(HaveKeyWithValue(bootstrapv1.CertificatesRefreshStatusAnnotation, bootstrapv1.CertificatesRefreshDoneStatus))
+		g.Expect(conditions.IsTrue(md, bootstrapv1.CertificatesRefreshedCondition)).To(BeTrue())
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		g := NewWithT(t)
+		c, orchestrator, scope := setup(g, bootstrapv1.CertificatesRefreshDoneStatus, bootstrapv1.CertificatesRefreshFailedStatus)
+		scope.Owner.GetAnnotations()[bootstrapv1.CertificatesRefreshStatusAnnotation] = bootstrapv1.CertificatesRefreshInProgressStatus
+
+		result, err := orchestrator.ReconcileRefresh(ctx, logr.Discard(), scope)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(result.RequeueAfter).To(BeZero())
+
+		md := &clusterv1.MachineDeployment{}
+		g.Expect(c.Get(ctx, client.ObjectKeyFromObject(scope.Owner), md)).To(Succeed())
+		g.Expect(md.Annotations).To(HaveKeyWithValue(bootstrapv1.CertificatesRefreshStatusAnnotation, bootstrapv1.CertificatesRefreshFailedStatus))
+		g.Expect(conditions.GetReason(md, bootstrapv1.CertificatesRefreshedCondition)).To(Equal(bootstrapv1.CertificatesRefreshFailedReason))
+	})
+}
+
+func TestOrchestratorReconcileRenewal(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	expiresAt := func(d time.Duration) map[string]string {
+		return map[string]string{bootstrapv1.MachineCertificatesExpiryDateAnnotation: now.Add(d).Format(time.RFC3339)}
+	}
+	c, orchestrator, scope := newOrchestrator(g, nil, expiresAt(time.Hour), expiresAt(100*24*time.Hour))
+	policy := &bootstrapv1.CertificatesRenewalPolicy{TTL: "1y", RenewBefore: metav1.Duration{Duration: 24 * time.Hour}}
+
+	result, err := orchestrator.ReconcileRenewal(ctx, logr.Discard(), scope, policy)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeNumerically("<=", time.Minute))
+
+	for name, expectRenewal := range map[string]bool{"a": true, "b": false} {
+		m := &clusterv1.Machine{}
+		g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, m)).To(Succeed())
+		if expectRenewal {
+			g.Expect(m.Annotations).To(HaveKeyWithValue(bootstrapv1.CertificatesRefreshAnnotation, "1y"))
+		} else {
+			g.Expect(m.Annotations).ToNot(HaveKey(bootstrapv1.CertificatesRefreshAnnotation))
+		}
+	}
+
+	md := &clusterv1.MachineDeployment{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(scope.Owner), md)).To(Succeed())
+	g.Expect(conditions.IsTrue(md, bootstrapv1.CertificatesRenewalPolicyValidCondition)).To(BeTrue())
+
+	scope.Owner = md
+	scope.Patcher, err = patch.NewHelper(md, c)
+	g.Expect(err).ToNot(HaveOccurred())
+	result, err = orchestrator.ReconcileRenewal(ctx, logr.Discard(), scope, &bootstrapv1.CertificatesRenewalPolicy{TTL: "1h", RenewBefore: metav1.Duration{Duration: 24 * time.Hour}})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(certificates.RenewalResyncPeriod))
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(scope.Owner), md)).To(Succeed())
+	g.Expect(conditions.IsFalse(md, bootstrapv1.CertificatesRenewalPolicyValidCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(md, bootstrapv1.CertificatesRenewalPolicyValidCondition)).To(Equal(bootstrapv1.CertificatesRenewalPolicyInvalidReason))
+}
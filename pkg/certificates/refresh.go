@@ -0,0 +1,131 @@
+package certificates
+
+import (
+	"strconv"
+	"strings"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+)
+
+// DefaultRefreshMaxParallel is the number of machines refreshed at the same time
+// when the owner object does not specify otherwise.
+const DefaultRefreshMaxParallel = 1
+
+// GetRefreshTTL returns the certificates refresh TTL requested on the object.
+func GetRefreshTTL(obj client.Object) string {
+	return obj.GetAnnotations()[bootstrapv1.CertificatesRefreshAnnotation]
+}
+
+// GetRefreshMaxParallel returns the number of machines that can refresh their certificates at the same time.
+// Missing or invalid values fall back to DefaultRefreshMaxParallel.
+func GetRefreshMaxParallel(obj client.Object) int {
+	v, ok := obj.GetAnnotations()[bootstrapv1.CertificatesRefreshMaxParallelAnnotation]
+	if !ok {
+		return DefaultRefreshMaxParallel
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return DefaultRefreshMaxParallel
+	}
+
+	return n
+}
+
+// IsRefreshInProgress checks if the object is marked with a certificates refresh in-progress.
+func IsRefreshInProgress(obj client.Object) bool {
+	return obj.GetAnnotations()[bootstrapv1.CertificatesRefreshStatusAnnotation] == bootstrapv1.CertificatesRefreshInProgressStatus
+}
+
+// IsMachineRefreshed checks if the machine certificates were refreshed and no refresh is pending.
+func IsMachineRefreshed(m *clusterv1.Machine) bool {
+	_, pending := m.Annotations[bootstrapv1.CertificatesRefreshAnnotation]
+	return !pending && m.Annotations[bootstrapv1.CertificatesRefreshStatusAnnotation] == bootstrapv1.CertificatesRefreshDoneStatus
+}
+
+// IsMachineRefreshFailed checks if the last certificates refresh of the machine failed.
+func IsMachineRefreshFailed(m *clusterv1.Machine) bool {
+	return m.Annotations[bootstrapv1.CertificatesRefreshStatusAnnotation] == bootstrapv1.CertificatesRefreshFailedStatus
+}
+
+// IsMachineRefreshing checks if the machine has a certificates refresh pending or in-progress.
+func IsMachineRefreshing(m *clusterv1.Machine) bool {
+	_, pending := m.Annotations[bootstrapv1.CertificatesRefreshAnnotation]
+	return pending || m.Annotations[bootstrapv1.CertificatesRefreshStatusAnnotation] == bootstrapv1.CertificatesRefreshInProgressStatus
+}
+
+// RefreshPlan describes the state of an orchestrated certificates refresh over a set of machines.
+type RefreshPlan struct {
+	// Total is the number of machines taking part in the refresh.
+	Total int
+	// Refreshed is the number of machines whose certificates were refreshed.
+	Refreshed int
+	// Refreshing is the number of machines with a refresh pending or in-progress.
+	Refreshing int
+	// Failed are the machines whose certificates refresh failed.
+	Failed []*clusterv1.Machine
+	// ToRefresh are the machines that should be marked for refresh next.
+	ToRefresh []*clusterv1.Machine
+}
+
+// Done returns true if all the machines were refreshed.
+func (p RefreshPlan) Done() bool {
+	return p.Refreshed == p.Total
+}
+
+// FailedMachineNames returns a comma separated list of the machines whose certificates refresh failed.
+func (p RefreshPlan) FailedMachineNames() string {
+	names := make([]string, 0, len(p.Failed))
+	for _, m := range p.Failed {
+		names = append(names, m.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// PlanRefresh walks the machines in order and decides which ones should be marked for refresh next,
+// never exceeding maxParallel machines refreshing at the same time.
+// Machines that are being deleted are not part of the refresh.
+func PlanRefresh(machines []*clusterv1.Machine, maxParallel int) RefreshPlan {
+	if maxParallel < 1 {
+		maxParallel = DefaultRefreshMaxParallel
+	}
+
+	var plan RefreshPlan
+	var candidates []*clusterv1.Machine
+	for _, m := range machines {
+		if !m.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		plan.Total++
+		switch {
+		case IsMachineRefreshed(m):
+			plan.Refreshed++
+		case IsMachineRefreshFailed(m):
+			plan.Failed = append(plan.Failed, m)
+		case IsMachineRefreshing(m):
+			plan.Refreshing++
+		default:
+			candidates = append(candidates, m)
+		}
+	}
+
+	// NOTE: A failure halts the whole refresh, so we don't pick any new machines.
+	if len(plan.Failed) > 0 {
+		return plan
+	}
+
+	budget := maxParallel - plan.Refreshing
+	for _, m := range candidates {
+		if budget <= 0 {
+			break
+		}
+		plan.ToRefresh = append(plan.ToRefresh, m)
+		budget--
+	}
+
+	return plan
+}
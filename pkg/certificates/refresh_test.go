@@ -0,0 +1,141 @@
+package certificates_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/certificates"
+)
+
+func newMachine(name string, annotations map[string]string) *clusterv1.Machine {
+	return &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: annotations,
+		},
+	}
+}
+
+func machineNames(machines []*clusterv1.Machine) []string {
+	names := make([]string, 0, len(machines))
+	for _, m := range machines {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+func TestGetRefreshMaxParallel(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		annotations map[string]string
+		expected    int
+	}{
+		{
+			name:        "missing",
+			annotations: map[string]string{},
+			expected:    certificates.DefaultRefreshMaxParallel,
+		},
+		{
+			name:        "valid",
+			annotations: map[string]string{bootstrapv1.CertificatesRefreshMaxParallelAnnotation: "3"},
+			expected:    3,
+		},
+		{
+			name:        "invalid",
+			annotations: map[string]string{bootstrapv1.CertificatesRefreshMaxParallelAnnotation: "many"},
+			expected:    certificates.DefaultRefreshMaxParallel,
+		},
+		{
+			name:        "zero",
+			annotations: map[string]string{bootstrapv1.CertificatesRefreshMaxParallelAnnotation: "0"},
+			expected:    certificates.DefaultRefreshMaxParallel,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(certificates.GetRefreshMaxParallel(newMachine("m", tc.annotations))).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestPlanRefresh(t *testing.T) {
+	done := map[string]string{bootstrapv1.CertificatesRefreshStatusAnnotation: bootstrapv1.CertificatesRefreshDoneStatus}
+	failed := map[string]string{bootstrapv1.CertificatesRefreshStatusAnnotation: bootstrapv1.CertificatesRefreshFailedStatus}
+	inProgress := map[string]string{bootstrapv1.CertificatesRefreshStatusAnnotation: bootstrapv1.CertificatesRefreshInProgressStatus}
+	pending := map[string]string{bootstrapv1.CertificatesRefreshAnnotation: "1y"}
+
+	deleting := newMachine("deleting", nil)
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+
+	for _, tc := range []struct {
+		name            string
+		machines        []*clusterv1.Machine
+		maxParallel     int
+		expectTotal     int
+		expectDone      bool
+		expectFailed    []string
+		expectToRefresh []string
+	}{
+		{
+			name:            "noneRefreshed",
+			machines:        []*clusterv1.Machine{newMachine("a", nil), newMachine("b", nil), newMachine("c", nil)},
+			maxParallel:     1,
+			expectTotal:     3,
+			expectToRefresh: []string{"a"},
+		},
+		{
+			name:            "parallel",
+			machines:        []*clusterv1.Machine{newMachine("a", nil), newMachine("b", nil), newMachine("c", nil)},
+			maxParallel:     2,
+			expectTotal:     3,
+			expectToRefresh: []string{"a", "b"},
+		},
+		{
+			name:            "budgetTakenByRefreshing",
+			machines:        []*clusterv1.Machine{newMachine("a", pending), newMachine("b", inProgress), newMachine("c", nil)},
+			maxParallel:     2,
+			expectTotal:     3,
+			expectToRefresh: []string{},
+		},
+		{
+			name:            "continuesAfterRefreshed",
+			machines:        []*clusterv1.Machine{newMachine("a", done), newMachine("b", nil)},
+			maxParallel:     1,
+			expectTotal:     2,
+			expectToRefresh: []string{"b"},
+		},
+		{
+			name:            "allRefreshed",
+			machines:        []*clusterv1.Machine{newMachine("a", done), newMachine("b", done), deleting},
+			maxParallel:     1,
+			expectTotal:     2,
+			expectDone:      true,
+			expectToRefresh: []string{},
+		},
+		{
+			name:            "haltsOnFailure",
+			machines:        []*clusterv1.Machine{newMachine("a", done), newMachine("b", failed), newMachine("c", nil)},
+			maxParallel:     3,
+			expectTotal:     3,
+			expectFailed:    []string{"b"},
+			expectToRefresh: []string{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			plan := certificates.PlanRefresh(tc.machines, tc.maxParallel)
+
+			g.Expect(plan.Total).To(Equal(tc.expectTotal))
+			g.Expect(plan.Done()).To(Equal(tc.expectDone))
+			g.Expect(machineNames(plan.Failed)).To(ConsistOf(tc.expectFailed))
+			g.Expect(machineNames(plan.ToRefresh)).To(Equal(tc.expectToRefresh))
+		})
+	}
+}
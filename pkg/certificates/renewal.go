@@ -0,0 +1,109 @@
+package certificates
+
+import (
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+)
+
+// RenewalResyncPeriod is the longest time between two checks of the certificates expiry of machines
+// with a renewal policy. This picks up the expiry of machines that did not record it yet.
+const RenewalResyncPeriod = time.Hour
+
+// ValidateRenewalPolicy checks that the renewal policy can renew certificates before they expire.
+func ValidateRenewalPolicy(policy *bootstrapv1.CertificatesRenewalPolicy) error {
+	return policy.Validate(field.NewPath("certificatesRenewal")).ToAggregate()
+}
+
+// GetRenewalMaxParallel returns the number of machines that can renew their certificates at the same time.
+func GetRenewalMaxParallel(policy *bootstrapv1.CertificatesRenewalPolicy) int {
+	if policy.MaxParallel == nil || *policy.MaxParallel < 1 {
+		return DefaultRefreshMaxParallel
+	}
+	return int(*policy.MaxParallel)
+}
+
+// GetMachineExpiry returns when the certificates of the machine expire, as recorded on the machine.
+func GetMachineExpiry(m *clusterv1.Machine) (time.Time, bool) {
+	expiry, err := time.Parse(time.RFC3339, m.Annotations[bootstrapv1.MachineCertificatesExpiryDateAnnotation])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expiry, true
+}
+
+// RenewalPlan describes which machines should renew their certificates following a renewal policy.
+type RenewalPlan struct {
+	// Refreshing is the number of machines with a refresh pending or in-progress.
+	Refreshing int
+	// ToRenew are the machines that should be marked for refresh next, soonest expiry first.
+	ToRenew []*clusterv1.Machine
+	// NextRenewal is when the next machine not yet due becomes due for renewal.
+	// It is the zero time if no such machine is known.
+	NextRenewal time.Time
+}
+
+// PlanRenewal decides which machines should renew their certificates because they expire within
+// the renewBefore duration of the policy, never exceeding its maxParallel machines refreshing at the same time.
+// Machines that are being deleted or did not record the expiry of their certificates are skipped.
+func PlanRenewal(machines []*clusterv1.Machine, policy *bootstrapv1.CertificatesRenewalPolicy, now time.Time) RenewalPlan {
+	type candidate struct {
+		machine *clusterv1.Machine
+		expiry  time.Time
+	}
+
+	var plan RenewalPlan
+	var candidates []candidate
+	for _, m := range machines {
+		if !m.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		if IsMachineRefreshing(m) {
+			plan.Refreshing++
+			continue
+		}
+
+		expiry, ok := GetMachineExpiry(m)
+		if !ok {
+			continue
+		}
+
+		if due := expiry.Add(-policy.RenewBefore.Duration); due.After(now) {
+			if plan.NextRenewal.IsZero() || due.Before(plan.NextRenewal) {
+				plan.NextRenewal = due
+			}
+			continue
+		}
+
+		candidates = append(candidates, candidate{machine: m, expiry: expiry})
+	}
+
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return a.expiry.Compare(b.expiry)
+	})
+
+	budget := GetRenewalMaxParallel(policy) - plan.Refreshing
+	for _, c := range candidates {
+		if budget <= 0 {
+			break
+		}
+		plan.ToRenew = append(plan.ToRenew, c.machine)
+		budget--
+	}
+
+	return plan
+}
+
+// RequeueAfter returns how long to wait before checking the renewal of the machines again,
+// when none of them is refreshing.
+func (p RenewalPlan) RequeueAfter(now time.Time) time.Duration {
+	if p.NextRenewal.IsZero() {
+		return RenewalResyncPeriod
+	}
+	return min(max(p.NextRenewal.Sub(now), time.Second), RenewalResyncPeriod)
+}
@@ -0,0 +1,132 @@
+package certificates_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/certificates"
+)
+
+func TestValidateRenewalPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		renewBefore time.Duration
+		ttl         string
+		expectErr   bool
+	}{
+		{
+			name:        "valid",
+			renewBefore: 30 * 24 * time.Hour,
+			ttl:         "1y",
+		},
+		{
+			name:      "missingRenewBefore",
+			ttl:       "1y",
+			expectErr: true,
+		},
+		{
+			name:        "invalidTTL",
+			renewBefore: time.Hour,
+			ttl:         "forever",
+			expectErr:   true,
+		},
+		{
+			name:        "ttlShorterThanRenewBefore",
+			renewBefore: 48 * time.Hour,
+			ttl:         "1d",
+			expectErr:   true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := certificates.ValidateRenewalPolicy(&bootstrapv1.CertificatesRenewalPolicy{
+				RenewBefore: metav1.Duration{Duration: tc.renewBefore},
+				TTL:         tc.ttl,
+			})
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestPlanRenewal(t *testing.T) {
+	now := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	expiresIn := func(d time.Duration) map[string]string {
+		return map[string]string{bootstrapv1.MachineCertificatesExpiryDateAnnotation: now.Add(d).Format(time.RFC3339)}
+	}
+	refreshing := map[string]string{bootstrapv1.CertificatesRefreshAnnotation: "1y"}
+	day := 24 * time.Hour
+
+	for _, tc := range []struct {
+		name              string
+		machines          []*clusterv1.Machine
+		maxParallel       *int32
+		expectRefreshing  int
+		expectToRenew     []string
+		expectNextRenewal time.Time
+	}{
+		{
+			name:              "noneDue",
+			machines:          []*clusterv1.Machine{newMachine("a", expiresIn(60*day)), newMachine("b", expiresIn(40*day))},
+			expectToRenew:     []string{},
+			expectNextRenewal: now.Add(10 * day),
+		},
+		{
+			name:          "soonestFirst",
+			machines:      []*clusterv1.Machine{newMachine("a", expiresIn(20*day)), newMachine("b", expiresIn(-day)), newMachine("c", expiresIn(60*day))},
+			expectToRenew: []string{"b"},
+			// NOTE: a is due as well, but the budget is taken by b.
+			expectNextRenewal: now.Add(30 * day),
+		},
+		{
+			name:          "parallel",
+			machines:      []*clusterv1.Machine{newMachine("a", expiresIn(20*day)), newMachine("b", expiresIn(10*day))},
+			maxParallel:   ptr.To[int32](2),
+			expectToRenew: []string{"b", "a"},
+		},
+		{
+			name:             "budgetTakenByRefreshing",
+			machines:         []*clusterv1.Machine{newMachine("a", refreshing), newMachine("b", expiresIn(day))},
+			expectRefreshing: 1,
+			expectToRenew:    []string{},
+		},
+		{
+			name:          "unknownExpiry",
+			machines:      []*clusterv1.Machine{newMachine("a", nil)},
+			expectToRenew: []string{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			plan := certificates.PlanRenewal(tc.machines, &bootstrapv1.CertificatesRenewalPolicy{
+				RenewBefore: metav1.Duration{Duration: 30 * day},
+				TTL:         "1y",
+				MaxParallel: tc.maxParallel,
+			}, now)
+
+			g.Expect(plan.Refreshing).To(Equal(tc.expectRefreshing))
+			g.Expect(machineNames(plan.ToRenew)).To(Equal(tc.expectToRenew))
+			g.Expect(plan.NextRenewal).To(Equal(tc.expectNextRenewal))
+		})
+	}
+}
+
+func TestRenewalPlanRequeueAfter(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now()
+
+	g.Expect(certificates.RenewalPlan{}.RequeueAfter(now)).To(Equal(certificates.RenewalResyncPeriod))
+	g.Expect(certificates.RenewalPlan{NextRenewal: now.Add(time.Minute)}.RequeueAfter(now)).To(Equal(time.Minute))
+	g.Expect(certificates.RenewalPlan{NextRenewal: now.Add(48 * time.Hour)}.RequeueAfter(now)).To(Equal(certificates.RenewalResyncPeriod))
+}
@@ -0,0 +1,164 @@
+package certificates
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/secret"
+	utiltime "github.com/canonical/cluster-api-k8s/pkg/time"
+)
+
+// RefreshValidator validates the certificates refresh annotations of machines and of the objects
+// owning them, so that malformed requests are rejected on admission instead of failing the refresh.
+type RefreshValidator struct {
+	Client client.Reader
+}
+
+var _ admission.CustomValidator = &RefreshValidator{}
+
+// ValidateCreate validates the certificates refresh annotations of a new object.
+func (v *RefreshValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	o, ok := obj.(client.Object)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a client.Object but got a %T", obj))
+	}
+
+	// NOTE: New machines have no node yet, the node is only required when refreshing existing machines.
+	return nil, v.validate(ctx, nil, o)
+}
+
+// ValidateUpdate validates the certificates refresh annotations added or changed on an object.
+func (v *RefreshValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldO, ok := oldObj.(client.Object)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a client.Object but got a %T", oldObj))
+	}
+	newO, ok := newObj.(client.Object)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a client.Object but got a %T", newObj))
+	}
+
+	return nil, v.validate(ctx, oldO, newO)
+}
+
+// ValidateDelete allows the deletion of any object.
+func (v *RefreshValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate checks the certificates refresh annotations of obj that differ from the ones of old.
+// Annotations left unchanged are not validated again, so that existing objects can still be updated.
+func (v *RefreshValidator) validate(ctx context.Context, old, obj client.Object) error {
+	var oldAnnotations map[string]string
+	if old != nil {
+		oldAnnotations = old.GetAnnotations()
+	}
+	annotations := obj.GetAnnotations()
+	annotationsPath := field.NewPath("metadata", "annotations")
+
+	var allErrs field.ErrorList
+	maxParallel, ok := annotations[bootstrapv1.CertificatesRefreshMaxParallelAnnotation]
+	if ok && maxParallel != oldAnnotations[bootstrapv1.CertificatesRefreshMaxParallelAnnotation] {
+		if n, err := strconv.Atoi(maxParallel); err != nil || n < 1 {
+			allErrs = append(allErrs, field.Invalid(annotationsPath.Key(bootstrapv1.CertificatesRefreshMaxParallelAnnotation), maxParallel, "must be a positive integer"))
+		}
+	}
+
+	ttl, ok := annotations[bootstrapv1.CertificatesRefreshAnnotation]
+	if ok && (old == nil || ttl != oldAnnotations[bootstrapv1.CertificatesRefreshAnnotation]) {
+		ttlPath := annotationsPath.Key(bootstrapv1.CertificatesRefreshAnnotation)
+
+		if m, isMachine := obj.(*clusterv1.Machine); isMachine && old != nil && m.Status.NodeRef == nil {
+			allErrs = append(allErrs, field.Forbidden(ttlPath, "the certificates of a machine without a node cannot be refreshed"))
+		}
+
+		caExpiry, err := v.getClusterCAExpiry(ctx, obj)
+		if err != nil {
+			return apierrors.NewInternalError(err)
+		}
+		if err := ValidateRefreshTTL(ttl, caExpiry, time.Now()); err != nil {
+			allErrs = append(allErrs, field.Invalid(ttlPath, ttl, err.Error()))
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	return apierrors.NewInvalid(gvk.GroupKind(), obj.GetName(), allErrs)
+}
+
+// getClusterCAExpiry returns when the certificate authority of the cluster of the object expires.
+// It returns the zero time if the certificate authority of the cluster does not exist yet.
+func (v *RefreshValidator) getClusterCAExpiry(ctx context.Context, obj client.Object) (time.Time, error) {
+	clusterName := getClusterName(obj)
+	if clusterName == "" {
+		return time.Time{}, nil
+	}
+
+	s, err := secret.GetFromNamespacedName(ctx, v.Client, client.ObjectKey{Namespace: obj.GetNamespace(), Name: clusterName}, secret.ClusterCA)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("failed to get certificate authority of cluster %s: %w", clusterName, err)
+	}
+
+	cert, err := certs.DecodeCertPEM(s.Data[secret.TLSCrtDataName])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode certificate authority of cluster %s: %w", clusterName, err)
+	} else if cert == nil {
+		return time.Time{}, nil
+	}
+	return cert.NotAfter, nil
+}
+
+// ValidateRefreshTTL checks that the certificates refresh TTL is well-formed and that certificates
+// refreshed now with it would not outlive the certificate authority expiring at caExpiry.
+// The lifetime of the certificate authority is not checked if caExpiry is the zero time.
+func ValidateRefreshTTL(ttl string, caExpiry time.Time, now time.Time) error {
+	seconds, err := utiltime.TTLToSeconds(ttl)
+	if err != nil {
+		return fmt.Errorf("invalid ttl: %w", err)
+	}
+	if seconds <= 0 {
+		return fmt.Errorf("ttl must be positive")
+	}
+
+	if expiry := utiltime.SecondsToExpirationDate(now, seconds); !caExpiry.IsZero() && expiry.After(caExpiry) {
+		return fmt.Errorf("certificates would expire at %s, after the certificate authority of the cluster at %s",
+			expiry.Format(time.RFC3339), caExpiry.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// getClusterName returns the name of the cluster the object belongs to.
+func getClusterName(obj client.Object) string {
+	switch o := obj.(type) {
+	case *clusterv1.Machine:
+		return o.Spec.ClusterName
+	case *clusterv1.MachineDeployment:
+		return o.Spec.ClusterName
+	case *controlplanev1.CK8sControlPlane:
+		for _, ref := range o.OwnerReferences {
+			if ref.Kind == "Cluster" {
+				return ref.Name
+			}
+		}
+	}
+	return obj.GetLabels()[clusterv1.ClusterNameLabel]
+}
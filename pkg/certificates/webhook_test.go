@@ -0,0 +1,129 @@
+package certificates_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/certificates"
+	"github.com/canonical/cluster-api-k8s/pkg/secret"
+)
+
+func TestValidateRefreshTTL(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		name        string
+		ttl         string
+		caExpiry    time.Time
+		expectValid bool
+	}{
+		{name: "days", ttl: "90d", caExpiry: now.AddDate(1, 0, 0), expectValid: true},
+		{name: "hours", ttl: "24h", caExpiry: now.AddDate(1, 0, 0), expectValid: true},
+		{name: "unknown ca", ttl: "20y", expectValid: true},
+		{name: "malformed", ttl: "1x"},
+		{name: "empty", ttl: ""},
+		{name: "zero", ttl: "0d"},
+		{name: "negative", ttl: "-1y"},
+		{name: "outlives ca", ttl: "2y", caExpiry: now.AddDate(1, 0, 0)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := certificates.ValidateRefreshTTL(tc.ttl, tc.caExpiry, now)
+			if tc.expectValid {
+				g.Expect(err).ToNot(HaveOccurred())
+			} else {
+				g.Expect(err).To(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestRefreshValidator(t *testing.T) {
+	ctx := context.Background()
+	clusterName := client.ObjectKey{Namespace: "default", Name: "cluster"}
+
+	cas := secret.NewCertificatesForInitialControlPlane(&bootstrapv1.CK8sConfigSpec{})
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	NewWithT(t).Expect(cas.LookupOrGenerate(ctx, c, clusterName, metav1.OwnerReference{})).To(Succeed())
+
+	validator := &certificates.RefreshValidator{Client: c}
+	machineWithTTL := func(ttl string, hasNode bool) client.Object {
+		m := newMachine("machine", nil)
+		m.Namespace = clusterName.Namespace
+		m.Spec.ClusterName = clusterName.Name
+		if ttl != "" {
+			m.Annotations = map[string]string{bootstrapv1.CertificatesRefreshAnnotation: ttl}
+		}
+		if hasNode {
+			m.Status.NodeRef = &corev1.ObjectReference{Name: "node"}
+		}
+		return m
+	}
+
+	t.Run("create", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := validator.ValidateCreate(ctx, machineWithTTL("1y", false))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		_, err = validator.ValidateCreate(ctx, machineWithTTL("1x", false))
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	})
+
+	t.Run("refresh", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := validator.ValidateUpdate(ctx, machineWithTTL("", true), machineWithTTL("1y", true))
+		g.Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("malformed ttl", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := validator.ValidateUpdate(ctx, machineWithTTL("", true), machineWithTTL("1x", true))
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		g.Expect(err.Error()).To(ContainSubstring(bootstrapv1.CertificatesRefreshAnnotation))
+	})
+
+	t.Run("ttl outlives ca", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := validator.ValidateUpdate(ctx, machineWithTTL("", true), machineWithTTL("20y", true))
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		g.Expect(err.Error()).To(ContainSubstring("certificate authority"))
+	})
+
+	t.Run("machine without node", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := validator.ValidateUpdate(ctx, machineWithTTL("", false), machineWithTTL("1y", false))
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		g.Expect(err.Error()).To(ContainSubstring("without a node"))
+	})
+
+	t.Run("unchanged ttl", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := validator.ValidateUpdate(ctx, machineWithTTL("1x", false), machineWithTTL("1x", false))
+		g.Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("invalid max parallel", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := machineWithTTL("", true)
+		m.SetAnnotations(map[string]string{bootstrapv1.CertificatesRefreshMaxParallelAnnotation: "0"})
+		_, err := validator.ValidateUpdate(ctx, machineWithTTL("", true), m)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	})
+}
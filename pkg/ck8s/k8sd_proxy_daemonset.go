@@ -3,7 +3,24 @@ package ck8s
 import (
 	"bytes"
 	_ "embed"
+	"fmt"
+	"strings"
 	"text/template"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
+)
+
+const (
+	// defaultK8sdProxyImageRegistry is the registry of the default k8sd-proxy image.
+	defaultK8sdProxyImageRegistry = "ghcr.io"
+	// defaultK8sdProxyImageName is the default k8sd-proxy image, without its registry.
+	defaultK8sdProxyImageName = "canonical/cluster-api-k8s/socat:1.8.0.0"
 )
 
 var (
@@ -15,14 +32,98 @@ var (
 
 type K8sdProxyDaemonSetInput struct {
 	K8sdPort int
+
+	// Image is the image of the k8sd-proxy containers. Defaults to the socat image released with the provider.
+	Image string
+	// Resources are the compute resources of the k8sd-proxy containers.
+	Resources *corev1.ResourceRequirements
+	// Tolerations are added to the default tolerations of the k8sd-proxy pods.
+	Tolerations []corev1.Toleration
+	// PriorityClassName is the priority class of the k8sd-proxy pods.
+	PriorityClassName string
 }
 
-// RenderK8sdProxyDaemonSet renders the manifest for the k8sd-proxy daemonset based on supplied configuration.
-func RenderK8sdProxyDaemonSetManifest(input K8sdProxyDaemonSetInput) ([]byte, error) {
+// NewK8sdProxyDaemonSetInput returns the input to render the k8sd-proxy daemonset configured on the control plane.
+func NewK8sdProxyDaemonSetInput(microclusterPort int, spec *controlplanev1.K8sdProxySpec) K8sdProxyDaemonSetInput {
+	input := K8sdProxyDaemonSetInput{K8sdPort: microclusterPort}
+	if spec == nil {
+		return input
+	}
+
+	input.Image = spec.Image
+	if input.Image == "" && spec.ImageRegistry != "" {
+		input.Image = fmt.Sprintf("%s/%s", strings.TrimSuffix(spec.ImageRegistry, "/"), defaultK8sdProxyImageName)
+	}
+	input.Resources = spec.Resources
+	input.Tolerations = spec.Tolerations
+	input.PriorityClassName = spec.PriorityClassName
+	return input
+}
+
+// RenderK8sdProxyObjects renders the configmap and the daemonset of k8sd-proxy based on supplied configuration.
+func RenderK8sdProxyObjects(input K8sdProxyDaemonSetInput) (*corev1.ConfigMap, *appsv1.DaemonSet, error) {
+	if input.Image == "" {
+		input.Image = fmt.Sprintf("%s/%s", defaultK8sdProxyImageRegistry, defaultK8sdProxyImageName)
+	}
+
 	var b bytes.Buffer
 	if err := k8sdProxyDaemonSetTemplate.Execute(&b, input); err != nil {
+		return nil, nil, err
+	}
+
+	docs := strings.Split(b.String(), "\n---\n")
+	if len(docs) != 2 {
+		return nil, nil, fmt.Errorf("expected 2 k8sd-proxy manifests, got %d", len(docs))
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := yaml.UnmarshalStrict([]byte(docs[0]), configMap); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse k8sd-proxy configmap: %w", err)
+	}
+	daemonSet := &appsv1.DaemonSet{}
+	if err := yaml.UnmarshalStrict([]byte(docs[1]), daemonSet); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse k8sd-proxy daemonset: %w", err)
+	}
+
+	podSpec := &daemonSet.Spec.Template.Spec
+	podSpec.Tolerations = append(podSpec.Tolerations, input.Tolerations...)
+	podSpec.PriorityClassName = input.PriorityClassName
+	if input.Resources != nil {
+		for i := range podSpec.Containers {
+			podSpec.Containers[i].Resources = *input.Resources.DeepCopy()
+		}
+	}
+
+	return configMap, daemonSet, nil
+}
+
+// RenderK8sdProxyDaemonSet renders the manifest for the k8sd-proxy daemonset based on supplied configuration.
+func RenderK8sdProxyDaemonSetManifest(input K8sdProxyDaemonSetInput) ([]byte, error) {
+	configMap, daemonSet, err := RenderK8sdProxyObjects(input)
+	if err != nil {
 		return nil, err
 	}
 
+	var b bytes.Buffer
+	for i, obj := range []runtime.Object{configMap, daemonSet} {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert k8sd-proxy manifest: %w", err)
+		}
+		// NOTE: Drop the fields that are only set by the API server.
+		unstructured.RemoveNestedField(u, "metadata", "creationTimestamp")
+		unstructured.RemoveNestedField(u, "spec", "template", "metadata", "creationTimestamp")
+		unstructured.RemoveNestedField(u, "status")
+
+		manifest, err := yaml.Marshal(u)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal k8sd-proxy manifest: %w", err)
+		}
+		if i > 0 {
+			b.WriteString("---\n")
+		}
+		b.Write(manifest)
+	}
+
 	return b.Bytes(), nil
 }
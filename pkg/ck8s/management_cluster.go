@@ -3,14 +3,20 @@ package ck8s
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	"sigs.k8s.io/cluster-api/util/collections"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
+	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
 	"github.com/canonical/cluster-api-k8s/pkg/token"
 )
 
@@ -89,6 +95,27 @@ func (m *Management) GetWorkloadCluster(ctx context.Context, clusterKey client.O
 		return nil, &RemoteClusterConnectionError{Name: clusterKey.String(), Err: err}
 	}
 
+	// NOTE: The workload cluster is still reachable without caching the k8sd clients, only more expensively.
+	if g.cache, err = getK8sdClientCache(clusterKey, restConfig); err != nil {
+		log.FromContext(ctx).Info("Failed to cache k8sd clients of the workload cluster", "cluster", clusterKey, "error", err.Error())
+	}
+
+	connection, err := m.getK8sdConnection(ctx, clusterKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get k8sd connection: %w", err)
+	}
+	if connection.GetMode() == controlplanev1.K8sdConnectionModeDirect {
+		credentials, err := m.getBastionCredentials(ctx, clusterKey.Namespace, connection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bastion credentials: %w", err)
+		}
+		if err := g.setDirect(connection.Bastion, credentials); err != nil {
+			return nil, err
+		}
+	}
+	g.allowUnverified = connection.GetAllowUnverifiedCertificates()
+	g.microclusterPort = microclusterPort
+
 	authToken, err := token.Lookup(ctx, m.Client, clusterKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to lookup auth token: %w", err)
@@ -99,6 +126,7 @@ func (m *Management) GetWorkloadCluster(ctx context.Context, clusterKey client.O
 	}
 
 	workload := &Workload{
+		clusterKey:          clusterKey,
 		authToken:           *authToken,
 		Client:              c,
 		ClientRestConfig:    restConfig,
@@ -109,4 +137,72 @@ func (m *Management) GetWorkloadCluster(ctx context.Context, clusterKey client.O
 	return workload, nil
 }
 
+// getK8sdConnection returns how k8sd is reached on the machines of the cluster, as configured on its control plane.
+func (m *Management) getK8sdConnection(ctx context.Context, clusterKey client.ObjectKey) (*controlplanev1.K8sdConnectionSpec, error) {
+	connection := &controlplanev1.K8sdConnectionSpec{}
+	if found, err := getControlPlaneSpecField(ctx, m.Client, clusterKey, "k8sdConnection", connection); err != nil || !found {
+		return nil, err
+	}
+	return connection, nil
+}
+
+// getBastionCredentials returns the credentials of the bastion from the secret referenced by the k8sd connection,
+// or nil if it does not reference any.
+func (m *Management) getBastionCredentials(ctx context.Context, namespace string, connection *controlplanev1.K8sdConnectionSpec) (*url.Userinfo, error) {
+	if connection == nil || connection.CredentialsSecretRef == nil {
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := m.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: connection.CredentialsSecretRef.Name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get secret %s: %w", connection.CredentialsSecretRef.Name, err)
+	}
+	username, ok := secret.Data[controlplanev1.BastionCredentialsUsernameKey]
+	if !ok {
+		return nil, fmt.Errorf("secret %s has no %q key", secret.Name, controlplanev1.BastionCredentialsUsernameKey)
+	}
+	return url.UserPassword(string(username), string(secret.Data[controlplanev1.BastionCredentialsPasswordKey])), nil
+}
+
+// GetK8sdProxySpec returns the configuration of the k8sd-proxy daemonset of the cluster, as configured on its control plane.
+func GetK8sdProxySpec(ctx context.Context, c client.Reader, clusterKey client.ObjectKey) (*controlplanev1.K8sdProxySpec, error) {
+	spec := &controlplanev1.K8sdProxySpec{}
+	if found, err := getControlPlaneSpecField(ctx, c, clusterKey, "k8sdProxy", spec); err != nil || !found {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// getControlPlaneSpecField reads a field of the spec of the CK8sControlPlane of the cluster into out.
+// It returns false if the cluster has no CK8sControlPlane or the field is not set.
+// The control plane is read as unstructured, as the scheme of the bootstrap provider does not know about it.
+func getControlPlaneSpecField(ctx context.Context, c client.Reader, clusterKey client.ObjectKey, field string, out any) (bool, error) {
+	cluster := &clusterv1.Cluster{}
+	if err := c.Get(ctx, clusterKey, cluster); err != nil {
+		return false, fmt.Errorf("failed to get cluster: %w", err)
+	}
+
+	ref := cluster.Spec.ControlPlaneRef
+	if ref == nil || ref.Kind != "CK8sControlPlane" {
+		return false, nil
+	}
+
+	kcp := &unstructured.Unstructured{}
+	kcp.SetAPIVersion(ref.APIVersion)
+	kcp.SetKind(ref.Kind)
+	if err := c.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: ref.Name}, kcp); err != nil {
+		return false, fmt.Errorf("failed to get control plane: %w", err)
+	}
+
+	raw, found, err := unstructured.NestedMap(kcp.Object, "spec", field)
+	if err != nil || !found {
+		return false, err
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, out); err != nil {
+		return false, fmt.Errorf("failed to parse control plane %s: %w", field, err)
+	}
+	return true, nil
+}
+
 var _ ManagementCluster = &Management{}
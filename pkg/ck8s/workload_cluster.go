@@ -6,8 +6,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	apiv1 "github.com/canonical/k8s-snap-api/api/v1"
 	"golang.org/x/sync/errgroup"
@@ -25,6 +29,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/metrics"
 )
 
 const (
@@ -40,6 +45,7 @@ type WorkloadCluster interface {
 	// Basic health and status checks.
 	ClusterStatus(ctx context.Context) (ClusterStatus, error)
 	UpdateAgentConditions(ctx context.Context, controlPlane *ControlPlane)
+	UpdateK8sdCertificateConditions(ctx context.Context, controlPlane *ControlPlane)
 	NewControlPlaneJoinToken(ctx context.Context, name string) (string, error)
 	NewWorkerJoinToken(ctx context.Context) (string, error)
 
@@ -49,7 +55,8 @@ type WorkloadCluster interface {
 // Workload defines operations on workload clusters.
 type Workload struct {
 	WorkloadCluster
-	authToken string
+	clusterKey ctrlclient.ObjectKey
+	authToken  string
 
 	Client              ctrlclient.Client
 	ClientRestConfig    *rest.Config
@@ -73,12 +80,28 @@ func (w *Workload) getControlPlaneNodes(ctx context.Context) (*corev1.NodeList,
 		// NOTE(neoaggelos): Canonical Kubernetes uses node-role.kubernetes.io/control-plane="" as a label for control plane nodes.
 		labelNodeRoleControlPlane: "",
 	}
+	if c := w.K8sdClientGenerator.syncedCache(); c != nil {
+		return c.getNodes(labels)
+	}
 	if err := w.Client.List(ctx, nodes, ctrlclient.MatchingLabels(labels)); err != nil {
 		return nil, err
 	}
 	return nodes, nil
 }
 
+// getNode returns the node with the given name, from the k8sd client cache if the workload cluster has one.
+func (w *Workload) getNode(ctx context.Context, name string) (*corev1.Node, error) {
+	if c := w.K8sdClientGenerator.syncedCache(); c != nil {
+		return c.getNode(name)
+	}
+
+	node := &corev1.Node{}
+	if err := w.Client.Get(ctx, ctrlclient.ObjectKey{Name: name}, node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
 // ClusterStatus returns the status of the cluster.
 func (w *Workload) ClusterStatus(ctx context.Context) (ClusterStatus, error) {
 	status := ClusterStatus{}
@@ -134,11 +157,15 @@ func nodeHasUnreachableTaint(node corev1.Node) bool {
 	return false
 }
 
-func getNodeInternalIP(node *corev1.Node) (string, error) {
-	// TODO: Make this more robust by possibly finding/parsing the right IP.
-	// This works as a start but might not be sufficient as the kubelet IP might not match microcluster IP.
+// getNodeK8sdAddress returns the address k8sd listens on, as published by the node.
+// Nodes that did not publish it fall back to their first internal IP, that may not match the microcluster address.
+func getNodeK8sdAddress(node *corev1.Node) (string, error) {
+	if address := strings.TrimSpace(node.Annotations[K8sdMicroclusterAddressAnnotation]); net.ParseIP(address) != nil {
+		return address, nil
+	}
+
 	for _, addr := range node.Status.Addresses {
-		if addr.Type == "InternalIP" {
+		if addr.Type == corev1.NodeInternalIP {
 			return addr.Address, nil
 		}
 	}
@@ -159,10 +186,30 @@ func (w *Workload) GetK8sdProxyForControlPlane(ctx context.Context, options k8sd
 		return nil, fmt.Errorf("failed to get control plane nodes: %w", err)
 	}
 
-	// Fetch the Pods only once.
-	podmap, err := w.K8sdClientGenerator.getProxyPods(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get proxy pods: %w", err)
+	// Fetch the Pods only once. They are not needed if k8sd is dialed directly.
+	var podmap map[string]corev1.Pod
+	if !w.K8sdClientGenerator.isDirect() {
+		if podmap, err = w.K8sdClientGenerator.getProxyPods(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get proxy pods: %w", err)
+		}
+	}
+
+	// Try the last control plane node that answered first, and use it without probing it again if it answered recently.
+	cache := w.K8sdClientGenerator.syncedCache()
+	var lastNode string
+	var lastNodeFresh bool
+	if cache != nil {
+		lastNode, lastNodeFresh = cache.getControlPlaneNode(time.Now())
+		slices.SortStableFunc(cplaneNodes.Items, func(a, b corev1.Node) int {
+			switch {
+			case a.Name == lastNode:
+				return -1
+			case b.Name == lastNode:
+				return 1
+			default:
+				return 0
+			}
+		})
 	}
 
 	var allErrors []error
@@ -171,31 +218,45 @@ func (w *Workload) GetK8sdProxyForControlPlane(ctx context.Context, options k8sd
 			continue
 		}
 
-		pod, ok := podmap[node.Name]
-		if !ok {
-			allErrors = append(allErrors, fmt.Errorf("node %s has no k8sd proxy pod", node.Name))
-			continue
-		}
+		var podName string
+		if !w.K8sdClientGenerator.isDirect() {
+			pod, ok := podmap[node.Name]
+			if !ok {
+				allErrors = append(allErrors, fmt.Errorf("node %s has no k8sd proxy pod", node.Name))
+				continue
+			}
 
-		if !podv1.IsPodReady(&pod) {
-			// if the Pod is not Ready, it won't be able to accept any k8sd API calls.
-			allErrors = append(allErrors, fmt.Errorf("pod '%s' is not Ready", pod.Name))
-			continue
+			if !podv1.IsPodReady(&pod) {
+				// if the Pod is not Ready, it won't be able to accept any k8sd API calls.
+				allErrors = append(allErrors, fmt.Errorf("pod '%s' is not Ready", pod.Name))
+				continue
+			}
+			podName = pod.Name
 		}
 
-		proxy, err := w.K8sdClientGenerator.forNodePod(ctx, &node, pod.Name) // #nosec G601
+		proxy, err := w.K8sdClientGenerator.forNodePod(ctx, &node, podName) // #nosec G601
 		if err != nil {
 			allErrors = append(allErrors, fmt.Errorf("could not create proxy client for node %s: %w", node.Name, err))
 			continue
 		}
 
+		if node.Name == lastNode && lastNodeFresh {
+			return proxy, nil
+		}
+
 		// Check if there is any response from the proxy.
 		header := w.newHeaderWithCAPIAuthToken()
 		if err := w.doK8sdRequest(ctx, proxy, http.MethodGet, "", header, "", nil); err != nil {
 			allErrors = append(allErrors, fmt.Errorf("error while contacting proxy on node %s: %w", node.Name, err))
+			if cache != nil && node.Name == lastNode {
+				cache.setControlPlaneNode("", time.Time{})
+			}
 			continue
 		}
 
+		if cache != nil {
+			cache.setControlPlaneNode(node.Name, time.Now())
+		}
 		return proxy, nil
 	}
 
@@ -212,8 +273,8 @@ func (w *Workload) GetK8sdProxyForMachine(ctx context.Context, machine *clusterv
 		return nil, fmt.Errorf("machine %s has no node reference", machine.Name)
 	}
 
-	node := &corev1.Node{}
-	if err := w.Client.Get(ctx, ctrlclient.ObjectKey{Name: machine.Status.NodeRef.Name}, node); err != nil {
+	node, err := w.getNode(ctx, machine.Status.NodeRef.Name)
+	if err != nil {
 		return nil, fmt.Errorf("failed to get node: %w", err)
 	}
 
@@ -411,6 +472,43 @@ func (w *Workload) GetRefreshStatusForMachine(ctx context.Context, machine *clus
 	return response, nil
 }
 
+// SnapReleaseAnnotation is the annotation on which nodes publish the release of the k8s snap they run when joining
+// the cluster, in the format of the in-place upgrade annotations (e.g. "channel=1.31-classic/stable").
+// It is updated after each in-place upgrade of the node.
+const SnapReleaseAnnotation = "v1beta2.k8sd.io/snap-release"
+
+// GetNodeSnapRelease returns the release of the k8s snap the node runs.
+// It returns an empty string if the node did not publish it.
+func (w *Workload) GetNodeSnapRelease(ctx context.Context, nodeName string) (string, error) {
+	node := &corev1.Node{}
+	if err := w.Client.Get(ctx, ctrlclient.ObjectKey{Name: nodeName}, node); err != nil {
+		return "", fmt.Errorf("failed to get node: %w", err)
+	}
+	return node.Annotations[SnapReleaseAnnotation], nil
+}
+
+// SetNodeSnapRelease records the release of the k8s snap the node runs after it was refreshed.
+func (w *Workload) SetNodeSnapRelease(ctx context.Context, nodeName string, release string) error {
+	node := &corev1.Node{}
+	if err := w.Client.Get(ctx, ctrlclient.ObjectKey{Name: nodeName}, node); err != nil {
+		return fmt.Errorf("failed to get node: %w", err)
+	}
+
+	if node.Annotations[SnapReleaseAnnotation] == release {
+		return nil
+	}
+
+	patch := ctrlclient.MergeFrom(node.DeepCopy())
+	if node.Annotations == nil {
+		node.Annotations = map[string]string{}
+	}
+	node.Annotations[SnapReleaseAnnotation] = release
+	if err := w.Client.Patch(ctx, node, patch); err != nil {
+		return fmt.Errorf("failed to patch node: %w", err)
+	}
+	return nil
+}
+
 // NewControlPlaneJoinToken creates a new join token for a control plane node.
 // NewControlPlaneJoinToken reaches out to the control-plane of the workload cluster via k8sd-proxy client.
 func (w *Workload) NewControlPlaneJoinToken(ctx context.Context, name string) (string, error) {
@@ -441,6 +539,7 @@ func (w *Workload) requestJoinToken(ctx context.Context, name string, worker boo
 		return "", fmt.Errorf("failed to get join token: %w", err)
 	}
 
+	metrics.RecordJoinTokenIssued(w.clusterKey, worker)
 	return response.EncodedToken, nil
 }
 
@@ -470,19 +569,31 @@ func (w *Workload) RemoveMachineFromCluster(ctx context.Context, machine *cluste
 	return nil
 }
 
-func (w *Workload) doK8sdRequest(ctx context.Context, k8sdProxy *K8sdClient, method, endpoint string, header map[string][]string, request any, response any) error {
+func (w *Workload) doK8sdRequest(ctx context.Context, k8sdProxy *K8sdClient, method, endpoint string, header map[string][]string, request any, response any) (rerr error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveK8sdRequest(endpoint, time.Since(start), rerr)
+	}()
+
 	type wrappedResponse struct {
 		Error    string          `json:"error"`
 		Metadata json.RawMessage `json:"metadata"`
 	}
 
-	url := fmt.Sprintf("https://%s:%v/%s", k8sdProxy.NodeIP, w.microclusterPort, endpoint)
+	url := fmt.Sprintf("https://%s/%s", net.JoinHostPort(k8sdProxy.NodeIP, strconv.Itoa(w.microclusterPort)), endpoint)
 
 	requestBody, err := json.Marshal(request)
 	if err != nil {
 		return fmt.Errorf("failed to prepare worker info request: %w", err)
 	}
 
+	// NOTE: The timeout is applied to each request, as the k8sd clients are cached across reconciliations.
+	if timeout := w.K8sdClientGenerator.requestTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -650,6 +761,92 @@ func (w *Workload) UpdateAgentConditions(ctx context.Context, controlPlane *Cont
 	})
 }
 
+// UpdateK8sdCertificateConditions is responsible for updating machine conditions reflecting whether k8sd on the
+// control plane machines serves the certificate whose fingerprint was published by their node.
+// This operation is best effort, in the sense that in case of problems in reaching k8sd, it sets the condition
+// to Unknown state without returning any error.
+func (w *Workload) UpdateK8sdCertificateConditions(ctx context.Context, controlPlane *ControlPlane) {
+	var podmap map[string]corev1.Pod
+	var err error
+	if !w.K8sdClientGenerator.isDirect() {
+		podmap, err = w.K8sdClientGenerator.getProxyPods(ctx)
+	}
+	if err != nil {
+		for _, machine := range controlPlane.Machines {
+			conditions.MarkUnknown(machine, controlplanev1.K8sdCertificateVerifiedCondition, controlplanev1.K8sdCertificateInspectionFailedReason, "Failed to get the k8sd proxy pods")
+		}
+		conditions.MarkUnknown(controlPlane.KCP, controlplanev1.K8sdCertificatesVerifiedCondition, controlplanev1.K8sdCertificateInspectionFailedReason, "Failed to get the k8sd proxy pods")
+		return
+	}
+
+	for _, machine := range controlPlane.Machines {
+		if machine.Status.NodeRef == nil || !machine.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		node, err := w.getNode(ctx, machine.Status.NodeRef.Name)
+		if err != nil {
+			conditions.MarkUnknown(machine, controlplanev1.K8sdCertificateVerifiedCondition, controlplanev1.K8sdCertificateInspectionFailedReason, "Failed to get node")
+			continue
+		}
+
+		if GetK8sdCertificateFingerprint(node) == "" && w.K8sdClientGenerator.allowUnverified {
+			conditions.MarkFalse(machine, controlplanev1.K8sdCertificateVerifiedCondition, controlplanev1.K8sdCertificateNotPublishedReason, clusterv1.ConditionSeverityWarning,
+				"Node %s did not publish the fingerprint of its k8sd certificate", node.Name)
+			continue
+		}
+
+		var podName string
+		if !w.K8sdClientGenerator.isDirect() {
+			pod, ok := podmap[node.Name]
+			if !ok || !podv1.IsPodReady(&pod) {
+				conditions.MarkUnknown(machine, controlplanev1.K8sdCertificateVerifiedCondition, controlplanev1.K8sdCertificateInspectionFailedReason, "k8sd proxy pod is not ready")
+				continue
+			}
+			podName = pod.Name
+		}
+
+		proxy, err := w.K8sdClientGenerator.forNodePod(ctx, node, podName)
+		if errors.Is(err, ErrK8sdCertificateNotPublished) {
+			conditions.MarkFalse(machine, controlplanev1.K8sdCertificateVerifiedCondition, controlplanev1.K8sdCertificateNotPublishedReason, clusterv1.ConditionSeverityWarning,
+				"Node %s did not publish the fingerprint of its k8sd certificate, and it could not be recorded", node.Name)
+			continue
+		}
+		if err != nil {
+			conditions.MarkUnknown(machine, controlplanev1.K8sdCertificateVerifiedCondition, controlplanev1.K8sdCertificateInspectionFailedReason, "Failed to create k8sd proxy client")
+			continue
+		}
+
+		header := w.newHeaderWithCAPIAuthToken()
+		if err := w.doK8sdRequest(ctx, proxy, http.MethodGet, "", header, "", nil); err != nil {
+			if errors.Is(err, ErrK8sdCertificateMismatch) {
+				conditions.MarkFalse(machine, controlplanev1.K8sdCertificateVerifiedCondition, controlplanev1.K8sdCertificateMismatchReason, clusterv1.ConditionSeverityError,
+					"k8sd on node %s serves a certificate that does not match the fingerprint published by the node", node.Name)
+				continue
+			}
+			conditions.MarkUnknown(machine, controlplanev1.K8sdCertificateVerifiedCondition, controlplanev1.K8sdCertificateInspectionFailedReason, "Failed to reach k8sd")
+			continue
+		}
+
+		if GetK8sdCertificateFingerprint(node) == "" || node.Annotations[K8sdCertificateTrustedOnFirstUseAnnotation] == "true" {
+			conditions.MarkFalse(machine, controlplanev1.K8sdCertificateVerifiedCondition, controlplanev1.K8sdCertificateTrustedOnFirstUseReason, clusterv1.ConditionSeverityInfo,
+				"The k8sd certificate of node %s was recorded on first use, as the node did not publish its fingerprint", node.Name)
+			continue
+		}
+
+		conditions.MarkTrue(machine, controlplanev1.K8sdCertificateVerifiedCondition)
+	}
+
+	aggregateFromMachinesToKCP(aggregateFromMachinesToKCPInput{
+		controlPlane:      controlPlane,
+		machineConditions: []clusterv1.ConditionType{controlplanev1.K8sdCertificateVerifiedCondition},
+		condition:         controlplanev1.K8sdCertificatesVerifiedCondition,
+		unhealthyReason:   controlplanev1.K8sdCertificatesUnverifiedReason,
+		unknownReason:     controlplanev1.K8sdCertificateInspectionFailedReason,
+		note:              "k8sd certificate",
+	})
+}
+
 type aggregateFromMachinesToKCPInput struct {
 	controlPlane      *ControlPlane
 	machineConditions []clusterv1.ConditionType
@@ -0,0 +1,76 @@
+package ck8s
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	apiv1 "github.com/canonical/k8s-snap-api/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+)
+
+// GetCertificatesStatus returns the expiry of each certificate and certificate authority of the machine.
+func (w *Workload) GetCertificatesStatus(ctx context.Context, machine *clusterv1.Machine, nodeToken string) (*bootstrapv1.CertificatesStatus, error) {
+	request := apiv1.CertificatesStatusRequest{}
+	response := &apiv1.CertificatesStatusResponse{}
+
+	header := w.newHeaderWithNodeToken(nodeToken)
+	k8sdProxy, err := w.GetK8sdProxyForMachine(ctx, machine)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create k8sd proxy: %w", err)
+	}
+
+	if err := w.doK8sdRequest(ctx, k8sdProxy, http.MethodGet, fmt.Sprintf("%s/%s", apiv1.K8sdAPIVersion, apiv1.CertificatesStatusRPC), header, request, response); err != nil {
+		return nil, fmt.Errorf("failed to get certificates status: %w", err)
+	}
+
+	return newCertificatesStatus(response)
+}
+
+// newCertificatesStatus converts the k8sd certificates status to the status of the CK8sConfig.
+func newCertificatesStatus(response *apiv1.CertificatesStatusResponse) (*bootstrapv1.CertificatesStatus, error) {
+	status := &bootstrapv1.CertificatesStatus{}
+	for _, c := range response.Certificates {
+		expiresAt, err := parseExpiry(c.Expires)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiry of certificate %q: %w", c.Name, err)
+		}
+		status.Certificates = append(status.Certificates, bootstrapv1.CertificateStatus{
+			Name:                 c.Name,
+			ExpiresAt:            expiresAt,
+			CertificateAuthority: c.CertificateAuthority,
+			ExternallyManaged:    c.ExternallyManaged,
+		})
+	}
+	for _, ca := range response.CertificateAuthorities {
+		expiresAt, err := parseExpiry(ca.Expires)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiry of certificate authority %q: %w", ca.Name, err)
+		}
+		status.CertificateAuthorities = append(status.CertificateAuthorities, bootstrapv1.CertificateStatus{
+			Name:              ca.Name,
+			ExpiresAt:         expiresAt,
+			ExternallyManaged: ca.ExternallyManaged,
+		})
+	}
+
+	return status, nil
+}
+
+// parseExpiry parses an RFC3339 expiry date. Certificates without an expiry date, e.g. missing
+// externally managed certificates, have no expiry.
+func parseExpiry(expires string) (*metav1.Time, error) {
+	if expires == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, expires)
+	if err != nil {
+		return nil, err
+	}
+	return &metav1.Time{Time: t}, nil
+}
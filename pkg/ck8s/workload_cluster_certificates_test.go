@@ -0,0 +1,80 @@
+package ck8s
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "github.com/canonical/k8s-snap-api/api/v1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+)
+
+func TestNewCertificatesStatus(t *testing.T) {
+	expiry := time.Date(2027, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		response     *apiv1.CertificatesStatusResponse
+		expectStatus *bootstrapv1.CertificatesStatus
+		expectErr    bool
+	}{
+		{
+			name: "certificates and authorities",
+			response: &apiv1.CertificatesStatusResponse{
+				Certificates: []apiv1.CertificateStatus{
+					{Name: "kubelet", Expires: expiry.Format(time.RFC3339), CertificateAuthority: "kubernetes-ca"},
+					{Name: "apiserver", Expires: expiry.Format(time.RFC3339), CertificateAuthority: "kubernetes-ca", ExternallyManaged: true},
+				},
+				CertificateAuthorities: []apiv1.CertificateAuthorityStatus{
+					{Name: "ca", Expires: expiry.Format(time.RFC3339)},
+				},
+			},
+			expectStatus: &bootstrapv1.CertificatesStatus{
+				Certificates: []bootstrapv1.CertificateStatus{
+					{Name: "kubelet", ExpiresAt: &metav1.Time{Time: expiry}, CertificateAuthority: "kubernetes-ca"},
+					{Name: "apiserver", ExpiresAt: &metav1.Time{Time: expiry}, CertificateAuthority: "kubernetes-ca", ExternallyManaged: true},
+				},
+				CertificateAuthorities: []bootstrapv1.CertificateStatus{
+					{Name: "ca", ExpiresAt: &metav1.Time{Time: expiry}},
+				},
+			},
+		},
+		{
+			name: "certificate without expiry",
+			response: &apiv1.CertificatesStatusResponse{
+				Certificates: []apiv1.CertificateStatus{{Name: "front-proxy-client", ExternallyManaged: true}},
+			},
+			expectStatus: &bootstrapv1.CertificatesStatus{
+				Certificates: []bootstrapv1.CertificateStatus{{Name: "front-proxy-client", ExternallyManaged: true}},
+			},
+		},
+		{
+			name:         "empty",
+			response:     &apiv1.CertificatesStatusResponse{},
+			expectStatus: &bootstrapv1.CertificatesStatus{},
+		},
+		{
+			name: "invalid expiry",
+			response: &apiv1.CertificatesStatusResponse{
+				Certificates: []apiv1.CertificateStatus{{Name: "kubelet", Expires: "tomorrow"}},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			status, err := newCertificatesStatus(tt.response)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(status).To(Equal(tt.expectStatus))
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package ck8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// mirrorPodAnnotation is set by the kubelet on the API objects of static pods.
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// CordonNode marks the node as unschedulable.
+func (w *Workload) CordonNode(ctx context.Context, nodeName string) error {
+	return w.setNodeUnschedulable(ctx, nodeName, true)
+}
+
+// UncordonNode marks the node as schedulable.
+func (w *Workload) UncordonNode(ctx context.Context, nodeName string) error {
+	return w.setNodeUnschedulable(ctx, nodeName, false)
+}
+
+func (w *Workload) setNodeUnschedulable(ctx context.Context, nodeName string, unschedulable bool) error {
+	node := &corev1.Node{}
+	if err := w.Client.Get(ctx, ctrlclient.ObjectKey{Name: nodeName}, node); err != nil {
+		return fmt.Errorf("failed to get node: %w", err)
+	}
+
+	if node.Spec.Unschedulable == unschedulable {
+		return nil
+	}
+
+	patch := ctrlclient.MergeFrom(node.DeepCopy())
+	node.Spec.Unschedulable = unschedulable
+	if err := w.Client.Patch(ctx, node, patch); err != nil {
+		return fmt.Errorf("failed to patch node: %w", err)
+	}
+
+	return nil
+}
+
+// IsNodeReady checks if the node reports the Ready condition.
+func (w *Workload) IsNodeReady(ctx context.Context, nodeName string) (bool, error) {
+	node := &corev1.Node{}
+	if err := w.Client.Get(ctx, ctrlclient.ObjectKey{Name: nodeName}, node); err != nil {
+		return false, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	return util.IsNodeReady(node), nil
+}
+
+// DrainNode evicts the pods running on the node and returns the number of pods that are still left to evict.
+// Evictions go through the eviction API so that PodDisruptionBudgets are honoured. Evictions refused
+// because of a PodDisruptionBudget are not errors, the caller is expected to call DrainNode again later.
+// DaemonSet pods, static pods and pods that already terminated are left on the node.
+func (w *Workload) DrainNode(ctx context.Context, nodeName string) (int, error) {
+	pods := &corev1.PodList{}
+	if err := w.Client.List(ctx, pods, ctrlclient.MatchingFields{"spec.nodeName": nodeName}); err != nil {
+		return 0, fmt.Errorf("failed to list pods on node: %w", err)
+	}
+
+	var remaining int
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !needsEviction(pod) {
+			continue
+		}
+
+		remaining++
+		if !pod.DeletionTimestamp.IsZero() {
+			// Already evicted, waiting for the pod to go away.
+			continue
+		}
+
+		eviction := &policyv1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pod.Name,
+				Namespace: pod.Namespace,
+			},
+		}
+		if err := w.Client.SubResource("eviction").Create(ctx, pod, eviction); err != nil {
+			switch {
+			case apierrors.IsNotFound(err):
+				remaining--
+			case apierrors.IsTooManyRequests(err):
+				// The eviction would violate a PodDisruptionBudget.
+			default:
+				return remaining, fmt.Errorf("failed to evict pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+		}
+	}
+
+	return remaining, nil
+}
+
+// needsEviction checks if the pod has to be evicted for the node to be drained.
+func needsEviction(pod *corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+
+	if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+		return false
+	}
+
+	if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "DaemonSet" {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,123 @@
+package ck8s
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newPod(name, nodeName string, modify func(*corev1.Pod)) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+	if modify != nil {
+		modify(pod)
+	}
+	return pod
+}
+
+func TestCordonNode(t *testing.T) {
+	g := NewWithT(t)
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	fakeClient := fake.NewClientBuilder().WithObjects(node).Build()
+	w := &Workload{Client: fakeClient}
+
+	g.Expect(w.CordonNode(context.Background(), "node1")).To(Succeed())
+
+	updated := &corev1.Node{}
+	g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Name: "node1"}, updated)).To(Succeed())
+	g.Expect(updated.Spec.Unschedulable).To(BeTrue())
+
+	g.Expect(w.UncordonNode(context.Background(), "node1")).To(Succeed())
+
+	g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Name: "node1"}, updated)).To(Succeed())
+	g.Expect(updated.Spec.Unschedulable).To(BeFalse())
+}
+
+func TestDrainNode(t *testing.T) {
+	tests := []struct {
+		name            string
+		pods            []client.Object
+		expectRemaining int
+		expectEvicted   []string
+		expectKept      []string
+	}{
+		{
+			name: "evicts workload pods",
+			pods: []client.Object{
+				newPod("app", "node1", nil),
+				newPod("other-node", "node2", nil),
+			},
+			expectRemaining: 1,
+			expectEvicted:   []string{"app"},
+			expectKept:      []string{"other-node"},
+		},
+		{
+			name: "skips daemonset, static and terminated pods",
+			pods: []client.Object{
+				newPod("daemon", "node1", func(p *corev1.Pod) {
+					p.OwnerReferences = []metav1.OwnerReference{{
+						APIVersion: "apps/v1",
+						Kind:       "DaemonSet",
+						Name:       "ds",
+						Controller: ptr.To(true),
+					}}
+				}),
+				newPod("static", "node1", func(p *corev1.Pod) {
+					p.Annotations = map[string]string{mirrorPodAnnotation: "hash"}
+				}),
+				newPod("completed", "node1", func(p *corev1.Pod) {
+					p.Status.Phase = corev1.PodSucceeded
+				}),
+			},
+			expectRemaining: 0,
+			expectKept:      []string{"daemon", "static", "completed"},
+		},
+		{
+			name:            "no pods",
+			expectRemaining: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			fakeClient := fake.NewClientBuilder().
+				WithObjects(tt.pods...).
+				WithIndex(&corev1.Pod{}, "spec.nodeName", func(o client.Object) []string {
+					return []string{o.(*corev1.Pod).Spec.NodeName}
+				}).
+				Build()
+			w := &Workload{Client: fakeClient}
+
+			remaining, err := w.DrainNode(context.Background(), "node1")
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(remaining).To(Equal(tt.expectRemaining))
+
+			for _, name := range tt.expectEvicted {
+				err := fakeClient.Get(context.Background(), client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: name}, &corev1.Pod{})
+				g.Expect(err).To(HaveOccurred())
+			}
+			for _, name := range tt.expectKept {
+				g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: name}, &corev1.Pod{})).To(Succeed())
+			}
+		})
+	}
+}
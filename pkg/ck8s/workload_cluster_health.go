@@ -0,0 +1,99 @@
+package ck8s
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	apiv1 "github.com/canonical/k8s-snap-api/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/cluster-api/util"
+)
+
+// CheckNodesReady checks that all the nodes of the workload cluster are Ready.
+// Nodes in ignoreNodes are expected to be unavailable, e.g. because they are being upgraded, and are not checked.
+func (w *Workload) CheckNodesReady(ctx context.Context, ignoreNodes map[string]struct{}) error {
+	nodes := &corev1.NodeList{}
+	if err := w.Client.List(ctx, nodes); err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var notReady []string
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if _, ok := ignoreNodes[node.Name]; ok {
+			continue
+		}
+		if !util.IsNodeReady(node) {
+			notReady = append(notReady, node.Name)
+		}
+	}
+
+	if len(notReady) > 0 {
+		return fmt.Errorf("nodes %v are not ready", notReady)
+	}
+	return nil
+}
+
+// CheckDatastoreMembers checks that every control plane node is a member of the k8sd dqlite cluster
+// with a settled role, i.e. no member is pending or in an unknown state.
+func (w *Workload) CheckDatastoreMembers(ctx context.Context) error {
+	nodes, err := w.getControlPlaneNodes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get control plane nodes: %w", err)
+	}
+
+	k8sdProxy, err := w.GetK8sdProxyForControlPlane(ctx, k8sdProxyOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create k8sd proxy: %w", err)
+	}
+
+	response := &apiv1.ClusterStatusResponse{}
+	header := w.newHeaderWithCAPIAuthToken()
+	if err := w.doK8sdRequest(ctx, k8sdProxy, http.MethodGet, fmt.Sprintf("%s/%s", apiv1.K8sdAPIVersion, apiv1.ClusterStatusRPC), header, apiv1.ClusterStatusRequest{}, response); err != nil {
+		return fmt.Errorf("failed to get cluster status: %w", err)
+	}
+
+	return checkDatastoreMembers(response.ClusterStatus.Members, nodes.Items)
+}
+
+// checkDatastoreMembers checks that the members report a settled datastore role for every control plane node.
+func checkDatastoreMembers(members []apiv1.NodeStatus, controlPlaneNodes []corev1.Node) error {
+	roles := make(map[string]apiv1.DatastoreRole, len(members))
+	for _, member := range members {
+		roles[member.Name] = member.DatastoreRole
+	}
+
+	var problems []string
+	for _, node := range controlPlaneNodes {
+		role, ok := roles[node.Name]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s is not a member", node.Name))
+		case role == apiv1.DatastoreRolePending || role == apiv1.DatastoreRoleUnknown || role == "":
+			problems = append(problems, fmt.Sprintf("%s has datastore role %q", node.Name, role))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("datastore membership is not healthy: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// CheckHealthEndpoint checks that a GET request to the path on the workload cluster API server succeeds.
+// This can reach a user-supplied health check service through the API server service proxy,
+// e.g. "/api/v1/namespaces/monitoring/services/health-check:8080/proxy/healthz".
+func (w *Workload) CheckHealthEndpoint(ctx context.Context, path string) error {
+	if w.K8sdClientGenerator == nil || w.K8sdClientGenerator.clientset == nil {
+		return fmt.Errorf("no clientset for the workload cluster")
+	}
+
+	if err := w.K8sdClientGenerator.clientset.Discovery().RESTClient().Get().AbsPath(path).Do(ctx).Error(); err != nil {
+		return fmt.Errorf("health check endpoint %q failed: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package ck8s
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "github.com/canonical/k8s-snap-api/api/v1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newNode(name string, ready bool) *corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{
+				Type:   corev1.NodeReady,
+				Status: status,
+			}},
+		},
+	}
+}
+
+func TestCheckNodesReady(t *testing.T) {
+	g := NewWithT(t)
+
+	fakeClient := fake.NewClientBuilder().WithObjects(newNode("node1", true), newNode("node2", false)).Build()
+	w := &Workload{Client: fakeClient}
+
+	g.Expect(w.CheckNodesReady(context.Background(), nil)).ToNot(Succeed())
+	g.Expect(w.CheckNodesReady(context.Background(), map[string]struct{}{"node2": {}})).To(Succeed())
+}
+
+func TestCheckDatastoreMembers(t *testing.T) {
+	nodes := []corev1.Node{*newNode("cp1", true), *newNode("cp2", true)}
+
+	for _, tc := range []struct {
+		name      string
+		members   []apiv1.NodeStatus
+		expectErr bool
+	}{
+		{
+			name: "healthy",
+			members: []apiv1.NodeStatus{
+				{Name: "cp1", DatastoreRole: apiv1.DatastoreRoleVoter},
+				{Name: "cp2", DatastoreRole: apiv1.DatastoreRoleStandBy},
+			},
+		},
+		{
+			name: "missingMember",
+			members: []apiv1.NodeStatus{
+				{Name: "cp1", DatastoreRole: apiv1.DatastoreRoleVoter},
+			},
+			expectErr: true,
+		},
+		{
+			name: "pendingMember",
+			members: []apiv1.NodeStatus{
+				{Name: "cp1", DatastoreRole: apiv1.DatastoreRoleVoter},
+				{Name: "cp2", DatastoreRole: apiv1.DatastoreRolePending},
+			},
+			expectErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := checkDatastoreMembers(tc.members, nodes)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
@@ -2,30 +2,85 @@ package ck8s
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	_ "embed"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
 	"github.com/canonical/cluster-api-k8s/pkg/proxy"
 )
 
+// K8sdCertificateFingerprintAnnotation is the annotation on which nodes publish the SHA-256 fingerprint
+// of the certificate served by k8sd when joining the cluster.
+const K8sdCertificateFingerprintAnnotation = "v1beta2.k8sd.io/certificate-fingerprint"
+
+// K8sdMicroclusterAddressAnnotation is the annotation on which nodes publish the address k8sd listens on,
+// resolved from the configured microcluster address or CIDR when joining the cluster.
+const K8sdMicroclusterAddressAnnotation = "v1beta2.k8sd.io/microcluster-address"
+
+// K8sdCertificateTrustedOnFirstUseAnnotation is the annotation set on nodes whose k8sd certificate fingerprint
+// was not published by the node, but recorded by the providers on their first connection to k8sd.
+const K8sdCertificateTrustedOnFirstUseAnnotation = "v1beta2.k8sd.io/certificate-trusted-on-first-use"
+
+// ErrK8sdCertificateMismatch is returned when k8sd serves a certificate that does not match
+// the fingerprint published by its node.
+var ErrK8sdCertificateMismatch = errors.New("k8sd certificate does not match the fingerprint published by the node")
+
+// ErrK8sdCertificateNotPublished is returned when connecting to k8sd on a node that did not publish
+// the fingerprint of its k8sd certificate, unless unverified certificates are allowed.
+var ErrK8sdCertificateNotPublished = errors.New("node did not publish the fingerprint of its k8sd certificate")
+
+// k8sdProxyLabelSelector selects the k8sd-proxy pods of the workload cluster.
+const k8sdProxyLabelSelector = "app=k8sd-proxy"
+
 type K8sdClient struct {
 	NodeIP string
 	Client *http.Client
+
+	// podName, bastion, bastionCredentials and fingerprint are the k8sd-proxy pod, bastion, hash of the bastion
+	// credentials and certificate fingerprint the client was built for.
+	podName            string
+	bastion            string
+	bastionCredentials string
+	fingerprint        string
 }
 
 type k8sdClientGenerator struct {
 	restConfig         *rest.Config
-	clientset          *kubernetes.Clientset
+	clientset          kubernetes.Interface
 	proxyClientTimeout time.Duration
+
+	// microclusterPort is the port k8sd listens on, used to record the k8sd certificate of the nodes that
+	// did not publish it. Certificates are not recorded if zero.
+	microclusterPort int
+
+	// cache is the k8sd client cache of the workload cluster. Clients are not cached if nil.
+	cache *k8sdClientCache
+
+	// directDialer dials k8sd on the nodes directly instead of through the k8sd-proxy pods, if set.
+	directDialer *proxy.DirectDialer
+	// bastion is the bastion the direct dialer goes through, if any.
+	bastion string
+	// bastionCredentials is the hash of the credentials of the bastion, if any.
+	bastionCredentials string
+
+	// allowUnverified allows connecting to k8sd on nodes that did not publish the fingerprint of their k8sd
+	// certificate, without verifying the certificate.
+	allowUnverified bool
 }
 
 func NewK8sdClientGenerator(restConfig *rest.Config, proxyClientTimeout time.Duration) (*k8sdClientGenerator, error) {
@@ -41,7 +96,34 @@ func NewK8sdClientGenerator(restConfig *rest.Config, proxyClientTimeout time.Dur
 	}, nil
 }
 
+// setDirect makes the generator dial k8sd on the nodes directly, optionally through the bastion
+// authenticated with the credentials.
+func (g *k8sdClientGenerator) setDirect(bastion string, credentials *url.Userinfo) error {
+	dialer, err := proxy.NewDirectDialer(bastion, credentials, g.proxyClientTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to create direct dialer: %w", err)
+	}
+	g.directDialer = dialer
+	g.bastion = bastion
+	g.bastionCredentials = ""
+	if credentials != nil {
+		// NOTE: Only a hash of the credentials is kept, to rebuild the cached clients when they change.
+		sum := sha256.Sum256([]byte(credentials.String()))
+		g.bastionCredentials = hex.EncodeToString(sum[:])
+	}
+	return nil
+}
+
+// isDirect returns true if k8sd is dialed directly, without the k8sd-proxy pods.
+func (g *k8sdClientGenerator) isDirect() bool {
+	return g.directDialer != nil
+}
+
 func (g *k8sdClientGenerator) forNode(ctx context.Context, node *corev1.Node) (*K8sdClient, error) {
+	if g.isDirect() {
+		return g.forNodePod(ctx, node, "")
+	}
+
 	podmap, err := g.getProxyPods(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get proxy pods: %w", err)
@@ -56,24 +138,114 @@ func (g *k8sdClientGenerator) forNode(ctx context.Context, node *corev1.Node) (*
 }
 
 func (g *k8sdClientGenerator) forNodePod(ctx context.Context, node *corev1.Node, podname string) (*K8sdClient, error) {
-	nodeInternalIP, err := getNodeInternalIP(node)
+	nodeInternalIP, err := getNodeK8sdAddress(node)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get internal IP for node %s: %w", node.Name, err)
+		return nil, fmt.Errorf("failed to get k8sd address for node %s: %w", node.Name, err)
+	}
+
+	// NOTE: The fingerprint is checked before looking up the cache, so that clients cached while unverified
+	// certificates were allowed are not used anymore once they are not.
+	fingerprint := GetK8sdCertificateFingerprint(node)
+	if fingerprint == "" && !g.allowUnverified {
+		if fingerprint, err = g.trustOnFirstUse(ctx, node, nodeInternalIP, podname); err != nil {
+			return nil, fmt.Errorf("refusing to connect to k8sd on node %s: %w", node.Name, err)
+		}
+		node = node.DeepCopy()
+		if node.Annotations == nil {
+			node.Annotations = map[string]string{}
+		}
+		node.Annotations[K8sdCertificateFingerprintAnnotation] = fingerprint
 	}
 
-	client, err := g.NewHTTPClient(ctx, podname)
+	if g.cache != nil {
+		if client, ok := g.cache.get(node, podname, g.bastion, g.bastionCredentials); ok {
+			return client, nil
+		}
+	}
+
+	httpClient, err := g.NewHTTPClient(ctx, podname, fingerprint)
 	if err != nil {
 		return nil, err
 	}
 
-	return &K8sdClient{
-		NodeIP: nodeInternalIP,
-		Client: client,
-	}, nil
+	client := &K8sdClient{
+		NodeIP:             nodeInternalIP,
+		Client:             httpClient,
+		podName:            podname,
+		bastion:            g.bastion,
+		bastionCredentials: g.bastionCredentials,
+		fingerprint:        fingerprint,
+	}
+	if g.cache != nil {
+		g.cache.set(node.Name, client)
+	}
+
+	return client, nil
+}
+
+// trustOnFirstUse records the fingerprint of the certificate served by k8sd on a node that did not publish it,
+// e.g. because it joined the cluster before fingerprints were published, on the annotations of the node.
+// The later connections to k8sd on the node are verified against the recorded fingerprint.
+func (g *k8sdClientGenerator) trustOnFirstUse(ctx context.Context, node *corev1.Node, address string, podName string) (string, error) {
+	if g.microclusterPort == 0 || g.clientset == nil {
+		return "", ErrK8sdCertificateNotPublished
+	}
+
+	if timeout := g.requestTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	dialContext, err := g.dialContext(podName)
+	if err != nil {
+		return "", err
+	}
+	conn, err := dialContext(ctx, "tcp", net.JoinHostPort(address, strconv.Itoa(g.microclusterPort)))
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to reach k8sd to record its certificate: %w", ErrK8sdCertificateNotPublished, err)
+	}
+	tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: true}) // #nosec G402 -- the certificate is trusted on first use
+	defer tlsConn.Close()
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return "", fmt.Errorf("%w: failed to get the k8sd certificate: %w", ErrK8sdCertificateNotPublished, err)
+	}
+	certificates := tlsConn.ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return "", fmt.Errorf("%w: no certificate served", ErrK8sdCertificateNotPublished)
+	}
+	sum := sha256.Sum256(certificates[0].Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q,%q:"true"}}}`,
+		K8sdCertificateFingerprintAnnotation, fingerprint, K8sdCertificateTrustedOnFirstUseAnnotation)
+	if _, err := g.clientset.CoreV1().Nodes().Patch(ctx, node.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		return "", fmt.Errorf("failed to record the k8sd certificate fingerprint on node %s: %w", node.Name, err)
+	}
+	return fingerprint, nil
+}
+
+// syncedCache returns the k8sd client cache of the workload cluster, or nil if there is none or it did not sync yet.
+func (g *k8sdClientGenerator) syncedCache() *k8sdClientCache {
+	if g == nil || g.cache == nil || !g.cache.synced() {
+		return nil
+	}
+	return g.cache
 }
 
 func (g *k8sdClientGenerator) getProxyPods(ctx context.Context) (map[string]corev1.Pod, error) {
-	pods, err := g.clientset.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{LabelSelector: "app=k8sd-proxy"})
+	if c := g.syncedCache(); c != nil {
+		podmap, err := c.getProxyPods()
+		if err != nil {
+			return nil, err
+		}
+		if len(podmap) == 0 {
+			return nil, errors.New("there isn't any k8sd-proxy pods in target cluster")
+		}
+		return podmap, nil
+	}
+
+	pods, err := g.clientset.CoreV1().Pods(metav1.NamespaceSystem).List(ctx, metav1.ListOptions{LabelSelector: k8sdProxyLabelSelector})
 	if err != nil {
 		return nil, fmt.Errorf("unable to list k8sd-proxy pods in target cluster: %w", err)
 	}
@@ -90,35 +262,100 @@ func (g *k8sdClientGenerator) getProxyPods(ctx context.Context) (map[string]core
 	return podmap, nil
 }
 
-func (g *k8sdClientGenerator) NewHTTPClient(ctx context.Context, podName string) (*http.Client, error) {
-	p := proxy.Proxy{
-		Kind:         "pods",
-		Namespace:    metav1.NamespaceSystem,
-		ResourceName: podName,
-		KubeConfig:   g.restConfig,
-		Port:         2380,
+// NewHTTPClient returns a client reaching k8sd through the k8sd-proxy pod, or directly in direct mode,
+// that only accepts the k8sd certificate matching the fingerprint.
+func (g *k8sdClientGenerator) NewHTTPClient(ctx context.Context, podName string, fingerprint string) (*http.Client, error) {
+	dialContext, err := g.dialContext(podName)
+	if err != nil {
+		return nil, err
 	}
 
-	dialer, err := proxy.NewDialer(p)
+	tlsConfig, err := newK8sdTLSConfig(fingerprint, g.allowUnverified)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create proxy dialer: %w", err)
+		return nil, err
+	}
+
+	// NOTE: The bastion is dialed by the direct dialer, so the proxy from the environment must not be used on top of it.
+	proxyFunc := http.DefaultTransport.(*http.Transport).Proxy
+	if g.bastion != "" {
+		proxyFunc = nil
 	}
 
 	// We return a http client with the same parameters as http.DefaultClient
-	// and an overridden DialContext to proxy the requests through api server.
+	// and an overridden DialContext to proxy the requests through api server, or the bastion in direct mode.
 	return &http.Client{
 		Transport: &http.Transport{
-			Proxy:                 http.DefaultTransport.(*http.Transport).Proxy,
-			DialContext:           dialer.DialContext,
+			Proxy:                 proxyFunc,
+			DialContext:           dialContext,
 			ForceAttemptHTTP2:     http.DefaultTransport.(*http.Transport).ForceAttemptHTTP2,
 			MaxIdleConns:          http.DefaultTransport.(*http.Transport).MaxIdleConns,
 			IdleConnTimeout:       http.DefaultTransport.(*http.Transport).IdleConnTimeout,
 			TLSHandshakeTimeout:   http.DefaultTransport.(*http.Transport).TLSHandshakeTimeout,
 			ExpectContinueTimeout: http.DefaultTransport.(*http.Transport).ExpectContinueTimeout,
-			// TODO: Workaround for now, address later on
-			// get the certificate fingerprint from the matching node through a resource in the cluster (TBD), and validate it in the TLSClientConfig
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec G402
+			TLSClientConfig:       tlsConfig,
+		},
+	}, nil
+}
+
+// requestTimeout returns the timeout of the requests to k8sd, or zero if there is none.
+func (g *k8sdClientGenerator) requestTimeout() time.Duration {
+	if g == nil {
+		return 0
+	}
+	return g.proxyClientTimeout
+}
+
+// dialContext returns the function dialing k8sd through the k8sd-proxy pod, or directly in direct mode.
+func (g *k8sdClientGenerator) dialContext(podName string) (func(ctx context.Context, network string, addr string) (net.Conn, error), error) {
+	if g.isDirect() {
+		return g.directDialer.DialContext, nil
+	}
+
+	p := proxy.Proxy{
+		Kind:         "pods",
+		Namespace:    metav1.NamespaceSystem,
+		ResourceName: podName,
+		KubeConfig:   g.restConfig,
+		Port:         2380,
+	}
+
+	dialer, err := proxy.NewDialer(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create proxy dialer: %w", err)
+	}
+	return dialer.DialContext, nil
+}
+
+// GetK8sdCertificateFingerprint returns the fingerprint of the k8sd certificate published by the node,
+// as lowercase hex without separators. It returns an empty string if the node did not publish it.
+func GetK8sdCertificateFingerprint(node *corev1.Node) string {
+	fingerprint := node.Annotations[K8sdCertificateFingerprintAnnotation]
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(fingerprint), ":", ""))
+}
+
+// newK8sdTLSConfig returns the TLS configuration verifying that k8sd serves the certificate with the fingerprint.
+// The k8sd certificate is self-signed, so it is pinned by its fingerprint instead of verified against a CA.
+// Without a fingerprint, the certificate is only left unverified if allowUnverified is set.
+func newK8sdTLSConfig(fingerprint string, allowUnverified bool) (*tls.Config, error) {
+	if fingerprint == "" {
+		if !allowUnverified {
+			return nil, ErrK8sdCertificateNotPublished
+		}
+		// NOTE: Nodes that joined before publishing their fingerprint cannot be verified.
+		return &tls.Config{InsecureSkipVerify: true}, nil // #nosec G402 -- explicitly allowed by the k8sd connection
+	}
+
+	return &tls.Config{
+		InsecureSkipVerify: true, // #nosec G402 -- the certificate is verified by VerifyConnection
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return fmt.Errorf("%w: no certificate served", ErrK8sdCertificateMismatch)
+			}
+			sum := sha256.Sum256(cs.PeerCertificates[0].Raw)
+			if got := hex.EncodeToString(sum[:]); got != fingerprint {
+				return fmt.Errorf("%w: expected %s, got %s", ErrK8sdCertificateMismatch, fingerprint, got)
+			}
+			return nil
 		},
-		Timeout: g.proxyClientTimeout,
 	}, nil
 }
@@ -0,0 +1,312 @@
+package ck8s
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// k8sdClientCacheIdleTimeout is how long the k8sd client cache of a workload cluster is kept without being used,
+	// e.g. after the cluster was deleted.
+	k8sdClientCacheIdleTimeout = 15 * time.Minute
+
+	// k8sdClientCacheSweepInterval is how often the k8sd client caches that were not used recently are stopped.
+	k8sdClientCacheSweepInterval = time.Minute
+
+	// k8sdProxyProbeInterval is how long the last control plane node that answered through its k8sd proxy
+	// is used without probing it again.
+	k8sdProxyProbeInterval = 30 * time.Second
+)
+
+// k8sdClientCaches holds the k8sd client cache of each workload cluster, shared by all the controllers of the manager.
+var k8sdClientCaches = struct {
+	sync.Mutex
+	caches map[ctrlclient.ObjectKey]*k8sdClientCache
+
+	// sweeper starts the background sweep of the caches that were not used recently.
+	sweeper sync.Once
+}{caches: map[ctrlclient.ObjectKey]*k8sdClientCache{}}
+
+// k8sdClientCache caches the k8sd clients of a workload cluster by node name.
+// The k8sd-proxy pods and the nodes of the workload cluster are watched, so that listing them does not hit the
+// workload cluster API server, and cached clients are dropped when the pod or node they were built for changes.
+type k8sdClientCache struct {
+	restConfig *rest.Config
+	podLister  corelisters.PodLister
+	nodeLister corelisters.NodeLister
+	hasSynced  []cache.InformerSynced
+	stop       chan struct{}
+
+	mu                   sync.Mutex
+	clients              map[string]*K8sdClient
+	controlPlaneNode     string
+	controlPlaneProbedAt time.Time
+	lastUsed             time.Time
+}
+
+// getK8sdClientCache returns the k8sd client cache of the workload cluster, creating it if needed.
+// The cache is created again if the credentials of the workload cluster changed.
+// Caches that are not used anymore, e.g. of deleted clusters, are stopped in the background.
+func getK8sdClientCache(clusterKey ctrlclient.ObjectKey, restConfig *rest.Config) (*k8sdClientCache, error) {
+	k8sdClientCaches.sweeper.Do(func() {
+		go func() {
+			for now := range time.Tick(k8sdClientCacheSweepInterval) {
+				sweepK8sdClientCaches(now)
+			}
+		}()
+	})
+
+	k8sdClientCaches.Lock()
+	defer k8sdClientCaches.Unlock()
+
+	now := time.Now()
+	if c, ok := k8sdClientCaches.caches[clusterKey]; ok {
+		if sameRESTConfig(c.restConfig, restConfig) {
+			c.touch(now)
+			return c, nil
+		}
+		c.close()
+		delete(k8sdClientCaches.caches, clusterKey)
+	}
+
+	// NOTE: The timeout of the client applies to the watch requests too, which would be cut and established again
+	// every time it elapses.
+	watchConfig := rest.CopyConfig(restConfig)
+	watchConfig.Timeout = 0
+	clientset, err := kubernetes.NewForConfig(watchConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	c, err := newK8sdClientCache(restConfig, clientset)
+	if err != nil {
+		return nil, err
+	}
+	c.touch(now)
+	k8sdClientCaches.caches[clusterKey] = c
+	return c, nil
+}
+
+// sweepK8sdClientCaches stops the k8sd client caches that were not used for longer than the idle timeout.
+func sweepK8sdClientCaches(now time.Time) {
+	k8sdClientCaches.Lock()
+	defer k8sdClientCaches.Unlock()
+
+	for key, c := range k8sdClientCaches.caches {
+		if c.idleSince(now) > k8sdClientCacheIdleTimeout {
+			c.close()
+			delete(k8sdClientCaches.caches, key)
+		}
+	}
+}
+
+// newK8sdClientCache starts the informers of the k8sd client cache. It does not wait for them to sync,
+// so that an unreachable workload cluster does not block the reconciliation.
+func newK8sdClientCache(restConfig *rest.Config, clientset kubernetes.Interface) (*k8sdClientCache, error) {
+	podInformerFactory := informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+		informers.WithNamespace(metav1.NamespaceSystem),
+		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.LabelSelector = k8sdProxyLabelSelector
+		}),
+	)
+	nodeInformerFactory := informers.NewSharedInformerFactory(clientset, 0)
+
+	podInformer := podInformerFactory.Core().V1().Pods()
+	nodeInformer := nodeInformerFactory.Core().V1().Nodes()
+
+	c := &k8sdClientCache{
+		restConfig: rest.CopyConfig(restConfig),
+		podLister:  podInformer.Lister(),
+		nodeLister: nodeInformer.Lister(),
+		stop:       make(chan struct{}),
+		clients:    map[string]*K8sdClient{},
+	}
+
+	if _, err := podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj any) {
+			if pod, ok := deletedObject(obj).(*corev1.Pod); ok {
+				c.invalidate(pod.Spec.NodeName)
+			}
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to watch k8sd proxy pods: %w", err)
+	}
+	if _, err := nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj any) {
+			if node, ok := deletedObject(obj).(*corev1.Node); ok {
+				c.invalidate(node.Name)
+			}
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to watch nodes: %w", err)
+	}
+
+	c.hasSynced = []cache.InformerSynced{podInformer.Informer().HasSynced, nodeInformer.Informer().HasSynced}
+	podInformerFactory.Start(c.stop)
+	nodeInformerFactory.Start(c.stop)
+
+	return c, nil
+}
+
+// synced returns true once the k8sd-proxy pods and the nodes of the workload cluster were listed.
+func (c *k8sdClientCache) synced() bool {
+	for _, hasSynced := range c.hasSynced {
+		if !hasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// getProxyPods returns the k8sd-proxy pods by node name.
+func (c *k8sdClientCache) getProxyPods() (map[string]corev1.Pod, error) {
+	pods, err := c.podLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list k8sd-proxy pods: %w", err)
+	}
+
+	podmap := make(map[string]corev1.Pod, len(pods))
+	for _, pod := range pods {
+		podmap[pod.Spec.NodeName] = *pod
+	}
+	return podmap, nil
+}
+
+// getNode returns the node with the given name.
+func (c *k8sdClientCache) getNode(name string) (*corev1.Node, error) {
+	node, err := c.nodeLister.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return node.DeepCopy(), nil
+}
+
+// getNodes returns the nodes matching the labels.
+func (c *k8sdClientCache) getNodes(matchLabels map[string]string) (*corev1.NodeList, error) {
+	nodes, err := c.nodeLister.List(labels.SelectorFromSet(matchLabels))
+	if err != nil {
+		return nil, err
+	}
+
+	list := &corev1.NodeList{Items: make([]corev1.Node, 0, len(nodes))}
+	for _, node := range nodes {
+		list.Items = append(list.Items, *node.DeepCopy())
+	}
+	return list, nil
+}
+
+// get returns the cached client of the node, if it was built for the same pod, bastion, address and certificate.
+func (c *k8sdClientCache) get(node *corev1.Node, podName string, bastion string, bastionCredentials string) (*K8sdClient, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	client, ok := c.clients[node.Name]
+	if !ok {
+		return nil, false
+	}
+
+	nodeIP, err := getNodeK8sdAddress(node)
+	if err != nil || client.podName != podName || client.bastion != bastion || client.bastionCredentials != bastionCredentials || client.NodeIP != nodeIP || client.fingerprint != GetK8sdCertificateFingerprint(node) {
+		client.Client.CloseIdleConnections()
+		delete(c.clients, node.Name)
+		return nil, false
+	}
+	return client, true
+}
+
+// set caches the client of the node.
+func (c *k8sdClientCache) set(nodeName string, client *K8sdClient) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.clients[nodeName] = client
+}
+
+// invalidate drops the cached client of the node.
+func (c *k8sdClientCache) invalidate(nodeName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.clients[nodeName]; ok {
+		client.Client.CloseIdleConnections()
+		delete(c.clients, nodeName)
+	}
+	if c.controlPlaneNode == nodeName {
+		c.controlPlaneNode = ""
+	}
+}
+
+// getControlPlaneNode returns the last control plane node that answered through its k8sd proxy,
+// and whether it answered recently enough to be used without probing it again.
+func (c *k8sdClientCache) getControlPlaneNode(now time.Time) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.controlPlaneNode, c.controlPlaneNode != "" && now.Sub(c.controlPlaneProbedAt) < k8sdProxyProbeInterval
+}
+
+// setControlPlaneNode remembers the control plane node that answered through its k8sd proxy.
+// An empty node name forgets the last node.
+func (c *k8sdClientCache) setControlPlaneNode(nodeName string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.controlPlaneNode = nodeName
+	c.controlPlaneProbedAt = now
+}
+
+func (c *k8sdClientCache) touch(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastUsed = now
+}
+
+func (c *k8sdClientCache) idleSince(now time.Time) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return now.Sub(c.lastUsed)
+}
+
+// close stops the informers and the idle connections of the cached clients.
+func (c *k8sdClientCache) close() {
+	close(c.stop)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, client := range c.clients {
+		client.Client.CloseIdleConnections()
+	}
+	c.clients = map[string]*K8sdClient{}
+}
+
+// deletedObject returns the deleted object of a delete event, that may be a tombstone if the deletion was missed.
+func deletedObject(obj any) any {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return tombstone.Obj
+	}
+	return obj
+}
+
+// sameRESTConfig returns true if both configurations reach the same API server with the same credentials.
+func sameRESTConfig(a, b *rest.Config) bool {
+	return a.Host == b.Host &&
+		a.BearerToken == b.BearerToken &&
+		bytes.Equal(a.CAData, b.CAData) &&
+		bytes.Equal(a.CertData, b.CertData) &&
+		bytes.Equal(a.KeyData, b.KeyData)
+}
@@ -0,0 +1,179 @@
+package ck8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newK8sdProxyPod(name, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceSystem,
+			Labels:    map[string]string{"app": "k8sd-proxy"},
+		},
+		Spec: corev1.PodSpec{NodeName: nodeName},
+	}
+}
+
+func newK8sdNode(name, ip string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      map[string]string{labelNodeRoleControlPlane: ""},
+			Annotations: map[string]string{K8sdCertificateFingerprintAnnotation: "0123456789"},
+		},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: ip}},
+		},
+	}
+}
+
+func TestK8sdClientCache(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	clientset := fake.NewSimpleClientset(
+		newK8sdProxyPod("k8sd-proxy-1", "node-1"),
+		newK8sdProxyPod("k8sd-proxy-2", "node-2"),
+		newK8sdNode("node-1", "10.0.0.1"),
+		newK8sdNode("node-2", "10.0.0.2"),
+	)
+
+	c, err := newK8sdClientCache(&rest.Config{Host: "https://127.0.0.1:6443"}, clientset)
+	g.Expect(err).ToNot(HaveOccurred())
+	defer c.close()
+	g.Eventually(c.synced).Should(BeTrue())
+
+	generator := &k8sdClientGenerator{
+		restConfig: &rest.Config{Host: "https://127.0.0.1:6443"},
+		cache:      c,
+	}
+
+	podmap, err := generator.getProxyPods(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(podmap).To(HaveLen(2))
+	g.Expect(podmap["node-1"].Name).To(Equal("k8sd-proxy-1"))
+
+	nodes, err := c.getNodes(map[string]string{labelNodeRoleControlPlane: ""})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(nodes.Items).To(HaveLen(2))
+
+	node, err := c.getNode("node-1")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	t.Run("reuses clients", func(t *testing.T) {
+		g := NewWithT(t)
+
+		first, err := generator.forNodePod(ctx, node, "k8sd-proxy-1")
+		g.Expect(err).ToNot(HaveOccurred())
+		second, err := generator.forNodePod(ctx, node, "k8sd-proxy-1")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(second).To(BeIdenticalTo(first))
+	})
+
+	t.Run("rebuilds clients of changed nodes", func(t *testing.T) {
+		g := NewWithT(t)
+
+		first, err := generator.forNodePod(ctx, node, "k8sd-proxy-1")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		changed := node.DeepCopy()
+		changed.Annotations = map[string]string{K8sdCertificateFingerprintAnnotation: "abcdef"}
+		second, err := generator.forNodePod(ctx, changed, "k8sd-proxy-1")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(second).ToNot(BeIdenticalTo(first))
+		g.Expect(second.fingerprint).To(Equal("abcdef"))
+
+		third, err := generator.forNodePod(ctx, changed, "k8sd-proxy-1-new")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(third).ToNot(BeIdenticalTo(second))
+	})
+
+	t.Run("drops clients of deleted pods", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := generator.forNodePod(ctx, node, "k8sd-proxy-1")
+		g.Expect(err).ToNot(HaveOccurred())
+		c.setControlPlaneNode("node-1", time.Now())
+
+		g.Expect(clientset.CoreV1().Pods(metav1.NamespaceSystem).Delete(ctx, "k8sd-proxy-1", metav1.DeleteOptions{})).To(Succeed())
+		g.Eventually(func() bool {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			_, ok := c.clients["node-1"]
+			return ok
+		}).Should(BeFalse())
+
+		lastNode, _ := c.getControlPlaneNode(time.Now())
+		g.Expect(lastNode).To(BeEmpty())
+	})
+}
+
+func TestK8sdClientCacheControlPlaneNode(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	c := &k8sdClientCache{clients: map[string]*K8sdClient{}}
+
+	node, fresh := c.getControlPlaneNode(now)
+	g.Expect(node).To(BeEmpty())
+	g.Expect(fresh).To(BeFalse())
+
+	c.setControlPlaneNode("node-1", now)
+	node, fresh = c.getControlPlaneNode(now.Add(time.Second))
+	g.Expect(node).To(Equal("node-1"))
+	g.Expect(fresh).To(BeTrue())
+
+	node, fresh = c.getControlPlaneNode(now.Add(k8sdProxyProbeInterval))
+	g.Expect(node).To(Equal("node-1"))
+	g.Expect(fresh).To(BeFalse())
+}
+
+func TestSweepK8sdClientCaches(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	idle := &k8sdClientCache{stop: make(chan struct{}), clients: map[string]*K8sdClient{}, lastUsed: now.Add(-k8sdClientCacheIdleTimeout - time.Second)}
+	used := &k8sdClientCache{stop: make(chan struct{}), clients: map[string]*K8sdClient{}, lastUsed: now}
+	idleKey := client.ObjectKey{Namespace: "default", Name: "deleted"}
+	usedKey := client.ObjectKey{Namespace: "default", Name: "used"}
+
+	k8sdClientCaches.Lock()
+	k8sdClientCaches.caches[idleKey] = idle
+	k8sdClientCaches.caches[usedKey] = used
+	k8sdClientCaches.Unlock()
+	defer func() {
+		k8sdClientCaches.Lock()
+		defer k8sdClientCaches.Unlock()
+		delete(k8sdClientCaches.caches, usedKey)
+		used.close()
+	}()
+
+	sweepK8sdClientCaches(now)
+
+	k8sdClientCaches.Lock()
+	defer k8sdClientCaches.Unlock()
+	g.Expect(k8sdClientCaches.caches).ToNot(HaveKey(idleKey))
+	g.Expect(k8sdClientCaches.caches).To(HaveKey(usedKey))
+	g.Expect(idle.stop).To(BeClosed())
+}
+
+func TestSameRESTConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	a := &rest.Config{Host: "https://10.0.0.1:6443", TLSClientConfig: rest.TLSClientConfig{CertData: []byte("cert")}}
+	g.Expect(sameRESTConfig(a, rest.CopyConfig(a))).To(BeTrue())
+
+	b := rest.CopyConfig(a)
+	b.CertData = []byte("rotated")
+	g.Expect(sameRESTConfig(a, b)).To(BeFalse())
+}
@@ -0,0 +1,119 @@
+package ck8s
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/canonical/cluster-api-k8s/version"
+)
+
+const (
+	// K8sdProxySpecHashAnnotation is the annotation on the k8sd-proxy daemonset recording the hash of the spec
+	// last applied by the controllers, so that changes of the configuration are rolled out.
+	K8sdProxySpecHashAnnotation = "controlplane.cluster.x-k8s.io/k8sd-proxy-spec-hash"
+
+	// K8sdProxyProviderVersionAnnotation is the annotation on the k8sd-proxy daemonset recording the version
+	// of the provider that last applied it.
+	K8sdProxyProviderVersionAnnotation = "controlplane.cluster.x-k8s.io/k8sd-proxy-provider-version"
+)
+
+// ReconcileK8sdProxy creates the k8sd-proxy configmap and daemonset in the workload cluster, and updates them
+// if they drifted from the configuration, e.g. they were edited or the provider was upgraded.
+// It returns true if any object was created or updated.
+func (w *Workload) ReconcileK8sdProxy(ctx context.Context, input K8sdProxyDaemonSetInput) (bool, error) {
+	configMap, daemonSet, err := RenderK8sdProxyObjects(input)
+	if err != nil {
+		return false, fmt.Errorf("failed to render k8sd-proxy: %w", err)
+	}
+
+	specJSON, err := json.Marshal(daemonSet.Spec)
+	if err != nil {
+		return false, fmt.Errorf("failed to hash k8sd-proxy daemonset spec: %w", err)
+	}
+	sum := sha256.Sum256(specJSON)
+	daemonSet.Annotations = map[string]string{
+		K8sdProxySpecHashAnnotation:        hex.EncodeToString(sum[:]),
+		K8sdProxyProviderVersionAnnotation: version.Get().String(),
+	}
+
+	changed := false
+
+	existingConfigMap := &corev1.ConfigMap{}
+	switch err := w.Client.Get(ctx, client.ObjectKeyFromObject(configMap), existingConfigMap); {
+	case apierrors.IsNotFound(err):
+		if err := w.Client.Create(ctx, configMap); err != nil {
+			return false, fmt.Errorf("failed to create k8sd-proxy configmap: %w", err)
+		}
+		changed = true
+	case err != nil:
+		return false, fmt.Errorf("failed to get k8sd-proxy configmap: %w", err)
+	case !equality.Semantic.DeepEqual(configMap.Data, existingConfigMap.Data):
+		existingConfigMap.Data = configMap.Data
+		if err := w.Client.Update(ctx, existingConfigMap); err != nil {
+			return false, fmt.Errorf("failed to update k8sd-proxy configmap: %w", err)
+		}
+		changed = true
+	}
+
+	existingDaemonSet := &appsv1.DaemonSet{}
+	switch err := w.Client.Get(ctx, client.ObjectKeyFromObject(daemonSet), existingDaemonSet); {
+	case apierrors.IsNotFound(err):
+		if err := w.Client.Create(ctx, daemonSet); err != nil {
+			return false, fmt.Errorf("failed to create k8sd-proxy daemonset: %w", err)
+		}
+		changed = true
+	case err != nil:
+		return false, fmt.Errorf("failed to get k8sd-proxy daemonset: %w", err)
+	case !k8sdProxyDaemonSetUpToDate(daemonSet, existingDaemonSet):
+		// NOTE: The fields that are defaulted by the API server are left unset in the desired spec,
+		// so that they do not count as drift.
+		existingDaemonSet.Spec = daemonSet.Spec
+		if existingDaemonSet.Annotations == nil {
+			existingDaemonSet.Annotations = map[string]string{}
+		}
+		for k, v := range daemonSet.Annotations {
+			existingDaemonSet.Annotations[k] = v
+		}
+		if existingDaemonSet.Labels == nil {
+			existingDaemonSet.Labels = map[string]string{}
+		}
+		for k, v := range daemonSet.Labels {
+			existingDaemonSet.Labels[k] = v
+		}
+		if err := w.Client.Update(ctx, existingDaemonSet); err != nil {
+			return false, fmt.Errorf("failed to update k8sd-proxy daemonset: %w", err)
+		}
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// k8sdProxyDaemonSetUpToDate returns true if the existing daemonset was applied with the same configuration and
+// provider version, and was not edited since.
+func k8sdProxyDaemonSetUpToDate(desired, existing *appsv1.DaemonSet) bool {
+	for _, annotation := range []string{K8sdProxySpecHashAnnotation, K8sdProxyProviderVersionAnnotation} {
+		if desired.Annotations[annotation] != existing.Annotations[annotation] {
+			return false
+		}
+	}
+
+	// NOTE: DeepDerivative ignores the elements appended to the lists of the existing spec, so added containers
+	// and tolerations are checked for explicitly. Tolerations are not defaulted by the API server.
+	desiredPod, existingPod := desired.Spec.Template.Spec, existing.Spec.Template.Spec
+	if len(desiredPod.Containers) != len(existingPod.Containers) ||
+		len(desiredPod.InitContainers) != len(existingPod.InitContainers) ||
+		!equality.Semantic.DeepEqual(desiredPod.Tolerations, existingPod.Tolerations) {
+		return false
+	}
+	return equality.Semantic.DeepDerivative(desired.Spec, existing.Spec)
+}
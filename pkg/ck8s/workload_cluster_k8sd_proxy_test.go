@@ -0,0 +1,181 @@
+package ck8s
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
+)
+
+func TestNewK8sdProxyDaemonSetInput(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		spec          *controlplanev1.K8sdProxySpec
+		expectedImage string
+	}{
+		{name: "default", expectedImage: "ghcr.io/canonical/cluster-api-k8s/socat:1.8.0.0"},
+		{name: "registry", spec: &controlplanev1.K8sdProxySpec{ImageRegistry: "mirror.local:5000/"}, expectedImage: "mirror.local:5000/canonical/cluster-api-k8s/socat:1.8.0.0"},
+		{name: "image", spec: &controlplanev1.K8sdProxySpec{Image: "socat:custom", ImageRegistry: "mirror.local"}, expectedImage: "socat:custom"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			_, daemonSet, err := RenderK8sdProxyObjects(NewK8sdProxyDaemonSetInput(2380, tc.spec))
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(daemonSet.Spec.Template.Spec.Containers).To(HaveLen(1))
+			g.Expect(daemonSet.Spec.Template.Spec.Containers[0].Image).To(Equal(tc.expectedImage))
+		})
+	}
+}
+
+func TestRenderK8sdProxyObjects(t *testing.T) {
+	g := NewWithT(t)
+
+	configMap, daemonSet, err := RenderK8sdProxyObjects(NewK8sdProxyDaemonSetInput(6400, &controlplanev1.K8sdProxySpec{
+		Resources: &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("32Mi")},
+		},
+		Tolerations:       []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+		PriorityClassName: "system-node-critical",
+	}))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(configMap.Data).To(HaveKeyWithValue("k8sd-port", "6400"))
+
+	podSpec := daemonSet.Spec.Template.Spec
+	g.Expect(podSpec.PriorityClassName).To(Equal("system-node-critical"))
+	g.Expect(podSpec.Tolerations).To(HaveLen(3))
+	g.Expect(podSpec.Tolerations[2].Key).To(Equal("dedicated"))
+	g.Expect(podSpec.Containers[0].Resources.Requests.Memory().String()).To(Equal("32Mi"))
+	g.Expect(podSpec.Containers[0].Args).To(HaveLen(1))
+	g.Expect(podSpec.Containers[0].Args[0]).To(ContainSubstring("/capi/k8sd-proxy/k8sd-address"))
+	g.Expect(podSpec.Containers[0].Args[0]).To(ContainSubstring("TCP6-LISTEN:2380,ipv6only=0"))
+	g.Expect(podSpec.Volumes).To(HaveLen(1))
+	g.Expect(podSpec.Volumes[0].HostPath.Path).To(Equal("/capi/k8sd-proxy"))
+
+	manifest, err := RenderK8sdProxyDaemonSetManifest(NewK8sdProxyDaemonSetInput(6400, nil))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(manifest)).ToNot(ContainSubstring("creationTimestamp"))
+	g.Expect(string(manifest)).ToNot(ContainSubstring("status:\n"))
+}
+
+func TestReconcileK8sdProxy(t *testing.T) {
+	ctx := context.Background()
+	key := client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: "k8sd-proxy"}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	w := &Workload{Client: c}
+	input := NewK8sdProxyDaemonSetInput(2380, nil)
+
+	t.Run("creates", func(t *testing.T) {
+		g := NewWithT(t)
+
+		changed, err := w.ReconcileK8sdProxy(ctx, input)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(changed).To(BeTrue())
+
+		daemonSet := &appsv1.DaemonSet{}
+		g.Expect(c.Get(ctx, key, daemonSet)).To(Succeed())
+		g.Expect(daemonSet.Annotations).To(HaveKey(K8sdProxySpecHashAnnotation))
+		g.Expect(c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: "k8sd-proxy-config"}, &corev1.ConfigMap{})).To(Succeed())
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		g := NewWithT(t)
+
+		changed, err := w.ReconcileK8sdProxy(ctx, input)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(changed).To(BeFalse())
+	})
+
+	t.Run("corrects drift", func(t *testing.T) {
+		g := NewWithT(t)
+
+		daemonSet := &appsv1.DaemonSet{}
+		g.Expect(c.Get(ctx, key, daemonSet)).To(Succeed())
+		daemonSet.Spec.Template.Spec.Containers[0].Image = "edited"
+		g.Expect(c.Update(ctx, daemonSet)).To(Succeed())
+
+		changed, err := w.ReconcileK8sdProxy(ctx, input)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(changed).To(BeTrue())
+
+		g.Expect(c.Get(ctx, key, daemonSet)).To(Succeed())
+		g.Expect(daemonSet.Spec.Template.Spec.Containers[0].Image).To(Equal("ghcr.io/canonical/cluster-api-k8s/socat:1.8.0.0"))
+	})
+
+	for _, tc := range []struct {
+		name string
+		edit func(podSpec *corev1.PodSpec)
+	}{
+		{
+			name: "added container",
+			edit: func(podSpec *corev1.PodSpec) {
+				podSpec.Containers = append(podSpec.Containers, corev1.Container{Name: "sidecar", Image: "sidecar"})
+			},
+		},
+		{
+			name: "added toleration",
+			edit: func(podSpec *corev1.PodSpec) {
+				podSpec.Tolerations = append(podSpec.Tolerations, corev1.Toleration{Operator: corev1.TolerationOpExists})
+			},
+		},
+	} {
+		t.Run("corrects "+tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			daemonSet := &appsv1.DaemonSet{}
+			g.Expect(c.Get(ctx, key, daemonSet)).To(Succeed())
+			expected := daemonSet.Spec.Template.Spec.DeepCopy()
+			tc.edit(&daemonSet.Spec.Template.Spec)
+			g.Expect(c.Update(ctx, daemonSet)).To(Succeed())
+
+			changed, err := w.ReconcileK8sdProxy(ctx, input)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(changed).To(BeTrue())
+
+			g.Expect(c.Get(ctx, key, daemonSet)).To(Succeed())
+			g.Expect(daemonSet.Spec.Template.Spec.Containers).To(Equal(expected.Containers))
+			g.Expect(daemonSet.Spec.Template.Spec.Tolerations).To(Equal(expected.Tolerations))
+		})
+	}
+
+	t.Run("recreates", func(t *testing.T) {
+		g := NewWithT(t)
+
+		g.Expect(c.Delete(ctx, &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}})).To(Succeed())
+
+		changed, err := w.ReconcileK8sdProxy(ctx, input)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(changed).To(BeTrue())
+		g.Expect(c.Get(ctx, key, &appsv1.DaemonSet{})).To(Succeed())
+	})
+
+	t.Run("rolls out configuration changes", func(t *testing.T) {
+		g := NewWithT(t)
+
+		changed, err := w.ReconcileK8sdProxy(ctx, NewK8sdProxyDaemonSetInput(2380, &controlplanev1.K8sdProxySpec{PriorityClassName: "system-node-critical"}))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(changed).To(BeTrue())
+
+		daemonSet := &appsv1.DaemonSet{}
+		g.Expect(c.Get(ctx, key, daemonSet)).To(Succeed())
+		g.Expect(daemonSet.Spec.Template.Spec.PriorityClassName).To(Equal("system-node-critical"))
+
+		// NOTE: Unsetting a field is a configuration change too, even though the existing spec still derives from the new one.
+		changed, err = w.ReconcileK8sdProxy(ctx, input)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(changed).To(BeTrue())
+		g.Expect(c.Get(ctx, key, daemonSet)).To(Succeed())
+		g.Expect(daemonSet.Spec.Template.Spec.PriorityClassName).To(BeEmpty())
+	})
+}
@@ -64,6 +64,7 @@ func NewInitControlPlane(input InitControlPlaneInput) (CloudConfig, error) {
 		"/capi/scripts/deploy-manifests.sh",
 		"/capi/scripts/configure-auth-token.sh",
 		"/capi/scripts/configure-node-token.sh",
+		"/capi/scripts/publish-k8sd-info.sh",
 		"/capi/scripts/create-sentinel-bootstrap.sh",
 	)
 	config.RunCommands = append(config.RunCommands, input.PostRunCommands...)
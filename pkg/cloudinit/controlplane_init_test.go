@@ -74,6 +74,7 @@ func TestNewInitControlPlane(t *testing.T) {
 		"/capi/scripts/deploy-manifests.sh",
 		"/capi/scripts/configure-auth-token.sh",
 		"/capi/scripts/configure-node-token.sh",
+		"/capi/scripts/publish-k8sd-info.sh",
 		"/capi/scripts/create-sentinel-bootstrap.sh",
 		"postrun1",
 		"postrun2",
@@ -93,6 +94,7 @@ func TestNewInitControlPlane(t *testing.T) {
 		HaveField("Path", "/capi/scripts/configure-node-token.sh"),
 		HaveField("Path", "/capi/scripts/create-sentinel-bootstrap.sh"),
 		HaveField("Path", "/capi/scripts/configure-snapstore-proxy.sh"),
+		HaveField("Path", "/capi/scripts/publish-k8sd-info.sh"),
 		HaveField("Path", "/capi/etc/config.yaml"),
 		HaveField("Path", "/capi/etc/microcluster-address"),
 		HaveField("Path", "/capi/etc/node-name"),
@@ -51,6 +51,7 @@ func NewJoinControlPlane(input JoinControlPlaneInput) (CloudConfig, error) {
 		"/capi/scripts/join-cluster.sh",
 		"/capi/scripts/wait-apiserver-ready.sh",
 		"/capi/scripts/configure-node-token.sh",
+		"/capi/scripts/publish-k8sd-info.sh",
 		"/capi/scripts/create-sentinel-bootstrap.sh",
 	)
 	config.RunCommands = append(config.RunCommands, input.PostRunCommands...)
@@ -52,6 +52,7 @@ func TestNewJoinControlPlane(t *testing.T) {
 		"/capi/scripts/join-cluster.sh",
 		"/capi/scripts/wait-apiserver-ready.sh",
 		"/capi/scripts/configure-node-token.sh",
+		"/capi/scripts/publish-k8sd-info.sh",
 		"/capi/scripts/create-sentinel-bootstrap.sh",
 		"postrun1",
 		"postrun2",
@@ -71,6 +72,7 @@ func TestNewJoinControlPlane(t *testing.T) {
 		HaveField("Path", "/capi/scripts/configure-node-token.sh"),
 		HaveField("Path", "/capi/scripts/create-sentinel-bootstrap.sh"),
 		HaveField("Path", "/capi/scripts/configure-snapstore-proxy.sh"),
+		HaveField("Path", "/capi/scripts/publish-k8sd-info.sh"),
 		HaveField("Path", "/capi/etc/config.yaml"),
 		HaveField("Path", "/capi/etc/microcluster-address"),
 		HaveField("Path", "/capi/etc/node-name"),
@@ -27,6 +27,7 @@ var (
 	scriptDeployManifests         script = "deploy-manifests.sh"
 	scriptCreateSentinelBootstrap script = "create-sentinel-bootstrap.sh"
 	scriptConfigureSnapstoreProxy script = "configure-snapstore-proxy.sh"
+	scriptPublishK8sdInfo         script = "publish-k8sd-info.sh"
 )
 
 func mustEmbed(s script) string {
@@ -52,5 +53,6 @@ var (
 		scriptDeployManifests:         mustEmbed(scriptDeployManifests),
 		scriptCreateSentinelBootstrap: mustEmbed(scriptCreateSentinelBootstrap),
 		scriptConfigureSnapstoreProxy: mustEmbed(scriptConfigureSnapstoreProxy),
+		scriptPublishK8sdInfo:         mustEmbed(scriptPublishK8sdInfo),
 	}
 )
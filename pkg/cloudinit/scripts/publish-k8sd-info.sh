@@ -0,0 +1,61 @@
+#!/bin/bash -xe
+
+## Assumptions:
+## - k8s is installed and the node joined the cluster
+## - /capi/etc/node-name contains the name of the node
+## - /capi/etc/microcluster-address contains the address configured for microcluster
+## - the kubelet kubeconfig can annotate the node
+
+name="$(cat /capi/etc/node-name)"
+certificate="/var/snap/k8s/common/var/lib/k8sd/state/cluster.crt"
+daemon_config="/var/snap/k8s/common/var/lib/k8sd/state/daemon.yaml"
+address_file="/capi/k8sd-proxy/k8sd-address"
+kubeconfig="/etc/kubernetes/kubelet.conf"
+
+fingerprint="$(openssl x509 -in "${certificate}" -noout -fingerprint -sha256 | cut -d= -f2 | tr -d : | tr '[:upper:]' '[:lower:]')"
+annotations=("v1beta2.k8sd.io/certificate-fingerprint=${fingerprint}")
+
+# strip_port turns "10.0.0.1:2380", "[fd00::1]:2380" or "[fd00::1]" into the bare IP address.
+# Bare IP addresses, e.g. "fd00::1", are left as is.
+strip_port() {
+  tr -d '"' | sed -E -e 's/^\[([^]]*)\](:[0-9]+)?$/\1/' -e 's/^([0-9.]+):[0-9]+$/\1/'
+}
+
+# The microcluster address may be configured as a CIDR, so the address k8sd listens on is read from its state.
+address="$(sed -n 's/^address: *//p' "${daemon_config}" 2>/dev/null | strip_port)"
+if [ -z "${address}" ]; then
+  address="$(strip_port < /capi/etc/microcluster-address)"
+  if [[ "${address}" == */* ]]; then
+    address=""
+  fi
+fi
+
+# The k8sd-proxy pods read the address of k8sd on their node from the address file.
+# It is kept out of /capi/etc, so that the pods do not mount the tokens of the node.
+if [ -n "${address}" ]; then
+  mkdir -p "$(dirname "${address_file}")"
+  echo "${address}" > "${address_file}"
+  annotations+=("v1beta2.k8sd.io/microcluster-address=${address}")
+fi
+
+# The providers roll back failed in-place upgrades to the published snap release.
+# Snaps installed from a file have no tracked channel, they are refreshed back to their revision.
+read -r revision tracking < <(snap list k8s | awk 'NR == 2 { print $3, $4 }') || true
+if [ -n "${tracking}" ] && [ "${tracking}" != "-" ]; then
+  annotations+=("v1beta2.k8sd.io/snap-release=channel=${tracking}")
+elif [ -n "${revision}" ]; then
+  annotations+=("v1beta2.k8sd.io/snap-release=revision=${revision}")
+fi
+
+# The node object is created by the kubelet, so it may not exist yet.
+for _ in $(seq 300); do
+  if /snap/k8s/current/bin/kubectl --kubeconfig "${kubeconfig}" annotate node "${name}" --overwrite "${annotations[@]}"; then
+    exit 0
+  fi
+  echo "node ${name} not yet registered"
+  sleep 1
+done
+
+# NOTE: Failing to publish is not fatal, the providers record the k8sd certificate on their first connection instead.
+echo "failed to publish the k8sd information of node ${name}"
+exit 0
@@ -0,0 +1,48 @@
+package cloudinit_test
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestPublishK8sdInfoStripPort(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not available")
+	}
+
+	g := NewWithT(t)
+
+	script, err := os.ReadFile("scripts/publish-k8sd-info.sh")
+	g.Expect(err).ToNot(HaveOccurred())
+	stripPort := regexp.MustCompile(`(?ms)^strip_port\(\) \{.*?^\}`).Find(script)
+	g.Expect(stripPort).ToNot(BeNil())
+
+	for _, tc := range []struct {
+		address  string
+		expected string
+	}{
+		{address: "10.0.0.1:2380", expected: "10.0.0.1"},
+		{address: `"10.0.0.1:2380"`, expected: "10.0.0.1"},
+		{address: "10.0.0.1", expected: "10.0.0.1"},
+		{address: "[fd00::1]:2380", expected: "fd00::1"},
+		{address: "[fd00::1]", expected: "fd00::1"},
+		{address: "fd00::1", expected: "fd00::1"},
+		{address: "2001:db8:0:0:0:0:0:1", expected: "2001:db8:0:0:0:0:0:1"},
+		{address: "10.0.0.0/24", expected: "10.0.0.0/24"},
+	} {
+		t.Run(tc.address, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cmd := exec.Command("bash", "-c", string(stripPort)+"\nstrip_port")
+			cmd.Stdin = strings.NewReader(tc.address + "\n")
+			out, err := cmd.Output()
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(strings.TrimSpace(string(out))).To(Equal(tc.expected))
+		})
+	}
+}
@@ -50,6 +50,7 @@ func NewJoinWorker(input JoinWorkerInput) (CloudConfig, error) {
 		"/capi/scripts/load-images.sh",
 		"/capi/scripts/join-cluster.sh",
 		"/capi/scripts/configure-node-token.sh",
+		"/capi/scripts/publish-k8sd-info.sh",
 		"/capi/scripts/create-sentinel-bootstrap.sh",
 	)
 	config.RunCommands = append(config.RunCommands, input.PostRunCommands...)
@@ -0,0 +1,81 @@
+package kubeconfig
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ClusterAdminUserPrefix prefixes the users of the kubeconfigs bound to the cluster-admin ClusterRole.
+	ClusterAdminUserPrefix = "ck8s-capi-admin-"
+
+	// ClusterAdminBindingLabel labels the ClusterRoleBindings of the users of the kubeconfigs in the workload cluster.
+	ClusterAdminBindingLabel = "v1beta2.k8sd.io/admin-kubeconfig"
+)
+
+// NewClusterAdminIdentity returns a new user to bind to the cluster-admin ClusterRole.
+// Every user is unique, so that the access of the previous kubeconfigs can be revoked.
+func NewClusterAdminIdentity(now time.Time) Identity {
+	return Identity{User: fmt.Sprintf("%s%d", ClusterAdminUserPrefix, now.UnixNano())}
+}
+
+// IsClusterAdminIdentity checks if the client certificate authenticates as a user bound to the cluster-admin ClusterRole.
+func IsClusterAdminIdentity(cert *x509.Certificate) bool {
+	return strings.HasPrefix(cert.Subject.CommonName, ClusterAdminUserPrefix) && len(cert.Subject.Organization) == 0
+}
+
+// EnsureClusterAdminBinding binds the user to the cluster-admin ClusterRole in the workload cluster.
+func EnsureClusterAdminBinding(ctx context.Context, c client.Client, user string) error {
+	binding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: user,
+			Labels: map[string]string{
+				ClusterAdminBindingLabel: "true",
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     "cluster-admin",
+		},
+		Subjects: []rbacv1.Subject{{
+			APIGroup: rbacv1.GroupName,
+			Kind:     rbacv1.UserKind,
+			Name:     user,
+		}},
+	}
+
+	if err := c.Create(ctx, binding); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create ClusterRoleBinding %s: %w", user, err)
+	}
+	return nil
+}
+
+// RevokeClusterAdminBindings deletes the ClusterRoleBindings of the users of the kubeconfigs
+// in the workload cluster, except the ones of the users to keep.
+func RevokeClusterAdminBindings(ctx context.Context, c client.Client, keep ...string) error {
+	bindings := &rbacv1.ClusterRoleBindingList{}
+	if err := c.List(ctx, bindings, client.HasLabels{ClusterAdminBindingLabel}); err != nil {
+		return fmt.Errorf("failed to list ClusterRoleBindings: %w", err)
+	}
+
+	for i := range bindings.Items {
+		binding := &bindings.Items[i]
+		if slices.Contains(keep, binding.Name) {
+			continue
+		}
+		if err := c.Delete(ctx, binding); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete ClusterRoleBinding %s: %w", binding.Name, err)
+		}
+	}
+	return nil
+}
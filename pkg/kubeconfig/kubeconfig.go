@@ -3,13 +3,20 @@ package kubeconfig
 import (
 	"context"
 	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -20,13 +27,56 @@ import (
 	"github.com/canonical/cluster-api-k8s/pkg/secret"
 )
 
+// adminRESTConfigValidity is the validity of the client certificates of NewAdminRESTConfig.
+const adminRESTConfigValidity = 10 * time.Minute
+
 var (
 	ErrDependentCertificateNotFound = errors.New("could not find secret ca")
 	ErrCertNotInKubeconfig          = errors.New("certificate not found in config")
 	ErrCAPrivateKeyNotFound         = errors.New("CA private key not found")
 )
 
-func generateKubeconfig(ctx context.Context, c client.Client, clusterName client.ObjectKey, endpoint string) ([]byte, error) {
+// Identity is the user that the client certificate of a kubeconfig authenticates as.
+type Identity struct {
+	// User is the common name of the client certificate.
+	User string
+	// Groups are the organizations of the client certificate.
+	Groups []string
+}
+
+// SystemMastersIdentity is a member of the system:masters group, which cannot be restricted by RBAC.
+var SystemMastersIdentity = Identity{User: "kubernetes-admin", Groups: []string{"system:masters"}}
+
+// Options configure the client certificate of a kubeconfig.
+type Options struct {
+	// Identity is the user that the kubeconfig authenticates as.
+	Identity Identity
+	// Validity is how long the client certificate is valid.
+	Validity time.Duration
+}
+
+// DefaultOptions returns the options of a kubeconfig for a member of system:masters, valid for a year.
+func DefaultOptions() Options {
+	return Options{
+		Identity: SystemMastersIdentity,
+		Validity: certs.DefaultCertDuration,
+	}
+}
+
+func generateKubeconfig(ctx context.Context, c client.Client, clusterName client.ObjectKey, endpoint string, opts Options) ([]byte, error) {
+	cfg, err := generateConfig(ctx, c, clusterName, endpoint, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := clientcmd.Write(*cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize config to yaml: %w", err)
+	}
+	return out, nil
+}
+
+func generateConfig(ctx context.Context, c client.Client, clusterName client.ObjectKey, endpoint string, opts Options) (*api.Config, error) {
 	clusterCA, err := secret.GetFromNamespacedName(ctx, c, clusterName, secret.ClusterCA)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
@@ -64,32 +114,27 @@ func generateKubeconfig(ctx context.Context, c client.Client, clusterName client
 		return nil, ErrCertNotInKubeconfig
 	}
 
-	cfg, err := New(clusterName.Name, endpoint, clientCACert, clientCAKey, serverCACert)
+	cfg, err := NewWithOptions(clusterName.Name, endpoint, clientCACert, clientCAKey, serverCACert, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate a kubeconfig: %w", err)
 	}
-
-	out, err := clientcmd.Write(*cfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to serialize config to yaml: %w", err)
-	}
-	return out, nil
+	return cfg, nil
 }
 
 // New creates a new Kubeconfig using the cluster name and specified endpoint.
 func New(clusterName, endpoint string, clientCACert *x509.Certificate, clientCAKey crypto.Signer, serverCACert *x509.Certificate) (*api.Config, error) {
-	cfg := &certs.Config{
-		CommonName:   "kubernetes-admin",
-		Organization: []string{"system:masters"},
-		Usages:       []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
-	}
+	return NewWithOptions(clusterName, endpoint, clientCACert, clientCAKey, serverCACert, DefaultOptions())
+}
 
+// NewWithOptions creates a new Kubeconfig using the cluster name and specified endpoint,
+// with a client certificate for the identity and validity of the options.
+func NewWithOptions(clusterName, endpoint string, clientCACert *x509.Certificate, clientCAKey crypto.Signer, serverCACert *x509.Certificate, opts Options) (*api.Config, error) {
 	clientKey, err := certs.NewPrivateKey()
 	if err != nil {
 		return nil, fmt.Errorf("unable to create private key: %w", err)
 	}
 
-	clientCert, err := cfg.NewSignedCert(clientKey, clientCACert, clientCAKey)
+	clientCert, err := newClientCert(clientKey, clientCACert, clientCAKey, opts)
 	if err != nil {
 		return nil, fmt.Errorf("unable to sign certificate: %w", err)
 	}
@@ -120,6 +165,33 @@ func New(clusterName, endpoint string, clientCACert *x509.Certificate, clientCAK
 	}, nil
 }
 
+// newClientCert creates a client certificate for the identity of the options, signed by the CA.
+func newClientCert(key *rsa.PrivateKey, caCert *x509.Certificate, caKey crypto.Signer, opts Options) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).SetInt64(math.MaxInt64))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	now := time.Now()
+	tmpl := x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:   opts.Identity.User,
+			Organization: opts.Identity.Groups,
+		},
+		SerialNumber: serial,
+		NotBefore:    caCert.NotBefore,
+		NotAfter:     now.Add(opts.Validity).UTC(),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	b, err := x509.CreateCertificate(rand.Reader, &tmpl, caCert, key.Public(), caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signed certificate: %w", err)
+	}
+	return x509.ParseCertificate(b)
+}
+
 // CreateSecret creates the Kubeconfig secret for the given cluster.
 func CreateSecret(ctx context.Context, c client.Client, cluster *clusterv1.Cluster) error {
 	name := util.ObjectKey(cluster)
@@ -128,13 +200,13 @@ func CreateSecret(ctx context.Context, c client.Client, cluster *clusterv1.Clust
 		Kind:       "Cluster",
 		Name:       cluster.Name,
 		UID:        cluster.UID,
-	})
+	}, DefaultOptions())
 }
 
 // CreateSecretWithOwner creates the Kubeconfig secret for the given cluster name, namespace, endpoint, and owner reference.
-func CreateSecretWithOwner(ctx context.Context, c client.Client, clusterName client.ObjectKey, endpoint string, owner metav1.OwnerReference) error {
+func CreateSecretWithOwner(ctx context.Context, c client.Client, clusterName client.ObjectKey, endpoint string, owner metav1.OwnerReference, opts Options) error {
 	server := fmt.Sprintf("https://%s", endpoint)
-	out, err := generateKubeconfig(ctx, c, clusterName, server)
+	out, err := generateKubeconfig(ctx, c, clusterName, server, opts)
 	if err != nil {
 		return err
 	}
@@ -142,6 +214,71 @@ func CreateSecretWithOwner(ctx context.Context, c client.Client, clusterName cli
 	return c.Create(ctx, GenerateSecretWithOwner(clusterName, out, owner))
 }
 
+// RegenerateSecret re-issues the Kubeconfig stored in the secret for the given cluster name and endpoint.
+func RegenerateSecret(ctx context.Context, c client.Client, configSecret *corev1.Secret, clusterName client.ObjectKey, endpoint string, opts Options) error {
+	server := fmt.Sprintf("https://%s", endpoint)
+	out, err := generateKubeconfig(ctx, c, clusterName, server, opts)
+	if err != nil {
+		return err
+	}
+
+	if configSecret.Data == nil {
+		configSecret.Data = map[string][]byte{}
+	}
+	configSecret.Data[secret.KubeconfigDataName] = out
+	return c.Update(ctx, configSecret)
+}
+
+// Generate returns a Kubeconfig for the given cluster name and endpoint, signed by the client CA of the cluster.
+func Generate(ctx context.Context, c client.Client, clusterName client.ObjectKey, endpoint string, opts Options) ([]byte, error) {
+	server := fmt.Sprintf("https://%s", endpoint)
+	return generateKubeconfig(ctx, c, clusterName, server, opts)
+}
+
+// GetClientCertificate returns the client certificate of the current context of the Kubeconfig stored in the secret.
+func GetClientCertificate(configSecret *corev1.Secret) (*x509.Certificate, error) {
+	cfg, err := clientcmd.Load(configSecret.Data[secret.KubeconfigDataName])
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	kubeContext, ok := cfg.Contexts[cfg.CurrentContext]
+	if !ok {
+		return nil, fmt.Errorf("current context %q not found in kubeconfig", cfg.CurrentContext)
+	}
+	authInfo, ok := cfg.AuthInfos[kubeContext.AuthInfo]
+	if !ok {
+		return nil, fmt.Errorf("user %q not found in kubeconfig", kubeContext.AuthInfo)
+	}
+
+	cert, err := certs.DecodeCertPEM(authInfo.ClientCertificateData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode client certificate: %w", err)
+	} else if cert == nil {
+		return nil, ErrCertNotInKubeconfig
+	}
+	return cert, nil
+}
+
+// NewAdminRESTConfig returns a REST config for the given cluster name and endpoint that authenticates as a member
+// of system:masters. Its client certificate is short-lived and only kept in memory.
+func NewAdminRESTConfig(ctx context.Context, c client.Client, clusterName client.ObjectKey, endpoint string) (*rest.Config, error) {
+	server := fmt.Sprintf("https://%s", endpoint)
+	cfg, err := generateConfig(ctx, c, clusterName, server, Options{
+		Identity: SystemMastersIdentity,
+		Validity: adminRESTConfigValidity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	restConfig, err := clientcmd.NewDefaultClientConfig(*cfg, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create REST config: %w", err)
+	}
+	return restConfig, nil
+}
+
 // GenerateSecret returns a Kubernetes secret for the given Cluster and kubeconfig data.
 func GenerateSecret(cluster *clusterv1.Cluster, data []byte) *corev1.Secret {
 	name := util.ObjectKey(cluster)
@@ -0,0 +1,154 @@
+package kubeconfig_test
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/canonical/cluster-api-k8s/pkg/kubeconfig"
+	"github.com/canonical/cluster-api-k8s/pkg/secret"
+)
+
+func newCA(g Gomega) *certs.KeyPair {
+	certificates := secret.Certificates{&secret.Certificate{Purpose: secret.ClusterCA}}
+	g.Expect(certificates.Generate()).To(Succeed())
+	return certificates[0].KeyPair
+}
+
+func TestNewWithOptions(t *testing.T) {
+	g := NewWithT(t)
+
+	ca := newCA(g)
+	caCert, err := certs.DecodeCertPEM(ca.Cert)
+	g.Expect(err).ToNot(HaveOccurred())
+	caKey, err := certs.DecodePrivateKeyPEM(ca.Key)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	tests := []struct {
+		name               string
+		opts               kubeconfig.Options
+		expectClusterAdmin bool
+	}{
+		{
+			name: "system masters",
+			opts: kubeconfig.DefaultOptions(),
+		},
+		{
+			name: "cluster admin",
+			opts: kubeconfig.Options{
+				Identity: kubeconfig.NewClusterAdminIdentity(time.Now()),
+				Validity: 24 * time.Hour,
+			},
+			expectClusterAdmin: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cfg, err := kubeconfig.NewWithOptions("cluster", "https://10.0.0.1:6443", caCert, caKey, caCert, tt.opts)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			data, err := clientcmd.Write(*cfg)
+			g.Expect(err).ToNot(HaveOccurred())
+			cert, err := kubeconfig.GetClientCertificate(&corev1.Secret{Data: map[string][]byte{secret.KubeconfigDataName: data}})
+			g.Expect(err).ToNot(HaveOccurred())
+
+			g.Expect(cert.Subject.CommonName).To(Equal(tt.opts.Identity.User))
+			g.Expect(cert.Subject.Organization).To(Equal(tt.opts.Identity.Groups))
+			g.Expect(cert.ExtKeyUsage).To(Equal([]x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}))
+			g.Expect(cert.NotAfter).To(BeTemporally("~", time.Now().Add(tt.opts.Validity), time.Minute))
+			g.Expect(cert.CheckSignatureFrom(caCert)).To(Succeed())
+			g.Expect(kubeconfig.IsClusterAdminIdentity(cert)).To(Equal(tt.expectClusterAdmin))
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	clusterName := client.ObjectKey{Namespace: "default", Name: "cluster"}
+	opts := kubeconfig.Options{
+		Identity: kubeconfig.Identity{User: "ci", Groups: []string{"viewers"}},
+		Validity: time.Hour,
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	_, err := kubeconfig.Generate(ctx, c, clusterName, "10.0.0.1:6443", opts)
+	g.Expect(err).To(MatchError(kubeconfig.ErrDependentCertificateNotFound))
+
+	certificates := secret.Certificates{
+		&secret.Certificate{Purpose: secret.ClusterCA},
+		&secret.Certificate{Purpose: secret.ClientClusterCA},
+	}
+	g.Expect(certificates.Generate()).To(Succeed())
+	c = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		certificates[0].AsSecret(clusterName, metav1.OwnerReference{}),
+		certificates[1].AsSecret(clusterName, metav1.OwnerReference{}),
+	).Build()
+
+	data, err := kubeconfig.Generate(ctx, c, clusterName, "10.0.0.1:6443", opts)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	cfg, err := clientcmd.Load(data)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cfg.Clusters[clusterName.Name].Server).To(Equal("https://10.0.0.1:6443"))
+	g.Expect(cfg.Clusters[clusterName.Name].CertificateAuthorityData).To(Equal(certificates[0].KeyPair.Cert))
+
+	cert, err := kubeconfig.GetClientCertificate(&corev1.Secret{Data: map[string][]byte{secret.KubeconfigDataName: data}})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cert.Subject.CommonName).To(Equal("ci"))
+	g.Expect(cert.Subject.Organization).To(Equal([]string{"viewers"}))
+	g.Expect(cert.NotAfter).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+
+	clientCACert, err := certs.DecodeCertPEM(certificates[1].KeyPair.Cert)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cert.CheckSignatureFrom(clientCACert)).To(Succeed())
+}
+
+func TestClusterAdminBindings(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	other := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(other).Build()
+
+	users := []string{
+		kubeconfig.ClusterAdminUserPrefix + "1",
+		kubeconfig.ClusterAdminUserPrefix + "2",
+		kubeconfig.ClusterAdminUserPrefix + "3",
+	}
+	for _, user := range users {
+		g.Expect(kubeconfig.EnsureClusterAdminBinding(ctx, c, user)).To(Succeed())
+	}
+	// Binding the same user again is a no-op.
+	g.Expect(kubeconfig.EnsureClusterAdminBinding(ctx, c, users[2])).To(Succeed())
+
+	binding := &rbacv1.ClusterRoleBinding{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Name: users[0]}, binding)).To(Succeed())
+	g.Expect(binding.RoleRef.Name).To(Equal("cluster-admin"))
+	g.Expect(binding.Subjects).To(ConsistOf(rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: users[0]}))
+
+	g.Expect(kubeconfig.RevokeClusterAdminBindings(ctx, c, users[1], users[2])).To(Succeed())
+
+	bindings := &rbacv1.ClusterRoleBindingList{}
+	g.Expect(c.List(ctx, bindings)).To(Succeed())
+	var names []string
+	for _, b := range bindings.Items {
+		names = append(names, b.Name)
+	}
+	g.Expect(names).To(ConsistOf("other", users[1], users[2]))
+}
@@ -0,0 +1,139 @@
+package maintenance
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+)
+
+// Window is a recurring maintenance window, within which new machine operations may start.
+type Window struct {
+	// Schedule is the cron expression for the start of the windows.
+	Schedule string
+	// Duration is how long each window lasts.
+	Duration time.Duration
+	// Location is the time zone of the schedule.
+	Location *time.Location
+
+	schedule cron.Schedule
+}
+
+// NewWindow creates a maintenance window from a cron schedule, a duration and an IANA time zone.
+// An empty time zone means UTC.
+func NewWindow(expr string, duration time.Duration, timeZone string) (*Window, error) {
+	s, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", expr, err)
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("invalid duration %q: must be positive", duration)
+	}
+
+	loc := time.UTC
+	if timeZone != "" {
+		if loc, err = time.LoadLocation(timeZone); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", timeZone, err)
+		}
+	}
+
+	return &Window{
+		Schedule: expr,
+		Duration: duration,
+		Location: loc,
+		schedule: s,
+	}, nil
+}
+
+// GetWindow returns the maintenance window set on the object, or nil if none is set.
+func GetWindow(obj client.Object) (*Window, error) {
+	annotations := obj.GetAnnotations()
+	expr, ok := annotations[bootstrapv1.MaintenanceWindowScheduleAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	v, ok := annotations[bootstrapv1.MaintenanceWindowDurationAnnotation]
+	if !ok {
+		return nil, fmt.Errorf("missing %s annotation", bootstrapv1.MaintenanceWindowDurationAnnotation)
+	}
+	duration, err := time.ParseDuration(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation %q: %w", bootstrapv1.MaintenanceWindowDurationAnnotation, v, err)
+	}
+
+	w, err := NewWindow(expr, duration, annotations[bootstrapv1.MaintenanceWindowTimeZoneAnnotation])
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance window: %w", err)
+	}
+	return w, nil
+}
+
+// ResolveWindow returns the maintenance window of the first object that sets one.
+// This is used to fall back from a CK8sControlPlane or MachineDeployment to its Cluster.
+func ResolveWindow(objs ...client.Object) (*Window, error) {
+	for _, obj := range objs {
+		if obj == nil {
+			continue
+		}
+		w, err := GetWindow(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get maintenance window of %s: %w", obj.GetName(), err)
+		}
+		if w != nil {
+			return w, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// IsOpen checks if the window is open at the given time.
+func (w *Window) IsOpen(now time.Time) bool {
+	// The window is open if it started within the last Duration.
+	// NOTE: The schedule is evaluated in the location of the time it is given.
+	start := w.schedule.Next(now.In(w.Location).Add(-w.Duration))
+	return !start.IsZero() && !start.After(now)
+}
+
+// NextOpen returns when the next window opens after the given time.
+// Returns the zero time if the schedule does not activate within the next five years.
+func (w *Window) NextOpen(now time.Time) time.Time {
+	return w.schedule.Next(now.In(w.Location))
+}
+
+// RequeueAfter returns how long to wait before checking again whether new machine operations may start.
+func (w *Window) RequeueAfter(now time.Time) time.Duration {
+	next := w.NextOpen(now)
+	if next.IsZero() {
+		return time.Hour
+	}
+	return max(next.Sub(now), time.Second)
+}
+
+// Check reports on the object whether the window is open and returns true if new machine operations may start.
+// A nil window is always open.
+func Check(obj conditions.Setter, w *Window, now time.Time) bool {
+	if w == nil {
+		conditions.Delete(obj, bootstrapv1.MaintenanceWindowOpenCondition)
+		return true
+	}
+
+	if w.IsOpen(now) {
+		conditions.MarkTrue(obj, bootstrapv1.MaintenanceWindowOpenCondition)
+		return true
+	}
+
+	next := w.NextOpen(now)
+	if next.IsZero() {
+		conditions.MarkFalse(obj, bootstrapv1.MaintenanceWindowOpenCondition, bootstrapv1.OutsideMaintenanceWindowReason, clusterv1.ConditionSeverityInfo, "Schedule %q never opens a maintenance window", w.Schedule)
+		return false
+	}
+	conditions.MarkFalse(obj, bootstrapv1.MaintenanceWindowOpenCondition, bootstrapv1.OutsideMaintenanceWindowReason, clusterv1.ConditionSeverityInfo, "Next maintenance window opens at %s", next.Format(time.RFC3339))
+	return false
+}
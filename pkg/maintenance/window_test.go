@@ -0,0 +1,157 @@
+package maintenance_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/maintenance"
+)
+
+func mustParse(t *testing.T, v string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		t.Fatalf("failed to parse time %q: %v", v, err)
+	}
+	return parsed
+}
+
+func TestNewWindow(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		schedule  string
+		duration  time.Duration
+		timeZone  string
+		expectErr bool
+	}{
+		{name: "valid", schedule: "0 22 * * FRI", duration: 48 * time.Hour},
+		{name: "validWithTimeZone", schedule: "*/15 1-5 1,15 JAN-JUN *", duration: time.Hour, timeZone: "Europe/Berlin"},
+		{name: "tooFewFields", schedule: "0 22 * *", duration: time.Hour, expectErr: true},
+		{name: "outOfRange", schedule: "60 22 * * *", duration: time.Hour, expectErr: true},
+		{name: "invalidStep", schedule: "*/0 22 * * *", duration: time.Hour, expectErr: true},
+		{name: "zeroDuration", schedule: "0 22 * * *", expectErr: true},
+		{name: "invalidTimeZone", schedule: "0 22 * * *", duration: time.Hour, timeZone: "Mars/Olympus", expectErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			_, err := maintenance.NewWindow(tc.schedule, tc.duration, tc.timeZone)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	// Weekend window, from Friday 22:00 to Sunday 22:00.
+	weekend, err := maintenance.NewWindow("0 22 * * FRI", 48*time.Hour, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Daily window at 02:00 Berlin time.
+	berlin, err := maintenance.NewWindow("0 2 * * *", time.Hour, "Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name           string
+		window         *maintenance.Window
+		now            string
+		expectOpen     bool
+		expectNextOpen string
+	}{
+		{
+			name:           "beforeWindow",
+			window:         weekend,
+			now:            "2024-11-06T12:00:00Z", // Wednesday
+			expectNextOpen: "2024-11-08T22:00:00Z",
+		},
+		{
+			name:           "atStart",
+			window:         weekend,
+			now:            "2024-11-08T22:00:00Z",
+			expectOpen:     true,
+			expectNextOpen: "2024-11-15T22:00:00Z",
+		},
+		{
+			name:           "withinWindow",
+			window:         weekend,
+			now:            "2024-11-10T21:59:00Z",
+			expectOpen:     true,
+			expectNextOpen: "2024-11-15T22:00:00Z",
+		},
+		{
+			name:           "atEnd",
+			window:         weekend,
+			now:            "2024-11-10T22:00:00Z",
+			expectNextOpen: "2024-11-15T22:00:00Z",
+		},
+		{
+			name:           "timeZone",
+			window:         berlin,
+			now:            "2024-11-06T01:30:00Z", // 02:30 in Berlin
+			expectOpen:     true,
+			expectNextOpen: "2024-11-07T01:00:00Z",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			now := mustParse(t, tc.now)
+			g.Expect(tc.window.IsOpen(now)).To(Equal(tc.expectOpen))
+			g.Expect(tc.window.NextOpen(now).Equal(mustParse(t, tc.expectNextOpen))).To(BeTrue())
+		})
+	}
+}
+
+func TestResolveWindow(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{}
+	cluster.SetAnnotations(map[string]string{
+		bootstrapv1.MaintenanceWindowScheduleAnnotation: "0 22 * * SAT",
+		bootstrapv1.MaintenanceWindowDurationAnnotation: "4h",
+	})
+	md := &clusterv1.MachineDeployment{}
+
+	w, err := maintenance.ResolveWindow(md, cluster)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(w).ToNot(BeNil())
+	g.Expect(w.Schedule).To(Equal("0 22 * * SAT"))
+
+	w, err = maintenance.ResolveWindow(md)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(w).To(BeNil())
+
+	md.SetAnnotations(map[string]string{bootstrapv1.MaintenanceWindowScheduleAnnotation: "0 22 * * SAT"})
+	_, err = maintenance.ResolveWindow(md, cluster)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestCheck(t *testing.T) {
+	g := NewWithT(t)
+
+	w, err := maintenance.NewWindow("0 22 * * FRI", 48*time.Hour, "")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	md := &clusterv1.MachineDeployment{}
+
+	g.Expect(maintenance.Check(md, w, mustParse(t, "2024-11-06T12:00:00Z"))).To(BeFalse())
+	g.Expect(conditions.IsFalse(md, bootstrapv1.MaintenanceWindowOpenCondition)).To(BeTrue())
+	g.Expect(conditions.GetMessage(md, bootstrapv1.MaintenanceWindowOpenCondition)).To(ContainSubstring("2024-11-08T22:00:00Z"))
+
+	g.Expect(maintenance.Check(md, w, mustParse(t, "2024-11-09T12:00:00Z"))).To(BeTrue())
+	g.Expect(conditions.IsTrue(md, bootstrapv1.MaintenanceWindowOpenCondition)).To(BeTrue())
+
+	g.Expect(maintenance.Check(md, nil, mustParse(t, "2024-11-06T12:00:00Z"))).To(BeTrue())
+	g.Expect(conditions.Has(md, bootstrapv1.MaintenanceWindowOpenCondition)).To(BeFalse())
+}
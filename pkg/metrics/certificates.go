@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+)
+
+var certificatesExpiryDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "machine", "certificates_expiry_seconds"),
+	"Seconds until the certificates of the machine expire, as recorded on the machine. Negative if they already expired.",
+	[]string{"namespace", "cluster", "machine"}, nil,
+)
+
+var certificatesExpiry = newCertificatesExpiryCollector(time.Now)
+
+// certificatesExpiryCollector reports the time left until the certificates of each machine expire.
+// The time left is computed when the metrics are collected, so that it does not go stale between reconciliations.
+type certificatesExpiryCollector struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	expiries map[types.NamespacedName]machineExpiry
+}
+
+type machineExpiry struct {
+	cluster string
+	expiry  time.Time
+}
+
+func newCertificatesExpiryCollector(now func() time.Time) *certificatesExpiryCollector {
+	return &certificatesExpiryCollector{
+		now:      now,
+		expiries: map[types.NamespacedName]machineExpiry{},
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *certificatesExpiryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- certificatesExpiryDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *certificatesExpiryCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for key, e := range c.expiries {
+		ch <- prometheus.MustNewConstMetric(certificatesExpiryDesc, prometheus.GaugeValue,
+			e.expiry.Sub(now).Seconds(), key.Namespace, e.cluster, key.Name)
+	}
+}
+
+func (c *certificatesExpiryCollector) record(m *clusterv1.Machine) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := types.NamespacedName{Namespace: m.Namespace, Name: m.Name}
+	expiry, err := time.Parse(time.RFC3339, m.Annotations[bootstrapv1.MachineCertificatesExpiryDateAnnotation])
+	if err != nil || !m.DeletionTimestamp.IsZero() {
+		delete(c.expiries, key)
+		return
+	}
+	c.expiries[key] = machineExpiry{cluster: m.Spec.ClusterName, expiry: expiry}
+}
+
+func (c *certificatesExpiryCollector) forget(key types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.expiries, key)
+}
+
+// RecordCertificatesExpiry reports the certificates expiry recorded on the machine.
+// Machines that are being deleted or did not record the expiry are not reported.
+func RecordCertificatesExpiry(m *clusterv1.Machine) {
+	certificatesExpiry.record(m)
+}
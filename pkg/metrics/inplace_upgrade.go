@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+)
+
+var inPlaceUpgradeMachinesDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "inplace_upgrade", "machines"),
+	"Number of machines of the cluster in each phase of the in-place upgrade.",
+	[]string{"namespace", "cluster", "phase"}, nil,
+)
+
+var inPlaceUpgradePhases = newInPlaceUpgradePhaseCollector()
+
+var inPlaceUpgradeDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "inplace_upgrade",
+		Name:      "duration_seconds",
+		Help:      "Duration of the in-place upgrades of machines, from the first attempt until they succeeded or exhausted their retries.",
+		// From one minute to about 17 hours.
+		Buckets: prometheus.ExponentialBuckets(60, 2, 11),
+	},
+	[]string{"namespace", "cluster", "result"},
+)
+
+const (
+	upgradeResultSucceeded = "succeeded"
+	upgradeResultFailed    = "failed"
+)
+
+// inPlaceUpgradePhaseCollector reports the number of machines in each in-place upgrade phase, per cluster.
+type inPlaceUpgradePhaseCollector struct {
+	mu     sync.Mutex
+	phases map[types.NamespacedName]machinePhase
+}
+
+type machinePhase struct {
+	cluster string
+	phase   bootstrapv1.InPlaceUpgradePhase
+}
+
+type clusterPhase struct {
+	namespace string
+	cluster   string
+	phase     bootstrapv1.InPlaceUpgradePhase
+}
+
+func newInPlaceUpgradePhaseCollector() *inPlaceUpgradePhaseCollector {
+	return &inPlaceUpgradePhaseCollector{
+		phases: map[types.NamespacedName]machinePhase{},
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *inPlaceUpgradePhaseCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- inPlaceUpgradeMachinesDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *inPlaceUpgradePhaseCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := map[clusterPhase]int{}
+	for key, p := range c.phases {
+		counts[clusterPhase{namespace: key.Namespace, cluster: p.cluster, phase: p.phase}]++
+	}
+	for p, count := range counts {
+		ch <- prometheus.MustNewConstMetric(inPlaceUpgradeMachinesDesc, prometheus.GaugeValue,
+			float64(count), p.namespace, p.cluster, string(p.phase))
+	}
+}
+
+func (c *inPlaceUpgradePhaseCollector) record(m *clusterv1.Machine, status *bootstrapv1.InPlaceUpgradeStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := types.NamespacedName{Namespace: m.Namespace, Name: m.Name}
+	if status == nil || status.Phase == "" || !m.DeletionTimestamp.IsZero() {
+		delete(c.phases, key)
+		return
+	}
+	c.phases[key] = machinePhase{cluster: m.Spec.ClusterName, phase: status.Phase}
+}
+
+func (c *inPlaceUpgradePhaseCollector) forget(key types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.phases, key)
+}
+
+// RecordInPlaceUpgradeStatus reports the phase of the in-place upgrade of the machine. When the status
+// transitions from previous to a final phase, the duration of the upgrade is observed as well.
+func RecordInPlaceUpgradeStatus(m *clusterv1.Machine, previous, status *bootstrapv1.InPlaceUpgradeStatus) {
+	inPlaceUpgradePhases.record(m, status)
+
+	if status == nil || status.StartedAt == nil {
+		return
+	}
+	if previous != nil && previous.Phase == status.Phase && previous.Release == status.Release {
+		return
+	}
+
+	switch {
+	case status.Phase == bootstrapv1.InPlaceUpgradePhaseSucceeded && status.CompletedAt != nil:
+		inPlaceUpgradeDuration.WithLabelValues(m.Namespace, m.Spec.ClusterName, upgradeResultSucceeded).
+			Observe(status.CompletedAt.Sub(status.StartedAt.Time).Seconds())
+	case status.Phase == bootstrapv1.InPlaceUpgradePhaseRetriesExhausted && status.LastFailedAttemptAt != nil:
+		inPlaceUpgradeDuration.WithLabelValues(m.Namespace, m.Spec.ClusterName, upgradeResultFailed).
+			Observe(status.LastFailedAttemptAt.Sub(status.StartedAt.Time).Seconds())
+	}
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var k8sdRequestDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "k8sd",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of the requests to k8sd, including failed requests.",
+		Buckets:   prometheus.DefBuckets,
+	},
+	[]string{"endpoint"},
+)
+
+var k8sdRequestErrors = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "k8sd",
+		Name:      "request_errors_total",
+		Help:      "Number of requests to k8sd that failed.",
+	},
+	[]string{"endpoint"},
+)
+
+var joinTokensIssued = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "join_tokens_issued_total",
+		Help:      "Number of join tokens issued for new nodes of the workload clusters.",
+	},
+	[]string{"namespace", "cluster", "role"},
+)
+
+const (
+	joinTokenRoleControlPlane = "control-plane"
+	joinTokenRoleWorker       = "worker"
+)
+
+// ObserveK8sdRequest reports a request to the k8sd endpoint that took duration, and failed if err is not nil.
+func ObserveK8sdRequest(endpoint string, duration time.Duration, err error) {
+	k8sdRequestDuration.WithLabelValues(endpoint).Observe(duration.Seconds())
+	if err != nil {
+		k8sdRequestErrors.WithLabelValues(endpoint).Inc()
+	}
+}
+
+// RecordJoinTokenIssued reports a join token issued for a new node of the cluster.
+func RecordJoinTokenIssued(cluster client.ObjectKey, worker bool) {
+	role := joinTokenRoleControlPlane
+	if worker {
+		role = joinTokenRoleWorker
+	}
+	joinTokensIssued.WithLabelValues(cluster.Namespace, cluster.Name, role).Inc()
+}
@@ -0,0 +1,28 @@
+// Package metrics exposes the Prometheus metrics of the CK8s providers on the controller-runtime
+// metrics endpoint of the manager.
+package metrics
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// namespace prefixes the names of all the metrics.
+const namespace = "ck8s"
+
+func init() {
+	metrics.Registry.MustRegister(
+		certificatesExpiry,
+		inPlaceUpgradePhases,
+		inPlaceUpgradeDuration,
+		k8sdRequestDuration,
+		k8sdRequestErrors,
+		joinTokensIssued,
+	)
+}
+
+// ForgetMachine stops reporting the metrics of a machine that no longer exists.
+func ForgetMachine(key types.NamespacedName) {
+	certificatesExpiry.forget(key)
+	inPlaceUpgradePhases.forget(key)
+}
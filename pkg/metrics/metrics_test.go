@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+)
+
+func newMachine(name string, annotations map[string]string) *clusterv1.Machine {
+	return &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   metav1.NamespaceDefault,
+			Annotations: annotations,
+		},
+		Spec: clusterv1.MachineSpec{ClusterName: "cluster"},
+	}
+}
+
+func TestCertificatesExpiryCollector(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	c := newCertificatesExpiryCollector(func() time.Time { return now })
+
+	c.record(newMachine("m1", map[string]string{
+		bootstrapv1.MachineCertificatesExpiryDateAnnotation: now.Add(time.Hour).Format(time.RFC3339),
+	}))
+	c.record(newMachine("m2", map[string]string{
+		bootstrapv1.MachineCertificatesExpiryDateAnnotation: now.Add(-time.Minute).Format(time.RFC3339),
+	}))
+	c.record(newMachine("no-expiry", nil))
+
+	g.Expect(testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP ck8s_machine_certificates_expiry_seconds Seconds until the certificates of the machine expire, as recorded on the machine. Negative if they already expired.
+# TYPE ck8s_machine_certificates_expiry_seconds gauge
+ck8s_machine_certificates_expiry_seconds{cluster="cluster",machine="m1",namespace="default"} 3600
+ck8s_machine_certificates_expiry_seconds{cluster="cluster",machine="m2",namespace="default"} -60
+`))).To(Succeed())
+
+	deleting := newMachine("m1", map[string]string{
+		bootstrapv1.MachineCertificatesExpiryDateAnnotation: now.Add(time.Hour).Format(time.RFC3339),
+	})
+	deleting.DeletionTimestamp = &metav1.Time{Time: now}
+	c.record(deleting)
+	c.forget(types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "m2"})
+
+	g.Expect(testutil.CollectAndCount(c)).To(Equal(0))
+}
+
+func TestInPlaceUpgradePhaseCollector(t *testing.T) {
+	g := NewWithT(t)
+
+	c := newInPlaceUpgradePhaseCollector()
+	c.record(newMachine("m1", nil), &bootstrapv1.InPlaceUpgradeStatus{Phase: bootstrapv1.InPlaceUpgradePhaseSucceeded})
+	c.record(newMachine("m2", nil), &bootstrapv1.InPlaceUpgradeStatus{Phase: bootstrapv1.InPlaceUpgradePhaseSucceeded})
+	c.record(newMachine("m3", nil), &bootstrapv1.InPlaceUpgradeStatus{Phase: bootstrapv1.InPlaceUpgradePhaseUpgrading})
+	c.record(newMachine("m4", nil), nil)
+
+	g.Expect(testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP ck8s_inplace_upgrade_machines Number of machines of the cluster in each phase of the in-place upgrade.
+# TYPE ck8s_inplace_upgrade_machines gauge
+ck8s_inplace_upgrade_machines{cluster="cluster",namespace="default",phase="Succeeded"} 2
+ck8s_inplace_upgrade_machines{cluster="cluster",namespace="default",phase="Upgrading"} 1
+`))).To(Succeed())
+
+	c.record(newMachine("m3", nil), &bootstrapv1.InPlaceUpgradeStatus{Phase: bootstrapv1.InPlaceUpgradePhaseSucceeded})
+	c.forget(types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "m1"})
+
+	g.Expect(testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP ck8s_inplace_upgrade_machines Number of machines of the cluster in each phase of the in-place upgrade.
+# TYPE ck8s_inplace_upgrade_machines gauge
+ck8s_inplace_upgrade_machines{cluster="cluster",namespace="default",phase="Succeeded"} 2
+`))).To(Succeed())
+}
+
+func TestRecordInPlaceUpgradeStatus(t *testing.T) {
+	g := NewWithT(t)
+
+	start := metav1.NewTime(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC))
+	done := metav1.NewTime(start.Add(10 * time.Minute))
+	m := newMachine("duration", nil)
+	m.Spec.ClusterName = "duration-cluster"
+
+	upgrading := &bootstrapv1.InPlaceUpgradeStatus{Phase: bootstrapv1.InPlaceUpgradePhaseUpgrading, Release: "1.32", StartedAt: &start}
+	succeeded := &bootstrapv1.InPlaceUpgradeStatus{Phase: bootstrapv1.InPlaceUpgradePhaseSucceeded, Release: "1.32", StartedAt: &start, CompletedAt: &done}
+
+	RecordInPlaceUpgradeStatus(m, nil, upgrading)
+	RecordInPlaceUpgradeStatus(m, upgrading, succeeded)
+	// Reconciling a succeeded upgrade again is not another upgrade.
+	RecordInPlaceUpgradeStatus(m, succeeded, succeeded)
+
+	g.Expect(testutil.CollectAndCompare(inPlaceUpgradeDuration, strings.NewReader(`
+# HELP ck8s_inplace_upgrade_duration_seconds Duration of the in-place upgrades of machines, from the first attempt until they succeeded or exhausted their retries.
+# TYPE ck8s_inplace_upgrade_duration_seconds histogram
+ck8s_inplace_upgrade_duration_seconds_bucket{cluster="duration-cluster",namespace="default",result="succeeded",le="60"} 0
+ck8s_inplace_upgrade_duration_seconds_bucket{cluster="duration-cluster",namespace="default",result="succeeded",le="120"} 0
+ck8s_inplace_upgrade_duration_seconds_bucket{cluster="duration-cluster",namespace="default",result="succeeded",le="240"} 0
+ck8s_inplace_upgrade_duration_seconds_bucket{cluster="duration-cluster",namespace="default",result="succeeded",le="480"} 0
+ck8s_inplace_upgrade_duration_seconds_bucket{cluster="duration-cluster",namespace="default",result="succeeded",le="960"} 1
+ck8s_inplace_upgrade_duration_seconds_bucket{cluster="duration-cluster",namespace="default",result="succeeded",le="1920"} 1
+ck8s_inplace_upgrade_duration_seconds_bucket{cluster="duration-cluster",namespace="default",result="succeeded",le="3840"} 1
+ck8s_inplace_upgrade_duration_seconds_bucket{cluster="duration-cluster",namespace="default",result="succeeded",le="7680"} 1
+ck8s_inplace_upgrade_duration_seconds_bucket{cluster="duration-cluster",namespace="default",result="succeeded",le="15360"} 1
+ck8s_inplace_upgrade_duration_seconds_bucket{cluster="duration-cluster",namespace="default",result="succeeded",le="30720"} 1
+ck8s_inplace_upgrade_duration_seconds_bucket{cluster="duration-cluster",namespace="default",result="succeeded",le="61440"} 1
+ck8s_inplace_upgrade_duration_seconds_bucket{cluster="duration-cluster",namespace="default",result="succeeded",le="+Inf"} 1
+ck8s_inplace_upgrade_duration_seconds_sum{cluster="duration-cluster",namespace="default",result="succeeded"} 600
+ck8s_inplace_upgrade_duration_seconds_count{cluster="duration-cluster",namespace="default",result="succeeded"} 1
+`))).To(Succeed())
+
+	ForgetMachine(types.NamespacedName{Namespace: m.Namespace, Name: m.Name})
+}
+
+func TestObserveK8sdRequest(t *testing.T) {
+	g := NewWithT(t)
+
+	ObserveK8sdRequest("1.0/test", time.Second, nil)
+	ObserveK8sdRequest("1.0/test", time.Second, errors.New("failed"))
+
+	g.Expect(testutil.ToFloat64(k8sdRequestErrors.WithLabelValues("1.0/test"))).To(Equal(1.0))
+	g.Expect(testutil.CollectAndCount(k8sdRequestDuration, "ck8s_k8sd_request_duration_seconds")).To(BeNumerically(">=", 1))
+}
+
+func TestRecordJoinTokenIssued(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "join-cluster"}
+	RecordJoinTokenIssued(cluster, false)
+	RecordJoinTokenIssued(cluster, true)
+	RecordJoinTokenIssued(cluster, true)
+
+	g.Expect(testutil.ToFloat64(joinTokensIssued.WithLabelValues(cluster.Namespace, cluster.Name, joinTokenRoleControlPlane))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(joinTokensIssued.WithLabelValues(cluster.Namespace, cluster.Name, joinTokenRoleWorker))).To(Equal(2.0))
+}
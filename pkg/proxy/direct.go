@@ -0,0 +1,130 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	netproxy "golang.org/x/net/proxy"
+)
+
+// DirectDialer creates connections to the nodes directly, optionally through a SOCKS5 or HTTP CONNECT bastion.
+type DirectDialer struct {
+	bastion *url.URL
+	dialer  *net.Dialer
+}
+
+// NewDirectDialer creates a new dialer reaching the nodes through the bastion URL. An empty bastion dials the nodes directly.
+// The credentials authenticate with the bastion, if set. The bastion URL must not have credentials, so that they
+// are not stored along with it.
+func NewDirectDialer(bastion string, credentials *url.Userinfo, timeout time.Duration) (*DirectDialer, error) {
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	d := &DirectDialer{dialer: &net.Dialer{Timeout: timeout}}
+
+	if bastion == "" {
+		return d, nil
+	}
+
+	u, err := url.Parse(bastion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bastion URL: %w", err)
+	}
+	if u.Scheme != "socks5" && u.Scheme != "http" {
+		return nil, fmt.Errorf("unsupported bastion scheme %q, must be socks5 or http", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("bastion URL %q has no host", bastion)
+	}
+	if u.User != nil {
+		return nil, fmt.Errorf("bastion URL must not have credentials")
+	}
+	u.User = credentials
+	d.bastion = u
+
+	return d, nil
+}
+
+// DialContext creates a connection to the address, through the bastion if any.
+func (d *DirectDialer) DialContext(ctx context.Context, network string, addr string) (net.Conn, error) {
+	if d.bastion == nil {
+		return d.dialer.DialContext(ctx, network, addr)
+	}
+
+	switch d.bastion.Scheme {
+	case "socks5":
+		var auth *netproxy.Auth
+		if d.bastion.User != nil {
+			password, _ := d.bastion.User.Password()
+			auth = &netproxy.Auth{User: d.bastion.User.Username(), Password: password}
+		}
+		socks, err := netproxy.SOCKS5("tcp", d.bastion.Host, auth, d.dialer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SOCKS5 dialer: %w", err)
+		}
+		conn, err := socks.(netproxy.ContextDialer).DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial %s through SOCKS5 bastion %s: %w", addr, d.bastion.Host, err)
+		}
+		return conn, nil
+	default:
+		return d.dialConnect(ctx, addr)
+	}
+}
+
+// dialConnect creates a connection to the address through an HTTP CONNECT bastion.
+func (d *DirectDialer) dialConnect(ctx context.Context, addr string) (net.Conn, error) {
+	conn, err := d.dialer.DialContext(ctx, "tcp", d.bastion.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial HTTP CONNECT bastion %s: %w", d.bastion.Host, err)
+	}
+
+	// NOTE: The deadline bounds the CONNECT handshake only, and is reset before handing over the connection.
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else if d.dialer.Timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(d.dialer.Timeout))
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if d.bastion.User != nil {
+		password, _ := d.bastion.User.Password()
+		req.SetBasicAuth(d.bastion.User.Username(), password)
+		req.Header.Set("Proxy-Authorization", req.Header.Get("Authorization"))
+		req.Header.Del("Authorization")
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT to bastion %s: %w", d.bastion.Host, err)
+	}
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNECT response from bastion %s: %w", d.bastion.Host, err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("bastion %s refused to CONNECT to %s: %s", d.bastion.Host, addr, res.Status)
+	}
+	// NOTE: The server does not send anything before the client, so nothing is left buffered.
+	if br.Buffered() > 0 {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected data from bastion %s after CONNECT", d.bastion.Host)
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+	return conn, nil
+}
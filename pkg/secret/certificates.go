@@ -66,6 +66,13 @@ func NewCertificatesForInitialControlPlane(config *bootstrapv1.CK8sConfigSpec) C
 		&Certificate{
 			Purpose: ClientClusterCA,
 		},
+		&Certificate{
+			Purpose:  FrontProxyCA,
+			Optional: true,
+		},
+		&Certificate{
+			Purpose: ServiceAccount,
+		},
 	}
 
 	if !config.IsEtcdManaged() {
@@ -126,6 +133,9 @@ func (c Certificates) Lookup(ctx context.Context, ctrlclient client.Client, clus
 func (c Certificates) EnsureAllExist() error {
 	for _, certificate := range c {
 		if certificate.KeyPair == nil {
+			if certificate.Optional {
+				continue
+			}
 			return ErrMissingCertificate
 		}
 		if len(certificate.KeyPair.Cert) == 0 {
@@ -140,10 +150,10 @@ func (c Certificates) EnsureAllExist() error {
 	return nil
 }
 
-// Generate will generate any certificates that do not have KeyPair data.
+// Generate will generate any certificates that do not have KeyPair data, except the optional ones.
 func (c Certificates) Generate() error {
 	for _, certificate := range c {
-		if certificate.KeyPair == nil {
+		if certificate.KeyPair == nil && !certificate.Optional {
 			err := certificate.Generate()
 			if err != nil {
 				return err
@@ -188,9 +198,11 @@ func (c Certificates) LookupOrGenerate(ctx context.Context, ctrlclient client.Cl
 }
 
 // Certificate represents a single certificate CA.
+// Optional certificates are looked up but never generated, they are only issued by an Issuer.
 type Certificate struct {
 	Generated         bool
 	External          bool
+	Optional          bool
 	Purpose           Purpose
 	KeyPair           *certs.KeyPair
 	CertFile, KeyFile string
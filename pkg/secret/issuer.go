@@ -0,0 +1,162 @@
+package secret
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/cert"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+)
+
+// ErrIssuancePending is an error indicating a certificate is still being issued by an Issuer.
+var ErrIssuancePending = errors.New("certificate issuance pending")
+
+// certificateAuthorityPurposes are the certificate authorities of a cluster. They are issued by an Issuer,
+// except for the external ones, e.g. the CA of an external datastore.
+var certificateAuthorityPurposes = []Purpose{ClusterCA, ClientClusterCA, FrontProxyCA, EtcdCA}
+
+// Issuer issues intermediate certificate authorities signed by an external PKI.
+type Issuer interface {
+	// Issue issues the certificate authority of the given purpose for the cluster, and writes it to its secret.
+	// It returns ErrIssuancePending while the certificate is being issued.
+	Issue(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey, purpose Purpose, owner metav1.OwnerReference) (*certs.KeyPair, error)
+}
+
+// LookupOrIssue looks up each certificate from secrets and issues the missing certificate authorities,
+// including the optional ones, with the issuer. The other missing certificates are generated.
+// It returns ErrIssuancePending until every certificate authority is issued.
+func (c Certificates) LookupOrIssue(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey, owner metav1.OwnerReference, issuer Issuer) error {
+	if err := c.Lookup(ctx, ctrlclient, clusterName); err != nil {
+		return err
+	}
+
+	var pending bool
+	for _, certificate := range c {
+		if certificate.KeyPair != nil || certificate.External || !isCertificateAuthority(certificate.Purpose) {
+			continue
+		}
+
+		kp, err := issuer.Issue(ctx, ctrlclient, clusterName, certificate.Purpose, owner)
+		if err != nil {
+			if errors.Is(err, ErrIssuancePending) {
+				pending = true
+				continue
+			}
+			return fmt.Errorf("failed to issue certificate %s: %w", certificate.Purpose, err)
+		}
+		certificate.KeyPair = kp
+	}
+	if pending {
+		return ErrIssuancePending
+	}
+
+	if err := c.Generate(); err != nil {
+		return err
+	}
+	return c.SaveGenerated(ctx, ctrlclient, clusterName, owner)
+}
+
+// CertificateAuthoritiesStatus returns the status of the certificate authorities that were looked up.
+func (c Certificates) CertificateAuthoritiesStatus(clusterName client.ObjectKey) ([]bootstrapv1.CertificateStatus, error) {
+	var statuses []bootstrapv1.CertificateStatus
+	for _, certificate := range c {
+		if certificate.KeyPair == nil || !isCertificateAuthority(certificate.Purpose) {
+			continue
+		}
+
+		caCert, err := certs.DecodeCertPEM(certificate.KeyPair.Cert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode certificate %s: %w", certificate.Purpose, err)
+		} else if caCert == nil {
+			return nil, fmt.Errorf("for certificate %s: %w", certificate.Purpose, ErrMissingCrt)
+		}
+
+		status := bootstrapv1.CertificateStatus{
+			Name:      Name(clusterName.Name, certificate.Purpose),
+			ExpiresAt: &metav1.Time{Time: caCert.NotAfter},
+		}
+		if caCert.Issuer.String() != caCert.Subject.String() {
+			status.CertificateAuthority = caCert.Issuer.CommonName
+			status.ExternallyManaged = true
+		}
+		if certificate.External {
+			status.ExternallyManaged = true
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func isCertificateAuthority(purpose Purpose) bool {
+	for _, p := range certificateAuthorityPurposes {
+		if p == purpose {
+			return true
+		}
+	}
+	return false
+}
+
+// newCertificateSigningRequest returns a PEM encoded certificate signing request for the certificate authority
+// of the given purpose. The common name of the certificate authority is the name of its secret.
+func newCertificateSigningRequest(clusterName client.ObjectKey, purpose Purpose, key *rsa.PrivateKey) ([]byte, error) {
+	tmpl := &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName: Name(clusterName.Name, purpose),
+		},
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, tmpl, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate signing request: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: cert.CertificateRequestBlockType, Bytes: der}), nil
+}
+
+// newIssuedKeyPair validates the PEM encoded certificate issued for the key, and returns the key pair of the
+// certificate authority. Only the first certificate is kept, its chain is dropped.
+func newIssuedKeyPair(certPEM []byte, key *rsa.PrivateKey) (*certs.KeyPair, error) {
+	issued, err := certs.DecodeCertPEM(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode issued certificate: %w", err)
+	} else if issued == nil {
+		return nil, ErrMissingCrt
+	}
+
+	if !issued.BasicConstraintsValid || !issued.IsCA {
+		return nil, fmt.Errorf("issued certificate %q is not a certificate authority", issued.Subject.CommonName)
+	}
+	if issued.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return nil, fmt.Errorf("issued certificate %q cannot sign certificates", issued.Subject.CommonName)
+	}
+	if !key.PublicKey.Equal(issued.PublicKey) {
+		return nil, fmt.Errorf("issued certificate %q does not match the private key", issued.Subject.CommonName)
+	}
+
+	return &certs.KeyPair{
+		Cert: certs.EncodeCertPEM(issued),
+		Key:  certs.EncodePrivateKeyPEM(key),
+	}, nil
+}
+
+// saveIssued saves the issued certificate authority as a Kubernetes secret.
+func saveIssued(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey, purpose Purpose, kp *certs.KeyPair, owner metav1.OwnerReference) error {
+	certificate := &Certificate{
+		Purpose:   purpose,
+		KeyPair:   kp,
+		Generated: true,
+	}
+	if err := ctrlclient.Create(ctx, certificate.AsSecret(clusterName, owner)); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to save issued certificate %s: %w", purpose, err)
+	}
+	return nil
+}
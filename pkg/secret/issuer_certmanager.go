@@ -0,0 +1,218 @@
+package secret
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// CertManagerGroup is the API group of cert-manager.
+	CertManagerGroup = "cert-manager.io"
+
+	// pendingKeySuffix is appended to the secret name of a certificate authority to store its private key
+	// while the certificate is being issued.
+	pendingKeySuffix = "pending"
+)
+
+// certificateRequestGVK is the GroupVersionKind of the cert-manager CertificateRequest.
+var certificateRequestGVK = schema.GroupVersionKind{Group: CertManagerGroup, Version: "v1", Kind: "CertificateRequest"}
+
+// CertManagerIssuer issues certificate authorities with cert-manager CertificateRequests.
+// CertificateRequests are used rather than Certificates, so that cert-manager does not re-issue the
+// certificate authorities of a running cluster on its own.
+type CertManagerIssuer struct {
+	// IssuerName is the name of the cert-manager Issuer or ClusterIssuer.
+	IssuerName string
+	// IssuerKind is either Issuer or ClusterIssuer.
+	IssuerKind string
+	// IssuerGroup is the API group of the issuer.
+	IssuerGroup string
+	// Duration is the validity requested for the certificate authorities.
+	Duration time.Duration
+}
+
+// Issue creates a CertificateRequest for the certificate authority, and saves its secret once the
+// CertificateRequest is ready. The private key is kept in a pending secret in the meantime.
+func (i *CertManagerIssuer) Issue(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey, purpose Purpose, owner metav1.OwnerReference) (*certs.KeyPair, error) {
+	name := Name(clusterName.Name, purpose)
+	pendingKey := client.ObjectKey{Namespace: clusterName.Namespace, Name: fmt.Sprintf("%s-%s", name, pendingKeySuffix)}
+
+	keySecret := &corev1.Secret{}
+	if err := ctrlclient.Get(ctx, pendingKey, keySecret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get pending key %s: %w", pendingKey.Name, err)
+		}
+		return nil, i.startIssuance(ctx, ctrlclient, clusterName, purpose, pendingKey, owner)
+	}
+
+	signer, err := certs.DecodePrivateKeyPEM(keySecret.Data[TLSKeyDataName])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode pending key %s: %w", pendingKey.Name, err)
+	}
+	key, ok := signer.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("pending key %s is not an RSA key", pendingKey.Name)
+	}
+
+	request := &unstructured.Unstructured{}
+	request.SetGroupVersionKind(certificateRequestGVK)
+	if err := ctrlclient.Get(ctx, client.ObjectKey{Namespace: clusterName.Namespace, Name: name}, request); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get CertificateRequest %s: %w", name, err)
+		}
+		if err := i.createCertificateRequest(ctx, ctrlclient, clusterName, purpose, key, owner); err != nil {
+			return nil, err
+		}
+		return nil, ErrIssuancePending
+	}
+
+	ready, reason, message := certificateRequestStatus(request)
+	switch {
+	case reason == "Failed" || reason == "Denied":
+		// NOTE: Start over with a new private key on the next reconciliation, after the issuer is fixed.
+		if err := deleteIgnoreNotFound(ctx, ctrlclient, request, keySecret); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("CertificateRequest %s failed: %s", name, message)
+	case !ready:
+		return nil, ErrIssuancePending
+	}
+
+	encoded, _, err := unstructured.NestedString(request.Object, "status", "certificate")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate of CertificateRequest %s: %w", name, err)
+	}
+	certPEM, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode certificate of CertificateRequest %s: %w", name, err)
+	}
+
+	kp, err := newIssuedKeyPair(certPEM, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveIssued(ctx, ctrlclient, clusterName, purpose, kp, owner); err != nil {
+		return nil, err
+	}
+	if err := deleteIgnoreNotFound(ctx, ctrlclient, request, keySecret); err != nil {
+		return nil, err
+	}
+	return kp, nil
+}
+
+// startIssuance generates the private key of the certificate authority, saves it in the pending secret
+// and requests its certificate. It always returns ErrIssuancePending on success.
+func (i *CertManagerIssuer) startIssuance(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey, purpose Purpose, pendingKey client.ObjectKey, owner metav1.OwnerReference) error {
+	// Drop a request left over by a previous issuance, since its private key is gone.
+	stale := &unstructured.Unstructured{}
+	stale.SetGroupVersionKind(certificateRequestGVK)
+	stale.SetNamespace(clusterName.Namespace)
+	stale.SetName(Name(clusterName.Name, purpose))
+	if err := deleteIgnoreNotFound(ctx, ctrlclient, stale); err != nil {
+		return err
+	}
+
+	key, err := certs.NewPrivateKey()
+	if err != nil {
+		return fmt.Errorf("failed to generate private key: %w", err)
+	}
+
+	keySecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       pendingKey.Namespace,
+			Name:            pendingKey.Name,
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Data: map[string][]byte{
+			TLSKeyDataName: certs.EncodePrivateKeyPEM(key),
+		},
+	}
+	if err := ctrlclient.Create(ctx, keySecret); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return ErrIssuancePending
+		}
+		return fmt.Errorf("failed to create pending key %s: %w", pendingKey.Name, err)
+	}
+
+	if err := i.createCertificateRequest(ctx, ctrlclient, clusterName, purpose, key, owner); err != nil {
+		return err
+	}
+	return ErrIssuancePending
+}
+
+// createCertificateRequest creates the CertificateRequest of the certificate authority for the private key.
+func (i *CertManagerIssuer) createCertificateRequest(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey, purpose Purpose, key *rsa.PrivateKey, owner metav1.OwnerReference) error {
+	csr, err := newCertificateSigningRequest(clusterName, purpose, key)
+	if err != nil {
+		return err
+	}
+
+	request := &unstructured.Unstructured{}
+	request.SetGroupVersionKind(certificateRequestGVK)
+	request.SetNamespace(clusterName.Namespace)
+	request.SetName(Name(clusterName.Name, purpose))
+	request.SetOwnerReferences([]metav1.OwnerReference{owner})
+	request.Object["spec"] = map[string]interface{}{
+		"request":  base64.StdEncoding.EncodeToString(csr),
+		"isCA":     true,
+		"duration": i.Duration.String(),
+		"usages":   []interface{}{"cert sign", "crl sign", "digital signature"},
+		"issuerRef": map[string]interface{}{
+			"name":  i.IssuerName,
+			"kind":  i.IssuerKind,
+			"group": i.IssuerGroup,
+		},
+	}
+
+	if err := ctrlclient.Create(ctx, request); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create CertificateRequest %s: %w", request.GetName(), err)
+	}
+	return nil
+}
+
+// certificateRequestStatus returns whether the CertificateRequest is ready, and the reason and message
+// of its Ready condition. Denied requests are reported with the Denied reason.
+func certificateRequestStatus(request *unstructured.Unstructured) (bool, string, string) {
+	conditions, _, _ := unstructured.NestedSlice(request.Object, "status", "conditions")
+
+	var ready bool
+	var reason, message string
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch condition["type"] {
+		case "Ready":
+			ready = condition["status"] == string(metav1.ConditionTrue)
+			reason, _ = condition["reason"].(string)
+			message, _ = condition["message"].(string)
+		case "Denied", "InvalidRequest":
+			if condition["status"] == string(metav1.ConditionTrue) {
+				message, _ = condition["message"].(string)
+				return false, "Denied", message
+			}
+		}
+	}
+	return ready, reason, message
+}
+
+func deleteIgnoreNotFound(ctx context.Context, ctrlclient client.Client, objs ...client.Object) error {
+	for _, obj := range objs {
+		if err := ctrlclient.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %s: %w", obj.GetName(), err)
+		}
+	}
+	return nil
+}
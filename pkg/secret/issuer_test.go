@@ -0,0 +1,338 @@
+package secret_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/secret"
+)
+
+var (
+	clusterName = client.ObjectKey{Namespace: "default", Name: "cluster"}
+
+	certificateRequestGVK = schema.GroupVersionKind{Group: secret.CertManagerGroup, Version: "v1", Kind: "CertificateRequest"}
+)
+
+// testPKI is the corporate PKI that signs the intermediate certificate authorities.
+type testPKI struct {
+	cert *x509.Certificate
+	key  *rsa.PrivateKey
+}
+
+func newTestPKI(g Gomega) *testPKI {
+	key, err := certs.NewPrivateKey()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "corporate-root"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	g.Expect(err).ToNot(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	return &testPKI{cert: cert, key: key}
+}
+
+// sign signs the PEM encoded certificate signing request, and returns the PEM encoded certificate followed by its chain.
+func (p *testPKI) sign(g Gomega, csrPEM []byte, isCA bool) []byte {
+	block, _ := pem.Decode(csrPEM)
+	g.Expect(block).ToNot(BeNil())
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               csr.Subject,
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, p.cert, csr.PublicKey, p.key)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	return append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), certs.EncodeCertPEM(p.cert)...)
+}
+
+func newFakeClient() client.Client {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	scheme.AddKnownTypeWithName(certificateRequestGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(certificateRequestGVK.GroupVersion().WithKind("CertificateRequestList"), &unstructured.UnstructuredList{})
+	return fake.NewClientBuilder().WithScheme(scheme).Build()
+}
+
+func expectIssued(g Gomega, c client.Client, certificates secret.Certificates, pki *testPKI) {
+	for _, purpose := range []secret.Purpose{secret.ClusterCA, secret.ClientClusterCA, secret.FrontProxyCA} {
+		certificate := certificates.GetByPurpose(purpose)
+		g.Expect(certificate.KeyPair).ToNot(BeNil())
+
+		s, err := secret.Get(context.Background(), c, clusterName, purpose)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(s.Data[secret.TLSCrtDataName]).To(Equal(certificate.KeyPair.Cert))
+		g.Expect(s.Data[secret.TLSKeyDataName]).To(Equal(certificate.KeyPair.Key))
+
+		cert, err := certs.DecodeCertPEM(certificate.KeyPair.Cert)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(cert.Subject.CommonName).To(Equal(secret.Name(clusterName.Name, purpose)))
+		g.Expect(cert.CheckSignatureFrom(pki.cert)).To(Succeed())
+	}
+
+	statuses, err := certificates.CertificateAuthoritiesStatus(clusterName)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(statuses).To(HaveLen(3))
+	for _, status := range statuses {
+		g.Expect(status.CertificateAuthority).To(Equal("corporate-root"))
+		g.Expect(status.ExternallyManaged).To(BeTrue())
+		g.Expect(status.ExpiresAt.Time).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+	}
+}
+
+func TestLookupOrIssueWebhook(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	pki := newTestPKI(g)
+
+	var requests []map[string]interface{}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		requests = append(requests, req)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"certificate": string(pki.sign(g, []byte(req["csr"].(string)), true)),
+		})
+	}))
+	defer server.Close()
+
+	c := newFakeClient()
+	issuer := &secret.WebhookIssuer{
+		URL:      server.URL,
+		CABundle: certs.EncodeCertPEM(server.Certificate()),
+		Token:    "secret-token",
+		Duration: time.Hour,
+	}
+
+	certificates := secret.NewCertificatesForInitialControlPlane(&bootstrapv1.CK8sConfigSpec{})
+	g.Expect(certificates.LookupOrIssue(ctx, c, clusterName, metav1.OwnerReference{}, issuer)).To(Succeed())
+	expectIssued(g, c, certificates, pki)
+
+	// The service account key is not a certificate authority, it is generated.
+	sa, err := secret.Get(ctx, c, clusterName, secret.ServiceAccount)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(sa.Data[secret.TLSKeyDataName]).To(Equal(certificates.GetByPurpose(secret.ServiceAccount).KeyPair.Key))
+
+	g.Expect(requests).To(HaveLen(3))
+	g.Expect(requests[0]).To(HaveKeyWithValue("cluster", "default/cluster"))
+	g.Expect(requests[0]).To(HaveKeyWithValue("purpose", "ca"))
+	g.Expect(requests[0]).To(HaveKeyWithValue("durationSeconds", BeNumerically("==", 3600)))
+
+	// Existing certificate authorities are never re-issued.
+	certificates = secret.NewCertificatesForInitialControlPlane(&bootstrapv1.CK8sConfigSpec{})
+	g.Expect(certificates.LookupOrIssue(ctx, c, clusterName, metav1.OwnerReference{}, issuer)).To(Succeed())
+	g.Expect(requests).To(HaveLen(3))
+}
+
+func TestLookupOrIssueWebhookRejectsLeafCertificates(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	pki := newTestPKI(g)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"certificate": string(pki.sign(g, []byte(req["csr"]), false)),
+		})
+	}))
+	defer server.Close()
+
+	c := newFakeClient()
+	issuer := &secret.WebhookIssuer{
+		URL:      server.URL,
+		CABundle: certs.EncodeCertPEM(server.Certificate()),
+		Duration: time.Hour,
+	}
+
+	certificates := secret.NewCertificatesForInitialControlPlane(&bootstrapv1.CK8sConfigSpec{})
+	g.Expect(certificates.LookupOrIssue(ctx, c, clusterName, metav1.OwnerReference{}, issuer)).To(MatchError(ContainSubstring("is not a certificate authority")))
+
+	_, err := secret.Get(ctx, c, clusterName, secret.ClusterCA)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestLookupOrIssueCertManager(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	pki := newTestPKI(g)
+
+	c := newFakeClient()
+	issuer := &secret.CertManagerIssuer{
+		IssuerName:  "corporate",
+		IssuerKind:  "ClusterIssuer",
+		IssuerGroup: secret.CertManagerGroup,
+		Duration:    time.Hour,
+	}
+
+	certificates := secret.NewCertificatesForInitialControlPlane(&bootstrapv1.CK8sConfigSpec{})
+	g.Expect(certificates.LookupOrIssue(ctx, c, clusterName, metav1.OwnerReference{}, issuer)).To(MatchError(secret.ErrIssuancePending))
+
+	requests := &unstructured.UnstructuredList{}
+	requests.SetGroupVersionKind(certificateRequestGVK.GroupVersion().WithKind("CertificateRequestList"))
+	g.Expect(c.List(ctx, requests)).To(Succeed())
+	g.Expect(requests.Items).To(HaveLen(3))
+
+	// The requests are pending until cert-manager issues the certificates.
+	certificates = secret.NewCertificatesForInitialControlPlane(&bootstrapv1.CK8sConfigSpec{})
+	g.Expect(certificates.LookupOrIssue(ctx, c, clusterName, metav1.OwnerReference{}, issuer)).To(MatchError(secret.ErrIssuancePending))
+
+	for i := range requests.Items {
+		request := &requests.Items[i]
+		issuerName, _, _ := unstructured.NestedString(request.Object, "spec", "issuerRef", "name")
+		g.Expect(issuerName).To(Equal("corporate"))
+		isCA, _, _ := unstructured.NestedBool(request.Object, "spec", "isCA")
+		g.Expect(isCA).To(BeTrue())
+
+		encoded, _, _ := unstructured.NestedString(request.Object, "spec", "request")
+		csr, err := base64.StdEncoding.DecodeString(encoded)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(unstructured.SetNestedField(request.Object, base64.StdEncoding.EncodeToString(pki.sign(g, csr, true)), "status", "certificate")).To(Succeed())
+		g.Expect(unstructured.SetNestedSlice(request.Object, []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "True", "reason": "Issued"},
+		}, "status", "conditions")).To(Succeed())
+		g.Expect(c.Update(ctx, request)).To(Succeed())
+	}
+
+	certificates = secret.NewCertificatesForInitialControlPlane(&bootstrapv1.CK8sConfigSpec{})
+	g.Expect(certificates.LookupOrIssue(ctx, c, clusterName, metav1.OwnerReference{}, issuer)).To(Succeed())
+	expectIssued(g, c, certificates, pki)
+
+	// The requests and the pending keys are cleaned up, the certificate authorities and the generated
+	// service account key are kept.
+	g.Expect(c.List(ctx, requests)).To(Succeed())
+	g.Expect(requests.Items).To(BeEmpty())
+	secrets := &corev1.SecretList{}
+	g.Expect(c.List(ctx, secrets)).To(Succeed())
+	g.Expect(secrets.Items).To(HaveLen(4))
+}
+
+func TestLookupOrIssueCertManagerDenied(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	c := newFakeClient()
+	issuer := &secret.CertManagerIssuer{
+		IssuerName:  "corporate",
+		IssuerKind:  "Issuer",
+		IssuerGroup: secret.CertManagerGroup,
+		Duration:    time.Hour,
+	}
+
+	certificates := secret.NewCertificatesForInitialControlPlane(&bootstrapv1.CK8sConfigSpec{})
+	g.Expect(certificates.LookupOrIssue(ctx, c, clusterName, metav1.OwnerReference{}, issuer)).To(MatchError(secret.ErrIssuancePending))
+
+	request := &unstructured.Unstructured{}
+	request.SetGroupVersionKind(certificateRequestGVK)
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: clusterName.Namespace, Name: "cluster-ca"}, request)).To(Succeed())
+	g.Expect(unstructured.SetNestedSlice(request.Object, []interface{}{
+		map[string]interface{}{"type": "Denied", "status": "True", "reason": "Denied", "message": "not allowed"},
+	}, "status", "conditions")).To(Succeed())
+	g.Expect(c.Update(ctx, request)).To(Succeed())
+
+	certificates = secret.NewCertificatesForInitialControlPlane(&bootstrapv1.CK8sConfigSpec{})
+	g.Expect(certificates.LookupOrIssue(ctx, c, clusterName, metav1.OwnerReference{}, issuer)).To(MatchError(ContainSubstring("not allowed")))
+
+	// The next attempt starts over with a new request.
+	certificates = secret.NewCertificatesForInitialControlPlane(&bootstrapv1.CK8sConfigSpec{})
+	g.Expect(certificates.LookupOrIssue(ctx, c, clusterName, metav1.OwnerReference{}, issuer)).To(MatchError(secret.ErrIssuancePending))
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: clusterName.Namespace, Name: "cluster-ca"}, request)).To(Succeed())
+	conditions, _, _ := unstructured.NestedSlice(request.Object, "status", "conditions")
+	g.Expect(conditions).To(BeEmpty())
+}
+
+func TestCertificateAuthoritiesStatusSelfSigned(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	c := newFakeClient()
+	certificates := secret.NewCertificatesForInitialControlPlane(&bootstrapv1.CK8sConfigSpec{})
+	g.Expect(certificates.LookupOrGenerate(ctx, c, clusterName, metav1.OwnerReference{})).To(Succeed())
+
+	// The optional front-proxy CA is not generated.
+	g.Expect(certificates.GetByPurpose(secret.FrontProxyCA).KeyPair).To(BeNil())
+	g.Expect(certificates.EnsureAllExist()).To(Succeed())
+
+	statuses, err := certificates.CertificateAuthoritiesStatus(clusterName)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(statuses).To(HaveLen(2))
+	g.Expect(statuses[0].Name).To(Equal("cluster-ca"))
+	g.Expect(statuses[0].ExternallyManaged).To(BeFalse())
+	g.Expect(statuses[0].CertificateAuthority).To(BeEmpty())
+	g.Expect(statuses[1].Name).To(Equal("cluster-cca"))
+}
+
+func TestCertificateAuthoritiesStatusExternalDatastore(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	c := newFakeClient()
+	pki := newTestPKI(g)
+	for _, purpose := range []secret.Purpose{secret.EtcdCA, secret.APIServerEtcdClient} {
+		g.Expect(c.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: clusterName.Namespace, Name: secret.Name(clusterName.Name, purpose)},
+			Data:       map[string][]byte{secret.TLSCrtDataName: certs.EncodeCertPEM(pki.cert)},
+		})).To(Succeed())
+	}
+
+	certificates := secret.NewCertificatesForInitialControlPlane(&bootstrapv1.CK8sConfigSpec{
+		ControlPlaneConfig: bootstrapv1.CK8sControlPlaneConfig{DatastoreType: "external"},
+	})
+	g.Expect(certificates.LookupOrGenerate(ctx, c, clusterName, metav1.OwnerReference{})).To(Succeed())
+
+	statuses, err := certificates.CertificateAuthoritiesStatus(clusterName)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(statuses).To(HaveLen(3))
+	g.Expect(statuses[2].Name).To(Equal("cluster-etcd"))
+	g.Expect(statuses[2].ExpiresAt.Time).To(BeTemporally("==", pki.cert.NotAfter))
+	g.Expect(statuses[2].ExternallyManaged).To(BeTrue())
+}
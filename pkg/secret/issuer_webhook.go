@@ -0,0 +1,147 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// webhookIssuerTimeout is the timeout of the requests to the signing endpoint.
+	webhookIssuerTimeout = 30 * time.Second
+
+	// webhookIssuerMaxResponseSize limits the size of the responses of the signing endpoint.
+	webhookIssuerMaxResponseSize = 1 << 20
+)
+
+// webhookSignRequest is the request sent to the signing endpoint.
+type webhookSignRequest struct {
+	CSR             string `json:"csr"`
+	Cluster         string `json:"cluster"`
+	Purpose         string `json:"purpose"`
+	DurationSeconds int64  `json:"durationSeconds"`
+}
+
+// webhookSignResponse is the response of the signing endpoint.
+type webhookSignResponse struct {
+	Certificate string `json:"certificate"`
+}
+
+// WebhookIssuer issues certificate authorities by sending certificate signing requests to an external
+// signing endpoint.
+type WebhookIssuer struct {
+	// URL is the HTTPS URL of the signing endpoint.
+	URL string
+	// CABundle is the PEM encoded CA bundle to verify the signing endpoint. The system trust store is used if empty.
+	CABundle []byte
+	// Token is the bearer token sent to the signing endpoint, if any.
+	Token string
+	// Duration is the validity requested for the certificate authorities.
+	Duration time.Duration
+}
+
+// Issue sends a certificate signing request for the certificate authority to the signing endpoint,
+// and saves the issued certificate authority.
+func (i *WebhookIssuer) Issue(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey, purpose Purpose, owner metav1.OwnerReference) (*certs.KeyPair, error) {
+	key, err := certs.NewPrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate private key: %w", err)
+	}
+	csr, err := newCertificateSigningRequest(clusterName, purpose, key)
+	if err != nil {
+		return nil, err
+	}
+
+	certPEM, err := i.sign(ctx, webhookSignRequest{
+		CSR:             string(csr),
+		Cluster:         clusterName.String(),
+		Purpose:         string(purpose),
+		DurationSeconds: int64(i.Duration.Seconds()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	kp, err := newIssuedKeyPair(certPEM, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveIssued(ctx, ctrlclient, clusterName, purpose, kp, owner); err != nil {
+		return nil, err
+	}
+	return kp, nil
+}
+
+func (i *WebhookIssuer) sign(ctx context.Context, signRequest webhookSignRequest) ([]byte, error) {
+	httpClient, err := i.httpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(signRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal signing request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signing request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if i.Token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", i.Token))
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send signing request: %w", err)
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(io.LimitReader(res.Body, webhookIssuerMaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("signing endpoint returned status %d: %s", res.StatusCode, string(resBody))
+	}
+
+	var signResponse webhookSignResponse
+	if err := json.Unmarshal(resBody, &signResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal signing response: %w", err)
+	}
+	if signResponse.Certificate == "" {
+		return nil, errors.New("signing response has no certificate")
+	}
+	return []byte(signResponse.Certificate), nil
+}
+
+func (i *WebhookIssuer) httpClient() (*http.Client, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(i.CABundle) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(i.CABundle) {
+			return nil, errors.New("failed to parse CA bundle of the signing endpoint")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &http.Client{
+		Timeout: webhookIssuerTimeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
@@ -0,0 +1,58 @@
+package inplace
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+)
+
+const (
+	// DefaultDrainTimeout is how long the node drain can take when the object does not specify otherwise.
+	DefaultDrainTimeout = 10 * time.Minute
+	// DefaultNodeReadyTimeout is how long the node can take to become Ready after the refresh
+	// when the object does not specify otherwise.
+	DefaultNodeReadyTimeout = 10 * time.Minute
+)
+
+// DrainOptions configures cordoning and draining the node around an in-place upgrade.
+type DrainOptions struct {
+	// Timeout is how long to wait for the node to drain before failing the upgrade.
+	Timeout time.Duration
+}
+
+// HasDrainOptions checks if the object configures the node drain, either enabling or disabling it.
+func HasDrainOptions(obj client.Object) bool {
+	_, ok := obj.GetAnnotations()[bootstrapv1.InPlaceUpgradeDrainAnnotation]
+	return ok
+}
+
+// GetDrainOptions returns the drain options set on the object, or nil if draining is not enabled.
+func GetDrainOptions(obj client.Object) (*DrainOptions, error) {
+	v, ok := obj.GetAnnotations()[bootstrapv1.InPlaceUpgradeDrainAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	enabled, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation %q: %w", bootstrapv1.InPlaceUpgradeDrainAnnotation, v, err)
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	opts := &DrainOptions{Timeout: DefaultDrainTimeout}
+	if v, ok := obj.GetAnnotations()[bootstrapv1.InPlaceUpgradeDrainTimeoutAnnotation]; ok {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation %q: %w", bootstrapv1.InPlaceUpgradeDrainTimeoutAnnotation, v, err)
+		}
+		opts.Timeout = timeout
+	}
+
+	return opts, nil
+}
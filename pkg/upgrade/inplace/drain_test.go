@@ -0,0 +1,78 @@
+package inplace_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/upgrade/inplace"
+)
+
+func TestGetDrainOptions(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		annotations map[string]string
+		expected    *inplace.DrainOptions
+		expectErr   bool
+	}{
+		{
+			name:        "noAnnotations",
+			annotations: map[string]string{},
+			expected:    nil,
+		},
+		{
+			name:        "disabled",
+			annotations: map[string]string{bootstrapv1.InPlaceUpgradeDrainAnnotation: "false"},
+			expected:    nil,
+		},
+		{
+			name:        "defaultTimeout",
+			annotations: map[string]string{bootstrapv1.InPlaceUpgradeDrainAnnotation: "true"},
+			expected:    &inplace.DrainOptions{Timeout: inplace.DefaultDrainTimeout},
+		},
+		{
+			name: "customTimeout",
+			annotations: map[string]string{
+				bootstrapv1.InPlaceUpgradeDrainAnnotation:        "true",
+				bootstrapv1.InPlaceUpgradeDrainTimeoutAnnotation: "30m",
+			},
+			expected: &inplace.DrainOptions{Timeout: 30 * time.Minute},
+		},
+		{
+			name:        "invalidDrain",
+			annotations: map[string]string{bootstrapv1.InPlaceUpgradeDrainAnnotation: "yes please"},
+			expectErr:   true,
+		},
+		{
+			name: "invalidTimeout",
+			annotations: map[string]string{
+				bootstrapv1.InPlaceUpgradeDrainAnnotation:        "true",
+				bootstrapv1.InPlaceUpgradeDrainTimeoutAnnotation: "soon",
+			},
+			expectErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tc.annotations,
+				},
+			}
+
+			opts, err := inplace.GetDrainOptions(machine)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(opts).To(Equal(tc.expected))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package inplace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
+)
+
+// HealthGate is a check that must pass between the machine upgrades of an orchestrated in-place upgrade.
+type HealthGate string
+
+const (
+	// HealthGateControlPlane checks that the control plane components are healthy.
+	HealthGateControlPlane HealthGate = "control-plane"
+	// HealthGateDatastore checks that every control plane node is a settled member of the dqlite cluster.
+	HealthGateDatastore HealthGate = "datastore"
+	// HealthGateNodes checks that all the nodes of the workload cluster are Ready.
+	HealthGateNodes HealthGate = "nodes"
+)
+
+// HealthChecks configures the health gates of an orchestrated in-place upgrade.
+type HealthChecks struct {
+	// Gates are the health gates to check.
+	Gates []HealthGate
+	// Endpoint is a path on the workload cluster API server that must respond successfully, if set.
+	Endpoint string
+}
+
+// WorkloadHealthChecker checks the health of the workload cluster.
+type WorkloadHealthChecker interface {
+	CheckNodesReady(ctx context.Context, ignoreNodes map[string]struct{}) error
+	CheckDatastoreMembers(ctx context.Context) error
+	CheckHealthEndpoint(ctx context.Context, path string) error
+}
+
+// GetHealthChecks returns the health checks set on the object, or nil if none are set.
+func GetHealthChecks(obj client.Object) (*HealthChecks, error) {
+	annotations := obj.GetAnnotations()
+	checks := &HealthChecks{
+		Endpoint: annotations[bootstrapv1.InPlaceUpgradeHealthCheckEndpointAnnotation],
+	}
+
+	if v := annotations[bootstrapv1.InPlaceUpgradeHealthGatesAnnotation]; v != "" {
+		for _, gate := range strings.Split(v, ",") {
+			switch gate := HealthGate(strings.TrimSpace(gate)); gate {
+			case HealthGateControlPlane, HealthGateDatastore, HealthGateNodes:
+				checks.Gates = append(checks.Gates, gate)
+			case "":
+			default:
+				return nil, fmt.Errorf("invalid %s annotation %q: unknown health gate %q", bootstrapv1.InPlaceUpgradeHealthGatesAnnotation, v, gate)
+			}
+		}
+	}
+
+	if len(checks.Gates) == 0 && checks.Endpoint == "" {
+		return nil, nil
+	}
+	return checks, nil
+}
+
+// Check runs the health checks and returns an error describing every failed gate.
+// The control plane conditions are read from controlPlane, which may be nil if the control plane is unknown.
+// Nodes in ignoreNodes are expected to be unavailable, e.g. because they are being upgraded.
+func (h *HealthChecks) Check(ctx context.Context, controlPlane conditions.Getter, workload WorkloadHealthChecker, ignoreNodes map[string]struct{}) error {
+	var errs []error
+	for _, gate := range h.Gates {
+		switch gate {
+		case HealthGateControlPlane:
+			if controlPlane == nil || !conditions.IsTrue(controlPlane, controlplanev1.ControlPlaneComponentsHealthyCondition) {
+				errs = append(errs, fmt.Errorf("control plane components are not healthy"))
+			}
+		case HealthGateDatastore:
+			if err := workload.CheckDatastoreMembers(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		case HealthGateNodes:
+			if err := workload.CheckNodesReady(ctx, ignoreNodes); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if h.Endpoint != "" {
+		if err := workload.CheckHealthEndpoint(ctx, h.Endpoint); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,140 @@
+package inplace_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	controlplanev1 "github.com/canonical/cluster-api-k8s/controlplane/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/upgrade/inplace"
+)
+
+type fakeHealthChecker struct {
+	nodesErr     error
+	datastoreErr error
+	endpointErr  error
+}
+
+func (f *fakeHealthChecker) CheckNodesReady(context.Context, map[string]struct{}) error {
+	return f.nodesErr
+}
+
+func (f *fakeHealthChecker) CheckDatastoreMembers(context.Context) error {
+	return f.datastoreErr
+}
+
+func (f *fakeHealthChecker) CheckHealthEndpoint(context.Context, string) error {
+	return f.endpointErr
+}
+
+func TestGetHealthChecks(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		annotations map[string]string
+		expected    *inplace.HealthChecks
+		expectErr   bool
+	}{
+		{
+			name:        "noAnnotations",
+			annotations: map[string]string{},
+		},
+		{
+			name:        "gates",
+			annotations: map[string]string{bootstrapv1.InPlaceUpgradeHealthGatesAnnotation: "control-plane, nodes"},
+			expected:    &inplace.HealthChecks{Gates: []inplace.HealthGate{inplace.HealthGateControlPlane, inplace.HealthGateNodes}},
+		},
+		{
+			name:        "endpointOnly",
+			annotations: map[string]string{bootstrapv1.InPlaceUpgradeHealthCheckEndpointAnnotation: "/healthz"},
+			expected:    &inplace.HealthChecks{Endpoint: "/healthz"},
+		},
+		{
+			name:        "unknownGate",
+			annotations: map[string]string{bootstrapv1.InPlaceUpgradeHealthGatesAnnotation: "etcd"},
+			expectErr:   true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := &clusterv1.Machine{}
+			m.SetAnnotations(tc.annotations)
+
+			checks, err := inplace.GetHealthChecks(m)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(checks).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestHealthChecksCheck(t *testing.T) {
+	healthyCP := &controlplanev1.CK8sControlPlane{}
+	conditions.MarkTrue(healthyCP, controlplanev1.ControlPlaneComponentsHealthyCondition)
+
+	allGates := &inplace.HealthChecks{
+		Gates:    []inplace.HealthGate{inplace.HealthGateControlPlane, inplace.HealthGateDatastore, inplace.HealthGateNodes},
+		Endpoint: "/healthz",
+	}
+
+	for _, tc := range []struct {
+		name         string
+		checks       *inplace.HealthChecks
+		controlPlane conditions.Getter
+		workload     *fakeHealthChecker
+		expectErr    bool
+	}{
+		{
+			name:         "healthy",
+			checks:       allGates,
+			controlPlane: healthyCP,
+			workload:     &fakeHealthChecker{},
+		},
+		{
+			name:         "unhealthyControlPlane",
+			checks:       allGates,
+			controlPlane: &controlplanev1.CK8sControlPlane{},
+			workload:     &fakeHealthChecker{},
+			expectErr:    true,
+		},
+		{
+			name:         "nodesNotReady",
+			checks:       allGates,
+			controlPlane: healthyCP,
+			workload:     &fakeHealthChecker{nodesErr: errors.New("not ready")},
+			expectErr:    true,
+		},
+		{
+			name:         "endpointFailed",
+			checks:       allGates,
+			controlPlane: healthyCP,
+			workload:     &fakeHealthChecker{endpointErr: errors.New("503")},
+			expectErr:    true,
+		},
+		{
+			name:      "gateNotConfigured",
+			checks:    &inplace.HealthChecks{Gates: []inplace.HealthGate{inplace.HealthGateNodes}},
+			workload:  &fakeHealthChecker{datastoreErr: errors.New("pending")},
+			expectErr: false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := tc.checks.Check(context.Background(), tc.controlPlane, tc.workload, nil)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
@@ -1,12 +1,17 @@
 package inplace
 
 import (
+	"k8s.io/apimachinery/pkg/util/intstr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
 )
 
+// DefaultMaxUnavailable is the number of machines upgraded at the same time
+// when the MachineDeployment does not specify otherwise.
+const DefaultMaxUnavailable = 1
+
 // IsUpgraded checks if the object is already upgraded to the specified release.
 func IsUpgraded(obj client.Object, release string) bool {
 	return obj.GetAnnotations()[bootstrapv1.InPlaceUpgradeReleaseAnnotation] == release
@@ -42,3 +47,33 @@ func IsMachineUpgrading(m *clusterv1.Machine) bool {
 	return m.Annotations[bootstrapv1.InPlaceUpgradeStatusAnnotation] == bootstrapv1.InPlaceUpgradeInProgressStatus ||
 		m.Annotations[bootstrapv1.InPlaceUpgradeToAnnotation] != ""
 }
+
+// IsUpgradeFailed checks if the in-place upgrade of the object to the specified release failed.
+// The object might still be retrying the upgrade of its machines at the moment of the check.
+func IsUpgradeFailed(obj client.Object, release string) bool {
+	return GetUpgradeInstructions(obj) == release &&
+		obj.GetAnnotations()[bootstrapv1.InPlaceUpgradeStatusAnnotation] == bootstrapv1.InPlaceUpgradeFailedStatus
+}
+
+// IsUpgrading checks if the object is instructed to upgrade to the specified release.
+func IsUpgrading(obj client.Object, release string) bool {
+	return obj.GetAnnotations()[bootstrapv1.InPlaceUpgradeToAnnotation] == release
+}
+
+// GetMaxUnavailable returns the number of machines that can upgrade at the same time out of total machines.
+// Percentages are rounded down, but at least one machine is always allowed to upgrade.
+// Missing or invalid values fall back to DefaultMaxUnavailable.
+func GetMaxUnavailable(obj client.Object, total int) int {
+	v, ok := obj.GetAnnotations()[bootstrapv1.InPlaceUpgradeMaxUnavailableAnnotation]
+	if !ok {
+		return DefaultMaxUnavailable
+	}
+
+	maxUnavailable := intstr.Parse(v)
+	n, err := intstr.GetScaledValueFromIntOrPercent(&maxUnavailable, total, false)
+	if err != nil || n < 0 {
+		return DefaultMaxUnavailable
+	}
+
+	return max(n, 1)
+}
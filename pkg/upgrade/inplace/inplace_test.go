@@ -214,3 +214,162 @@ func TestIsMachineUpgrading(t *testing.T) {
 		})
 	}
 }
+
+func TestIsUpgradeFailed(t *testing.T) {
+	g := NewWithT(t)
+
+	for _, tc := range []struct {
+		name          string
+		annotations   map[string]string
+		releaseString string
+		upgradeFailed bool
+	}{
+		{
+			name: "failed",
+			annotations: map[string]string{
+				bootstrapv1.InPlaceUpgradeToAnnotation:     "v1.31",
+				bootstrapv1.InPlaceUpgradeStatusAnnotation: bootstrapv1.InPlaceUpgradeFailedStatus,
+			},
+			releaseString: "v1.31",
+			upgradeFailed: true,
+		},
+		{
+			name: "failedForAnotherRelease",
+			annotations: map[string]string{
+				bootstrapv1.InPlaceUpgradeToAnnotation:     "v1.30",
+				bootstrapv1.InPlaceUpgradeStatusAnnotation: bootstrapv1.InPlaceUpgradeFailedStatus,
+			},
+			releaseString: "v1.31",
+			upgradeFailed: false,
+		},
+		{
+			name: "inProgress",
+			annotations: map[string]string{
+				bootstrapv1.InPlaceUpgradeToAnnotation:     "v1.31",
+				bootstrapv1.InPlaceUpgradeStatusAnnotation: bootstrapv1.InPlaceUpgradeInProgressStatus,
+			},
+			releaseString: "v1.31",
+			upgradeFailed: false,
+		},
+		{
+			name:          "noAnnotations",
+			annotations:   map[string]string{},
+			releaseString: "v1.31",
+			upgradeFailed: false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			controlPlane := &controlplanev1.CK8sControlPlane{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tc.annotations,
+				},
+			}
+
+			result := inplace.IsUpgradeFailed(controlPlane, tc.releaseString)
+
+			g.Expect(result).To(Equal(tc.upgradeFailed))
+		})
+	}
+}
+
+func TestIsUpgrading(t *testing.T) {
+	g := NewWithT(t)
+
+	for _, tc := range []struct {
+		name          string
+		annotations   map[string]string
+		releaseString string
+		upgrading     bool
+	}{
+		{
+			name:          "upgrading",
+			annotations:   map[string]string{bootstrapv1.InPlaceUpgradeToAnnotation: "v1.31"},
+			releaseString: "v1.31",
+			upgrading:     true,
+		},
+		{
+			name:          "upgradingToAnotherRelease",
+			annotations:   map[string]string{bootstrapv1.InPlaceUpgradeToAnnotation: "v1.30"},
+			releaseString: "v1.31",
+			upgrading:     false,
+		},
+		{
+			name:          "upgraded",
+			annotations:   map[string]string{bootstrapv1.InPlaceUpgradeReleaseAnnotation: "v1.31"},
+			releaseString: "v1.31",
+			upgrading:     false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			md := &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tc.annotations,
+				},
+			}
+
+			result := inplace.IsUpgrading(md, tc.releaseString)
+
+			g.Expect(result).To(Equal(tc.upgrading))
+		})
+	}
+}
+
+func TestGetMaxUnavailable(t *testing.T) {
+	g := NewWithT(t)
+
+	for _, tc := range []struct {
+		name           string
+		annotations    map[string]string
+		total          int
+		maxUnavailable int
+	}{
+		{
+			name:           "noAnnotation",
+			annotations:    map[string]string{},
+			total:          10,
+			maxUnavailable: inplace.DefaultMaxUnavailable,
+		},
+		{
+			name:           "absolute",
+			annotations:    map[string]string{bootstrapv1.InPlaceUpgradeMaxUnavailableAnnotation: "3"},
+			total:          10,
+			maxUnavailable: 3,
+		},
+		{
+			name:           "percentage",
+			annotations:    map[string]string{bootstrapv1.InPlaceUpgradeMaxUnavailableAnnotation: "25%"},
+			total:          10,
+			maxUnavailable: 2,
+		},
+		{
+			name:           "percentageRoundedDownToZero",
+			annotations:    map[string]string{bootstrapv1.InPlaceUpgradeMaxUnavailableAnnotation: "5%"},
+			total:          10,
+			maxUnavailable: 1,
+		},
+		{
+			name:           "zero",
+			annotations:    map[string]string{bootstrapv1.InPlaceUpgradeMaxUnavailableAnnotation: "0"},
+			total:          10,
+			maxUnavailable: 1,
+		},
+		{
+			name:           "invalid",
+			annotations:    map[string]string{bootstrapv1.InPlaceUpgradeMaxUnavailableAnnotation: "many"},
+			total:          10,
+			maxUnavailable: inplace.DefaultMaxUnavailable,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			md := &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tc.annotations,
+				},
+			}
+
+			result := inplace.GetMaxUnavailable(md, tc.total)
+
+			g.Expect(result).To(Equal(tc.maxUnavailable))
+		})
+	}
+}
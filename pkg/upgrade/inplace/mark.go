@@ -13,24 +13,33 @@ import (
 
 // MarkMachineToUpgrade marks the machine to upgrade.
 func MarkMachineToUpgrade(ctx context.Context, m *clusterv1.Machine, to string, c client.Client) error {
-	patchHelper, err := patch.NewHelper(m, c)
+	return MarkToUpgrade(ctx, m, to, c)
+}
+
+// MarkToUpgrade marks the object to upgrade, clearing the state left by any previous upgrade.
+// This is used by the orchestrators to hand over the upgrade to the controller of the object.
+func MarkToUpgrade(ctx context.Context, obj client.Object, to string, c client.Client) error {
+	patchHelper, err := patch.NewHelper(obj, c)
 	if err != nil {
 		return fmt.Errorf("failed to create new patch helper: %w", err)
 	}
 
-	if m.Annotations == nil {
-		m.Annotations = make(map[string]string)
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
 	}
 
 	// clean up
-	delete(m.Annotations, bootstrapv1.InPlaceUpgradeReleaseAnnotation)
-	delete(m.Annotations, bootstrapv1.InPlaceUpgradeStatusAnnotation)
-	delete(m.Annotations, bootstrapv1.InPlaceUpgradeChangeIDAnnotation)
-	delete(m.Annotations, bootstrapv1.InPlaceUpgradeLastFailedAttemptAtAnnotation)
+	delete(annotations, bootstrapv1.InPlaceUpgradeReleaseAnnotation)
+	delete(annotations, bootstrapv1.InPlaceUpgradeStatusAnnotation)
+	delete(annotations, bootstrapv1.InPlaceUpgradeChangeIDAnnotation)
+	delete(annotations, bootstrapv1.InPlaceUpgradeLastFailedAttemptAtAnnotation)
+	delete(annotations, bootstrapv1.InPlaceUpgradeAcknowledgeFailureAnnotation)
 
-	m.Annotations[bootstrapv1.InPlaceUpgradeToAnnotation] = to
+	annotations[bootstrapv1.InPlaceUpgradeToAnnotation] = to
+	obj.SetAnnotations(annotations)
 
-	if err := patchHelper.Patch(ctx, m); err != nil {
+	if err := patchHelper.Patch(ctx, obj); err != nil {
 		return fmt.Errorf("failed to patch: %w", err)
 	}
 
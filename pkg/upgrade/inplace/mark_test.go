@@ -100,6 +100,35 @@ func TestMarkMachineToUpgrade(t *testing.T) {
 	})
 }
 
+func TestMarkToUpgrade(t *testing.T) {
+	g := NewWithT(t)
+
+	controlPlane := &controlplanev1.CK8sControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cp_test",
+			Annotations: map[string]string{
+				bootstrapv1.InPlaceUpgradeReleaseAnnotation: "v1.30",
+				bootstrapv1.InPlaceUpgradeStatusAnnotation:  bootstrapv1.InPlaceUpgradeDoneStatus,
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	err := controlplanev1.AddToScheme(scheme)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	testClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(controlPlane.DeepCopy()).
+		Build()
+
+	result := inplace.MarkToUpgrade(context.Background(), controlPlane, "v1.31", testClient)
+
+	g.Expect(result).NotTo(HaveOccurred())
+	g.Expect(controlPlane.ObjectMeta.Annotations).ShouldNot(HaveKey(bootstrapv1.InPlaceUpgradeReleaseAnnotation))
+	g.Expect(controlPlane.ObjectMeta.Annotations).ShouldNot(HaveKey(bootstrapv1.InPlaceUpgradeStatusAnnotation))
+	g.Expect(controlPlane.ObjectMeta.Annotations[bootstrapv1.InPlaceUpgradeToAnnotation]).To(Equal("v1.31"))
+}
+
 func TestMarkUpgradeFailed(t *testing.T) {
 	g := NewWithT(t)
 
@@ -0,0 +1,89 @@
+package inplace
+
+import (
+	"fmt"
+	"strconv"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+)
+
+// IsPaused checks if in-place upgrades are paused on any of the objects.
+func IsPaused(objs ...client.Object) bool {
+	for _, obj := range objs {
+		if isPaused(obj.GetAnnotations()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isPaused(annotations map[string]string) bool {
+	paused, _ := strconv.ParseBool(annotations[bootstrapv1.InPlaceUpgradePausedAnnotation])
+	return paused
+}
+
+// Progress counts the machines of an orchestrated in-place upgrade by their state.
+type Progress struct {
+	// Total is the number of machines.
+	Total int
+	// Upgraded is the number of machines running the release.
+	Upgraded int
+	// Upgrading is the number of machines with an upgrade attempt in flight.
+	Upgrading int
+	// Failed is the number of machines whose upgrade failed at some point and is not done yet.
+	Failed int
+	// Pending is the number of machines waiting for their turn to upgrade.
+	Pending int
+	// Paused is the number of machines that are not upgraded because the upgrade is paused.
+	Paused int
+}
+
+// GetProgress counts the machines by the state of their upgrade to the release.
+// If paused is true, the upgrade is paused for all the machines.
+func GetProgress(machines []*clusterv1.Machine, release string, paused bool) Progress {
+	p := Progress{Total: len(machines)}
+	for _, m := range machines {
+		switch {
+		case IsUpgraded(m, release):
+			p.Upgraded++
+		case IsMachineUpgradeFailed(m):
+			p.Failed++
+		case isUpgradeStarted(m):
+			p.Upgrading++
+		case paused || isPaused(m.Annotations):
+			p.Paused++
+		default:
+			p.Pending++
+		}
+	}
+
+	return p
+}
+
+// isUpgradeStarted checks if an upgrade attempt is in flight on the machine.
+func isUpgradeStarted(m *clusterv1.Machine) bool {
+	return m.Annotations[bootstrapv1.InPlaceUpgradeStatusAnnotation] == bootstrapv1.InPlaceUpgradeInProgressStatus
+}
+
+// String returns a human readable summary of the progress.
+func (p Progress) String() string {
+	return fmt.Sprintf("Upgraded %d of %d machines (%d upgrading, %d failed, %d pending, %d paused)",
+		p.Upgraded, p.Total, p.Upgrading, p.Failed, p.Pending, p.Paused)
+}
+
+// MarkProgress reports the progress in the MachinesInPlaceUpgraded condition of the object.
+func MarkProgress(obj conditions.Setter, p Progress) {
+	switch {
+	case p.Upgraded == p.Total:
+		conditions.MarkTrue(obj, bootstrapv1.MachinesInPlaceUpgradedCondition)
+	case p.Paused > 0:
+		conditions.MarkFalse(obj, bootstrapv1.MachinesInPlaceUpgradedCondition, bootstrapv1.InPlaceUpgradePausedReason, clusterv1.ConditionSeverityInfo, "%s", p)
+	default:
+		conditions.MarkFalse(obj, bootstrapv1.MachinesInPlaceUpgradedCondition, bootstrapv1.InPlaceUpgradeInProgressReason, clusterv1.ConditionSeverityInfo, "%s", p)
+	}
+}
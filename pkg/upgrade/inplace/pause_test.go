@@ -0,0 +1,135 @@
+package inplace_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/upgrade/inplace"
+)
+
+func TestIsPaused(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		annotations  []map[string]string
+		expectPaused bool
+	}{
+		{
+			name:        "notPaused",
+			annotations: []map[string]string{{}, {}},
+		},
+		{
+			name:         "paused",
+			annotations:  []map[string]string{{}, {bootstrapv1.InPlaceUpgradePausedAnnotation: "true"}},
+			expectPaused: true,
+		},
+		{
+			name:        "resumed",
+			annotations: []map[string]string{{bootstrapv1.InPlaceUpgradePausedAnnotation: "false"}},
+		},
+		{
+			name:        "invalid",
+			annotations: []map[string]string{{bootstrapv1.InPlaceUpgradePausedAnnotation: "yes please"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			var objs []client.Object
+			for _, annotations := range tc.annotations {
+				objs = append(objs, &clusterv1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}})
+			}
+
+			g.Expect(inplace.IsPaused(objs...)).To(Equal(tc.expectPaused))
+		})
+	}
+}
+
+func TestGetProgress(t *testing.T) {
+	release := "channel=1.31/stable"
+	machine := func(annotations map[string]string) *clusterv1.Machine {
+		return &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+	}
+	machines := []*clusterv1.Machine{
+		machine(map[string]string{bootstrapv1.InPlaceUpgradeReleaseAnnotation: release}),
+		machine(map[string]string{
+			bootstrapv1.InPlaceUpgradeToAnnotation:     release,
+			bootstrapv1.InPlaceUpgradeStatusAnnotation: bootstrapv1.InPlaceUpgradeInProgressStatus,
+		}),
+		machine(map[string]string{
+			bootstrapv1.InPlaceUpgradeToAnnotation:                  release,
+			bootstrapv1.InPlaceUpgradeLastFailedAttemptAtAnnotation: "Mon, 02 Jan 2006 15:04:05 -0700",
+		}),
+		machine(map[string]string{
+			bootstrapv1.InPlaceUpgradeToAnnotation:     release,
+			bootstrapv1.InPlaceUpgradePausedAnnotation: "true",
+		}),
+		machine(map[string]string{bootstrapv1.InPlaceUpgradeToAnnotation: release}),
+		machine(nil),
+	}
+
+	for _, tc := range []struct {
+		name           string
+		paused         bool
+		expectProgress inplace.Progress
+	}{
+		{
+			name:           "running",
+			expectProgress: inplace.Progress{Total: 6, Upgraded: 1, Upgrading: 1, Failed: 1, Pending: 2, Paused: 1},
+		},
+		{
+			name:           "paused",
+			paused:         true,
+			expectProgress: inplace.Progress{Total: 6, Upgraded: 1, Upgrading: 1, Failed: 1, Paused: 3},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(inplace.GetProgress(machines, release, tc.paused)).To(Equal(tc.expectProgress))
+		})
+	}
+}
+
+func TestMarkProgress(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		progress     inplace.Progress
+		expectTrue   bool
+		expectReason string
+	}{
+		{
+			name:       "done",
+			progress:   inplace.Progress{Total: 2, Upgraded: 2},
+			expectTrue: true,
+		},
+		{
+			name:         "inProgress",
+			progress:     inplace.Progress{Total: 2, Upgraded: 1, Upgrading: 1},
+			expectReason: bootstrapv1.InPlaceUpgradeInProgressReason,
+		},
+		{
+			name:         "paused",
+			progress:     inplace.Progress{Total: 2, Upgraded: 1, Paused: 1},
+			expectReason: bootstrapv1.InPlaceUpgradePausedReason,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			md := &clusterv1.MachineDeployment{}
+			inplace.MarkProgress(md, tc.progress)
+
+			g.Expect(conditions.IsTrue(md, bootstrapv1.MachinesInPlaceUpgradedCondition)).To(Equal(tc.expectTrue))
+			if !tc.expectTrue {
+				g.Expect(conditions.GetReason(md, bootstrapv1.MachinesInPlaceUpgradedCondition)).To(Equal(tc.expectReason))
+				g.Expect(conditions.GetMessage(md, bootstrapv1.MachinesInPlaceUpgradedCondition)).To(Equal(tc.progress.String()))
+			}
+		})
+	}
+}
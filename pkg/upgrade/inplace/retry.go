@@ -0,0 +1,98 @@
+package inplace
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+)
+
+const (
+	// DefaultRetryBackoff is how long to wait after the first failed attempt when the object does not specify otherwise.
+	DefaultRetryBackoff = 10 * time.Second
+	// DefaultMaxRetryBackoff caps the wait between attempts when the object does not specify otherwise.
+	DefaultMaxRetryBackoff = 10 * time.Minute
+)
+
+// RetryPolicy configures how failed in-place upgrades are retried.
+type RetryPolicy struct {
+	// MaxAttempts is how many times the upgrade is attempted before giving up. Zero means no limit.
+	MaxAttempts int
+	// Backoff is how long to wait after the first failed attempt. It doubles after every failed attempt.
+	Backoff time.Duration
+	// MaxBackoff caps the wait between attempts.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used when neither the machine nor its owner configure one.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		Backoff:    DefaultRetryBackoff,
+		MaxBackoff: DefaultMaxRetryBackoff,
+	}
+}
+
+// Exhausted checks if the upgrade should not be retried after the given number of failed attempts.
+func (p *RetryPolicy) Exhausted(attempts int) bool {
+	return p.MaxAttempts > 0 && attempts >= p.MaxAttempts
+}
+
+// BackoffFor returns how long to wait before retrying after the given number of failed attempts.
+func (p *RetryPolicy) BackoffFor(attempts int) time.Duration {
+	backoff := p.Backoff
+	for i := 1; i < attempts && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, p.MaxBackoff)
+}
+
+// HasRetryPolicy checks if the object configures the retry policy.
+func HasRetryPolicy(obj client.Object) bool {
+	for _, annotation := range []string{
+		bootstrapv1.InPlaceUpgradeMaxAttemptsAnnotation,
+		bootstrapv1.InPlaceUpgradeRetryBackoffAnnotation,
+		bootstrapv1.InPlaceUpgradeMaxRetryBackoffAnnotation,
+	} {
+		if _, ok := obj.GetAnnotations()[annotation]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetRetryPolicy returns the retry policy set on the object. Unset values are defaulted.
+func GetRetryPolicy(obj client.Object) (*RetryPolicy, error) {
+	policy := DefaultRetryPolicy()
+	annotations := obj.GetAnnotations()
+
+	if v, ok := annotations[bootstrapv1.InPlaceUpgradeMaxAttemptsAnnotation]; ok {
+		maxAttempts, err := strconv.Atoi(v)
+		if err != nil || maxAttempts < 0 {
+			return nil, fmt.Errorf("invalid %s annotation %q: must be a non-negative integer", bootstrapv1.InPlaceUpgradeMaxAttemptsAnnotation, v)
+		}
+		policy.MaxAttempts = maxAttempts
+	}
+
+	if v, ok := annotations[bootstrapv1.InPlaceUpgradeRetryBackoffAnnotation]; ok {
+		backoff, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation %q: %w", bootstrapv1.InPlaceUpgradeRetryBackoffAnnotation, v, err)
+		}
+		policy.Backoff = backoff
+	}
+
+	if v, ok := annotations[bootstrapv1.InPlaceUpgradeMaxRetryBackoffAnnotation]; ok {
+		maxBackoff, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation %q: %w", bootstrapv1.InPlaceUpgradeMaxRetryBackoffAnnotation, v, err)
+		}
+		policy.MaxBackoff = maxBackoff
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,89 @@
+package inplace_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/upgrade/inplace"
+)
+
+func TestGetRetryPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		annotations  map[string]string
+		expectHas    bool
+		expectPolicy *inplace.RetryPolicy
+		expectErr    bool
+	}{
+		{
+			name:         "missing",
+			annotations:  map[string]string{},
+			expectPolicy: inplace.DefaultRetryPolicy(),
+		},
+		{
+			name: "all",
+			annotations: map[string]string{
+				bootstrapv1.InPlaceUpgradeMaxAttemptsAnnotation:     "3",
+				bootstrapv1.InPlaceUpgradeRetryBackoffAnnotation:    "30s",
+				bootstrapv1.InPlaceUpgradeMaxRetryBackoffAnnotation: "5m",
+			},
+			expectHas:    true,
+			expectPolicy: &inplace.RetryPolicy{MaxAttempts: 3, Backoff: 30 * time.Second, MaxBackoff: 5 * time.Minute},
+		},
+		{
+			name:         "maxAttemptsOnly",
+			annotations:  map[string]string{bootstrapv1.InPlaceUpgradeMaxAttemptsAnnotation: "5"},
+			expectHas:    true,
+			expectPolicy: &inplace.RetryPolicy{MaxAttempts: 5, Backoff: inplace.DefaultRetryBackoff, MaxBackoff: inplace.DefaultMaxRetryBackoff},
+		},
+		{
+			name:        "invalidMaxAttempts",
+			annotations: map[string]string{bootstrapv1.InPlaceUpgradeMaxAttemptsAnnotation: "-1"},
+			expectHas:   true,
+			expectErr:   true,
+		},
+		{
+			name:        "invalidBackoff",
+			annotations: map[string]string{bootstrapv1.InPlaceUpgradeRetryBackoffAnnotation: "soon"},
+			expectHas:   true,
+			expectErr:   true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := &clusterv1.Machine{}
+			m.SetAnnotations(tc.annotations)
+
+			g.Expect(inplace.HasRetryPolicy(m)).To(Equal(tc.expectHas))
+
+			policy, err := inplace.GetRetryPolicy(m)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(policy).To(Equal(tc.expectPolicy))
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	g := NewWithT(t)
+
+	policy := &inplace.RetryPolicy{MaxAttempts: 4, Backoff: 10 * time.Second, MaxBackoff: time.Minute}
+
+	g.Expect(policy.BackoffFor(1)).To(Equal(10 * time.Second))
+	g.Expect(policy.BackoffFor(2)).To(Equal(20 * time.Second))
+	g.Expect(policy.BackoffFor(3)).To(Equal(40 * time.Second))
+	g.Expect(policy.BackoffFor(4)).To(Equal(time.Minute))
+	g.Expect(policy.BackoffFor(100)).To(Equal(time.Minute))
+
+	g.Expect(policy.Exhausted(3)).To(BeFalse())
+	g.Expect(policy.Exhausted(4)).To(BeTrue())
+	g.Expect(inplace.DefaultRetryPolicy().Exhausted(100)).To(BeFalse())
+}
@@ -0,0 +1,91 @@
+package inplace
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+)
+
+// StatusFromAnnotations converts the in-place upgrade state that earlier releases recorded in the annotations
+// of a machine to a typed status. It is only used for machines that have no status yet.
+// Returns nil if the machine was never upgraded in-place.
+func StatusFromAnnotations(annotations map[string]string) *bootstrapv1.InPlaceUpgradeStatus {
+	upgradeTo, upgrading := annotations[bootstrapv1.InPlaceUpgradeToAnnotation]
+	release, upgraded := annotations[bootstrapv1.InPlaceUpgradeReleaseAnnotation]
+	if !upgrading && !upgraded {
+		return nil
+	}
+
+	now := metav1.Now()
+	if !upgrading {
+		return &bootstrapv1.InPlaceUpgradeStatus{
+			Phase:              bootstrapv1.InPlaceUpgradePhaseSucceeded,
+			Release:            release,
+			LastTransitionTime: &now,
+		}
+	}
+
+	status := &bootstrapv1.InPlaceUpgradeStatus{
+		Phase:              bootstrapv1.InPlaceUpgradePhasePending,
+		Release:            upgradeTo,
+		ChangeID:           annotations[bootstrapv1.InPlaceUpgradeChangeIDAnnotation],
+		LastTransitionTime: &now,
+	}
+
+	switch annotations[bootstrapv1.InPlaceUpgradeStatusAnnotation] {
+	case bootstrapv1.InPlaceUpgradeInProgressStatus:
+		if status.ChangeID != "" {
+			status.Phase = bootstrapv1.InPlaceUpgradePhaseUpgrading
+		}
+	case bootstrapv1.InPlaceUpgradeDoneStatus:
+		status.Phase = bootstrapv1.InPlaceUpgradePhaseSucceeded
+	case bootstrapv1.InPlaceUpgradeFailedStatus:
+		status.Phase = bootstrapv1.InPlaceUpgradePhaseFailed
+	}
+
+	// NOTE: Earlier releases did not count the attempts, the attempt in flight or failed is the first one.
+	if status.Phase != bootstrapv1.InPlaceUpgradePhasePending {
+		status.Attempts = 1
+	}
+	if failedAt, err := time.Parse(time.RFC1123Z, annotations[bootstrapv1.InPlaceUpgradeLastFailedAttemptAtAnnotation]); err == nil {
+		status.LastFailedAttemptAt = &metav1.Time{Time: failedAt}
+	}
+
+	return status
+}
+
+// NewStatus returns the status of a new in-place upgrade to the release, following the upgrade reported by
+// the previous status, if any. The release the machine runs is carried over from the previous upgrade,
+// so that a failed upgrade can be rolled back to it.
+// NOTE: The release is only known if the previous upgrade succeeded, or if it failed and was rolled back.
+func NewStatus(previous *bootstrapv1.InPlaceUpgradeStatus, release string) *bootstrapv1.InPlaceUpgradeStatus {
+	now := metav1.Now()
+	status := &bootstrapv1.InPlaceUpgradeStatus{
+		Phase:              bootstrapv1.InPlaceUpgradePhasePending,
+		Release:            release,
+		LastTransitionTime: &now,
+	}
+
+	switch {
+	case previous == nil:
+	case previous.Phase == bootstrapv1.InPlaceUpgradePhaseSucceeded:
+		status.PreviousRelease = previous.Release
+	case previous.RollbackPhase == bootstrapv1.InPlaceUpgradePhaseSucceeded:
+		status.PreviousRelease = previous.PreviousRelease
+	}
+
+	return status
+}
+
+// SetPhase moves the in-place upgrade to the phase, recording when the phase changed.
+func SetPhase(status *bootstrapv1.InPlaceUpgradeStatus, phase bootstrapv1.InPlaceUpgradePhase) {
+	if status.Phase == phase {
+		return
+	}
+
+	now := metav1.Now()
+	status.Phase = phase
+	status.LastTransitionTime = &now
+}
@@ -0,0 +1,178 @@
+package inplace_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	bootstrapv1 "github.com/canonical/cluster-api-k8s/bootstrap/api/v1beta2"
+	"github.com/canonical/cluster-api-k8s/pkg/upgrade/inplace"
+)
+
+func TestStatusFromAnnotations(t *testing.T) {
+	failedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	for _, tc := range []struct {
+		name           string
+		annotations    map[string]string
+		expectNil      bool
+		expectPhase    bootstrapv1.InPlaceUpgradePhase
+		expectRelease  string
+		expectChangeID string
+		expectAttempts int32
+		expectFailedAt bool
+	}{
+		{
+			name:        "neverUpgraded",
+			annotations: map[string]string{},
+			expectNil:   true,
+		},
+		{
+			name:          "pending",
+			annotations:   map[string]string{bootstrapv1.InPlaceUpgradeToAnnotation: "channel=1.31/stable"},
+			expectPhase:   bootstrapv1.InPlaceUpgradePhasePending,
+			expectRelease: "channel=1.31/stable",
+		},
+		{
+			name: "inProgress",
+			annotations: map[string]string{
+				bootstrapv1.InPlaceUpgradeToAnnotation:       "channel=1.31/stable",
+				bootstrapv1.InPlaceUpgradeStatusAnnotation:   bootstrapv1.InPlaceUpgradeInProgressStatus,
+				bootstrapv1.InPlaceUpgradeChangeIDAnnotation: "1",
+			},
+			expectPhase:    bootstrapv1.InPlaceUpgradePhaseUpgrading,
+			expectRelease:  "channel=1.31/stable",
+			expectChangeID: "1",
+			expectAttempts: 1,
+		},
+		{
+			name: "inProgressWithoutChangeID",
+			annotations: map[string]string{
+				bootstrapv1.InPlaceUpgradeToAnnotation:     "channel=1.31/stable",
+				bootstrapv1.InPlaceUpgradeStatusAnnotation: bootstrapv1.InPlaceUpgradeInProgressStatus,
+			},
+			expectPhase:   bootstrapv1.InPlaceUpgradePhasePending,
+			expectRelease: "channel=1.31/stable",
+		},
+		{
+			name: "failed",
+			annotations: map[string]string{
+				bootstrapv1.InPlaceUpgradeToAnnotation:                  "channel=1.31/stable",
+				bootstrapv1.InPlaceUpgradeStatusAnnotation:              bootstrapv1.InPlaceUpgradeFailedStatus,
+				bootstrapv1.InPlaceUpgradeLastFailedAttemptAtAnnotation: failedAt.Format(time.RFC1123Z),
+			},
+			expectPhase:    bootstrapv1.InPlaceUpgradePhaseFailed,
+			expectRelease:  "channel=1.31/stable",
+			expectAttempts: 1,
+			expectFailedAt: true,
+		},
+		{
+			name: "done",
+			annotations: map[string]string{
+				bootstrapv1.InPlaceUpgradeToAnnotation:       "channel=1.31/stable",
+				bootstrapv1.InPlaceUpgradeStatusAnnotation:   bootstrapv1.InPlaceUpgradeDoneStatus,
+				bootstrapv1.InPlaceUpgradeChangeIDAnnotation: "1",
+			},
+			expectPhase:    bootstrapv1.InPlaceUpgradePhaseSucceeded,
+			expectRelease:  "channel=1.31/stable",
+			expectChangeID: "1",
+			expectAttempts: 1,
+		},
+		{
+			name:          "upgraded",
+			annotations:   map[string]string{bootstrapv1.InPlaceUpgradeReleaseAnnotation: "channel=1.31/stable"},
+			expectPhase:   bootstrapv1.InPlaceUpgradePhaseSucceeded,
+			expectRelease: "channel=1.31/stable",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			status := inplace.StatusFromAnnotations(tc.annotations)
+			if tc.expectNil {
+				g.Expect(status).To(BeNil())
+				return
+			}
+
+			g.Expect(status).ToNot(BeNil())
+			g.Expect(status.Phase).To(Equal(tc.expectPhase))
+			g.Expect(status.Release).To(Equal(tc.expectRelease))
+			g.Expect(status.ChangeID).To(Equal(tc.expectChangeID))
+			g.Expect(status.Attempts).To(Equal(tc.expectAttempts))
+			g.Expect(status.LastTransitionTime).ToNot(BeNil())
+			if tc.expectFailedAt {
+				g.Expect(status.LastFailedAttemptAt.Time).To(BeTemporally("==", failedAt))
+			} else {
+				g.Expect(status.LastFailedAttemptAt).To(BeNil())
+			}
+		})
+	}
+}
+
+func TestNewStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name                  string
+		previous              *bootstrapv1.InPlaceUpgradeStatus
+		expectPreviousRelease string
+	}{
+		{
+			name: "neverUpgraded",
+		},
+		{
+			name: "upgraded",
+			previous: &bootstrapv1.InPlaceUpgradeStatus{
+				Phase:   bootstrapv1.InPlaceUpgradePhaseSucceeded,
+				Release: "channel=1.31/stable",
+			},
+			expectPreviousRelease: "channel=1.31/stable",
+		},
+		{
+			name: "rolledBack",
+			previous: &bootstrapv1.InPlaceUpgradeStatus{
+				Phase:           bootstrapv1.InPlaceUpgradePhaseFailed,
+				Release:         "channel=1.32/stable",
+				PreviousRelease: "channel=1.31/stable",
+				RollbackPhase:   bootstrapv1.InPlaceUpgradePhaseSucceeded,
+			},
+			expectPreviousRelease: "channel=1.31/stable",
+		},
+		{
+			name: "notRolledBack",
+			previous: &bootstrapv1.InPlaceUpgradeStatus{
+				Phase:           bootstrapv1.InPlaceUpgradePhaseFailed,
+				Release:         "channel=1.32/stable",
+				PreviousRelease: "channel=1.31/stable",
+				RollbackPhase:   bootstrapv1.InPlaceUpgradePhaseFailed,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			status := inplace.NewStatus(tc.previous, "channel=1.33/stable")
+			g.Expect(status.Phase).To(Equal(bootstrapv1.InPlaceUpgradePhasePending))
+			g.Expect(status.Release).To(Equal("channel=1.33/stable"))
+			g.Expect(status.PreviousRelease).To(Equal(tc.expectPreviousRelease))
+			g.Expect(status.Attempts).To(BeZero())
+		})
+	}
+}
+
+func TestSetPhase(t *testing.T) {
+	g := NewWithT(t)
+
+	transitionTime := metav1.NewTime(time.Now().Add(-time.Hour))
+	status := &bootstrapv1.InPlaceUpgradeStatus{
+		Phase:              bootstrapv1.InPlaceUpgradePhaseUpgrading,
+		LastTransitionTime: &transitionTime,
+	}
+
+	inplace.SetPhase(status, bootstrapv1.InPlaceUpgradePhaseUpgrading)
+	g.Expect(status.LastTransitionTime).To(Equal(&transitionTime))
+
+	inplace.SetPhase(status, bootstrapv1.InPlaceUpgradePhaseWaitingForNode)
+	g.Expect(status.Phase).To(Equal(bootstrapv1.InPlaceUpgradePhaseWaitingForNode))
+	g.Expect(status.LastTransitionTime.Time).To(BeTemporally(">", transitionTime.Time))
+}